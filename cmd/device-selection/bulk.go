@@ -0,0 +1,97 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+)
+
+func bulkCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	flags, common := newFlagSet("bulk")
+	file := flags.String("file", "", "json or yaml file with the bulk request (required)")
+	v1 := flags.Bool("v1", false, "the file is a request of the deprecated /bulk/selectables endpoint")
+	combinedDevices := flags.Bool("combined-devices", false, "return the devices matching any element; the file is a request of the v1 format")
+	completeServices := flags.Bool("complete-services", false, "add import types and import path options to the result")
+	err := parseFlags(flags, common, args)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: missing -file", errUsage)
+	}
+	token, err := common.authorization()
+	if err != nil {
+		return err
+	}
+	c := common.client()
+	options := &client.BulkOptions{CompleteServices: *completeServices}
+
+	var result model.BulkResult
+	if *v1 || *combinedDevices {
+		request := model.BulkRequest{}
+		err = readFile(*file, &request)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := common.context(ctx)
+		defer cancel()
+		if *combinedDevices {
+			devices, _, err := c.BulkSelectablesCombinedDevices(ctx, token, request)
+			if err != nil {
+				return err
+			}
+			return write(stdout, common.format, output{value: devices, tables: []table{devicesTable(devices)}})
+		}
+		result, _, err = c.BulkSelectables(ctx, token, request, options)
+	} else {
+		request := model.BulkRequestV2{}
+		err = readFile(*file, &request)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := common.context(ctx)
+		defer cancel()
+		result, _, err = c.BulkSelectablesV2(ctx, token, request, options)
+	}
+	if err != nil {
+		return err
+	}
+	return write(stdout, common.format, output{value: result, tables: []table{bulkTable(result)}})
+}
+
+func bulkTable(result model.BulkResult) table {
+	t := table{title: "bulk", header: append([]string{"ELEMENT"}, selectableHeader...), rows: [][]string{}}
+	for _, element := range result {
+		for _, info := range getSelectableInfos(element.Selectables) {
+			t.rows = append(t.rows, append([]string{element.Id}, info.row()...))
+		}
+	}
+	return t
+}
+
+func devicesTable(devices []model.PermSearchDevice) table {
+	t := table{title: "devices", header: []string{"ID", "NAME", "DEVICE_TYPE"}, rows: [][]string{}}
+	for _, device := range devices {
+		t.rows = append(t.rows, deviceRow(device))
+	}
+	return t
+}
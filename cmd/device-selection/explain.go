@@ -0,0 +1,165 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+func explainCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	flags, common := newFlagSet("explain")
+	queryLocation := flags.String("query", "", "json or yaml file with a list of criteria or a bulk v2 element (criteria and options)")
+	query := addQueryFlags(flags)
+	err := parseFlags(flags, common, args)
+	if err != nil {
+		return err
+	}
+	element, err := query.build(flags, *queryLocation)
+	if err != nil {
+		return err
+	}
+	request := explainRequest(element)
+
+	token, err := common.authorization()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+	result, _, err := common.client().BulkSelectablesV2(ctx, token, request, &client.BulkOptions{})
+	if err != nil {
+		return err
+	}
+	if len(result) != len(request) {
+		return fmt.Errorf("unexpected result count %v", len(result))
+	}
+	exp := explain(element.Criteria, result)
+	return write(stdout, common.format, output{value: exp, tables: exp.tables()})
+}
+
+// explainRequest queries the complete criteria set (element 0) and each criterion on its own (element i+1)
+func explainRequest(element model.BulkRequestElementV2) (request model.BulkRequestV2) {
+	element.Id = "all"
+	request = model.BulkRequestV2{element}
+	for i, criterion := range element.Criteria {
+		single := element
+		single.Id = strconv.Itoa(i + 1)
+		single.Criteria = model.FilterCriteriaAndSet{criterion}
+		request = append(request, single)
+	}
+	return request
+}
+
+type explanation struct {
+	Matches  int                    `json:"matches"`
+	Criteria []criterionExplanation `json:"criteria"`
+	Excluded []excludedSelectable   `json:"excluded"`
+}
+
+type criterionExplanation struct {
+	Index     int                        `json:"index"`
+	Criterion devicemodel.FilterCriteria `json:"criterion"`
+	Matches   int                        `json:"matches"`
+}
+
+// excludedSelectable matches at least one criterion on its own, but not the complete criteria set.
+// if MissingCriteria is empty, each criterion matches but not the combination (e.g. because of the interaction).
+type excludedSelectable struct {
+	selectableInfo
+	MatchedCriteria []int `json:"matched_criteria"`
+	MissingCriteria []int `json:"missing_criteria"`
+}
+
+// explain expects the result of explainRequest in the same order
+func explain(criteria model.FilterCriteriaAndSet, result model.BulkResult) (exp explanation) {
+	exp = explanation{Matches: len(result[0].Selectables), Criteria: []criterionExplanation{}, Excluded: []excludedSelectable{}}
+	included := map[string]bool{}
+	for _, info := range getSelectableInfos(result[0].Selectables) {
+		included[info.key()] = true
+	}
+	excluded := map[string]*excludedSelectable{}
+	order := []string{}
+	for i, criterion := range criteria {
+		index := i + 1
+		exp.Criteria = append(exp.Criteria, criterionExplanation{Index: index, Criterion: criterion, Matches: len(result[index].Selectables)})
+		for _, info := range getSelectableInfos(result[index].Selectables) {
+			if included[info.key()] {
+				continue
+			}
+			element, ok := excluded[info.key()]
+			if !ok {
+				element = &excludedSelectable{selectableInfo: info}
+				excluded[info.key()] = element
+				order = append(order, info.key())
+			}
+			element.MatchedCriteria = append(element.MatchedCriteria, index)
+		}
+	}
+	for _, key := range order {
+		element := excluded[key]
+		element.MissingCriteria = []int{}
+		for i := range criteria {
+			if !slices.Contains(element.MatchedCriteria, i+1) {
+				element.MissingCriteria = append(element.MissingCriteria, i+1)
+			}
+		}
+		exp.Excluded = append(exp.Excluded, *element)
+	}
+	return exp
+}
+
+func (this explanation) tables() []table {
+	criteria := table{title: "criteria", header: []string{"#", "FUNCTION", "ASPECT", "DEVICE_CLASS", "INTERACTION", "MATCHES"}, rows: [][]string{}}
+	for _, element := range this.Criteria {
+		criteria.rows = append(criteria.rows, []string{
+			strconv.Itoa(element.Index),
+			element.Criterion.FunctionId,
+			element.Criterion.AspectId,
+			element.Criterion.DeviceClassId,
+			element.Criterion.Interaction,
+			strconv.Itoa(element.Matches),
+		})
+	}
+	criteria.rows = append(criteria.rows, []string{"all", "", "", "", "", strconv.Itoa(this.Matches)})
+
+	excluded := table{title: "excluded", header: []string{"TYPE", "ID", "NAME", "MATCHED", "MISSING"}, rows: [][]string{}}
+	for _, element := range this.Excluded {
+		missing := joinInts(element.MissingCriteria)
+		if missing == "" {
+			missing = "combination"
+		}
+		excluded.rows = append(excluded.rows, []string{element.Type, element.Id, element.Name, joinInts(element.MatchedCriteria), missing})
+	}
+	return []table{criteria, excluded}
+}
+
+func joinInts(list []int) string {
+	result := []string{}
+	for _, element := range list {
+		result = append(result, strconv.Itoa(element))
+	}
+	return strings.Join(result, ",")
+}
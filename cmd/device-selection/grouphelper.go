@@ -0,0 +1,80 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"io"
+	"strconv"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+func groupHelperCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	flags, common := newFlagSet("group-helper")
+	devices := listFlag{}
+	flags.Var(&devices, "devices", "comma separated device ids of the group")
+	file := flags.String("file", "", "json or yaml file with a list of device ids; combined with -devices")
+	options := &client.DeviceGroupHelperOptions{}
+	blockList := listFlag{}
+	flags.StringVar(&options.Search, "search", "", "search text of the offered devices")
+	flags.Int64Var(&options.Limit, "limit", 0, "limit of the offered devices (server default 100)")
+	flags.Int64Var(&options.Offset, "offset", 0, "offset of the offered devices")
+	flags.BoolVar(&options.MaintainsGroupUsability, "maintains-group-usability", false, "only offer devices that keep the group usable")
+	flags.Var(&blockList, "function-block-list", "comma separated function ids to ignore")
+	aspectMatch := flags.String("aspect-match", "", "exact, descendants (default), ancestors or both")
+	err := parseFlags(flags, common, args)
+	if err != nil {
+		return err
+	}
+	options.FunctionBlockList = blockList
+	options.AspectMatch = devicemodel.AspectMatch(*aspectMatch)
+	deviceIds := []string{}
+	if *file != "" {
+		err = readFile(*file, &deviceIds)
+		if err != nil {
+			return err
+		}
+	}
+	deviceIds = append(deviceIds, devices...)
+
+	token, err := common.authorization()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+	result, _, err := common.client().DeviceGroupHelper(ctx, token, deviceIds, options)
+	if err != nil {
+		return err
+	}
+	return write(stdout, common.format, output{value: result, tables: groupHelperTables(result)})
+}
+
+func groupHelperTables(result model.DeviceGroupHelperResult) []table {
+	criteria := table{title: "criteria", header: []string{"FUNCTION", "ASPECT", "DEVICE_CLASS", "INTERACTION"}, rows: [][]string{}}
+	for _, criterion := range result.Criteria {
+		criteria.rows = append(criteria.rows, []string{criterion.FunctionId, criterion.AspectId, criterion.DeviceClassId, string(criterion.Interaction)})
+	}
+	options := table{title: "options", header: []string{"ID", "NAME", "DEVICE_TYPE", "MAINTAINS_GROUP_USABILITY", "REMOVES_CRITERIA"}, rows: [][]string{}}
+	for _, option := range result.Options {
+		options.rows = append(options.rows, append(deviceRow(option.Device), strconv.FormatBool(option.MaintainsGroupUsability), strconv.Itoa(len(option.RemovesCriteria))))
+	}
+	return []table{criteria, options}
+}
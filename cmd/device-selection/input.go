@@ -0,0 +1,242 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"gopkg.in/yaml.v3"
+)
+
+// readFile decodes a json or yaml (.yaml, .yml) file into result; "-" reads json or yaml from stdin.
+// unknown fields are rejected to detect typos.
+func readFile(location string, result interface{}) error {
+	var content []byte
+	var err error
+	if location == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return err
+	}
+	content, err = toJson(location, content)
+	if err != nil {
+		return fmt.Errorf("unable to read %v: %w", location, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(result)
+	if err != nil {
+		return fmt.Errorf("unable to read %v: %w", location, err)
+	}
+	return nil
+}
+
+func toJson(location string, content []byte) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".json":
+		return content, nil
+	case ".yaml", ".yml":
+	default:
+		//stdin and unknown extensions: json is valid yaml, but yaml numbers and strings would be decoded differently
+		if json.Valid(content) {
+			return content, nil
+		}
+	}
+	var temp interface{}
+	err := yaml.Unmarshal(content, &temp)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(temp)
+}
+
+// readQuery reads a query file: either a bulk v2 element (criteria and options) or a plain list of criteria
+func readQuery(location string) (query model.BulkRequestElementV2, err error) {
+	var raw json.RawMessage
+	err = readFile(location, &raw)
+	if err != nil {
+		return query, err
+	}
+	var target interface{} = &query
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		target = &query.Criteria
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(target)
+	if err != nil {
+		return query, fmt.Errorf("unable to read %v: %w", location, err)
+	}
+	return query, nil
+}
+
+// criteriaFlag collects repeated -criterion flags like "function_id=urn:...,aspect_id=urn:...,interaction=request"
+type criteriaFlag []devicemodel.FilterCriteria
+
+func (this *criteriaFlag) String() string {
+	if this == nil {
+		return ""
+	}
+	result := []string{}
+	for _, criteria := range *this {
+		result = append(result, criteria.Short())
+	}
+	return strings.Join(result, " ")
+}
+
+func (this *criteriaFlag) Set(value string) error {
+	criteria := devicemodel.FilterCriteria{}
+	for _, part := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return fmt.Errorf("expected key=value, got %q", part)
+		}
+		switch key {
+		case "function_id", "function":
+			criteria.FunctionId = val
+		case "aspect_id", "aspect":
+			criteria.AspectId = val
+		case "aspect_match":
+			criteria.AspectMatch = devicemodel.AspectMatch(val)
+		case "device_class_id", "device_class":
+			criteria.DeviceClassId = val
+		case "interaction":
+			criteria.Interaction = val
+		default:
+			return fmt.Errorf("unknown criteria field %q (expected function_id, aspect_id, aspect_match, device_class_id or interaction)", key)
+		}
+	}
+	if criteria.FunctionId == "" {
+		return errors.New("function_id is required")
+	}
+	*this = append(*this, criteria)
+	return nil
+}
+
+// listFlag is a comma separated list
+type listFlag []string
+
+func (this *listFlag) String() string {
+	if this == nil {
+		return ""
+	}
+	return strings.Join(*this, ",")
+}
+
+func (this *listFlag) Set(value string) error {
+	*this = []string{}
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			*this = append(*this, element)
+		}
+	}
+	return nil
+}
+
+// queryFlags are the options of a single criteria set; they overwrite values of a query file if set explicitly
+type queryFlags struct {
+	criteria          criteriaFlag
+	includeGroups     bool
+	includeImports    bool
+	includeDevices    bool
+	includeIdModified bool
+	trimImportPath    bool
+	devices           listFlag
+	localDevices      listFlag
+	localDeviceOwner  string
+	attributeKeys     listFlag
+}
+
+func addQueryFlags(flags *flag.FlagSet) *queryFlags {
+	query := &queryFlags{}
+	flags.Var(&query.criteria, "criterion", "criterion as function_id=...,aspect_id=...,aspect_match=...,device_class_id=...,interaction=...; may be repeated")
+	flags.BoolVar(&query.includeDevices, "include-devices", true, "include devices")
+	flags.BoolVar(&query.includeGroups, "include-groups", false, "include device groups")
+	flags.BoolVar(&query.includeImports, "include-imports", false, "include imports")
+	flags.BoolVar(&query.includeIdModified, "include-id-modified", false, "include id modified devices")
+	flags.BoolVar(&query.trimImportPath, "import-path-trim-first-element", false, "trim the first element of import paths")
+	flags.Var(&query.devices, "devices", "comma separated device ids to limit the result to")
+	flags.Var(&query.localDevices, "local-devices", "comma separated local device ids to limit the result to")
+	flags.StringVar(&query.localDeviceOwner, "local-device-owner", "", "owner of -local-devices")
+	flags.Var(&query.attributeKeys, "filter-attr-keys", "comma separated device attribute keys a device must have")
+	return query
+}
+
+// applyOptions sets the explicitly set option flags (and include-devices, if no query file is used) on element.
+// -criterion flags are not applied.
+func (this *queryFlags) applyOptions(flags *flag.FlagSet, element *model.BulkRequestElementV2, fromFile bool) {
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	if set["include-devices"] || !fromFile {
+		element.IncludeDevices = this.includeDevices
+	}
+	if set["include-groups"] {
+		element.IncludeGroups = this.includeGroups
+	}
+	if set["include-imports"] {
+		element.IncludeImports = this.includeImports
+	}
+	if set["include-id-modified"] {
+		element.IncludeIdModifiedDevices = this.includeIdModified
+	}
+	if set["import-path-trim-first-element"] {
+		element.ImportPathTrimFirstElement = this.trimImportPath
+	}
+	if set["devices"] {
+		element.Devices = this.devices
+	}
+	if set["local-devices"] {
+		element.LocalDevices = this.localDevices
+	}
+	if set["local-device-owner"] {
+		element.LocalDeviceOwner = this.localDeviceOwner
+	}
+	if set["filter-attr-keys"] {
+		element.FilterByDeviceAttributeKeys = this.attributeKeys
+	}
+}
+
+// build returns the criteria set of location (may be empty) combined with the query flags
+func (this *queryFlags) build(flags *flag.FlagSet, location string) (element model.BulkRequestElementV2, err error) {
+	if location != "" {
+		element, err = readQuery(location)
+		if err != nil {
+			return element, err
+		}
+	}
+	element.Criteria = append(element.Criteria, this.criteria...)
+	this.applyOptions(flags, &element, location != "")
+	if len(element.Criteria) == 0 {
+		return element, fmt.Errorf("%w: no criteria given (-query or -criterion)", errUsage)
+	}
+	return element, nil
+}
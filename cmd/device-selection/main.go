@@ -0,0 +1,152 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/client"
+)
+
+const usage = `device-selection runs ad-hoc queries against the device-selection service.
+
+usage:
+  device-selection <command> [flags]
+
+commands:
+  selectables   query selectables of one criteria set; -diff compares two criteria sets
+  explain       show which criteria exclude devices of a criteria set
+  bulk          run a bulk request file
+  group-helper  evaluate a device group
+  help          show this message
+
+run 'device-selection <command> -h' for the flags of a command.
+the service url and token default to $DEVICE_SELECTION_URL and $DEVICE_SELECTION_TOKEN.
+`
+
+type command func(ctx context.Context, args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"selectables":  selectablesCommand,
+	"explain":      explainCommand,
+	"bulk":         bulkCommand,
+	"group-helper": groupHelperCommand,
+}
+
+var errUsage = errors.New("usage error")
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%v", args[0], usage)
+		return 2
+	}
+	err := cmd(ctx, args[1:], stdout)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(stderr, err)
+		return 2
+	default:
+		fmt.Fprintln(stderr, "ERROR:", err)
+		return 1
+	}
+}
+
+// commonFlags are shared by all commands
+type commonFlags struct {
+	url     string
+	token   string
+	format  string
+	timeout time.Duration
+	retries int
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	common := &commonFlags{}
+	flags.StringVar(&common.url, "url", envOrDefault("DEVICE_SELECTION_URL", "http://localhost:8080"), "device-selection url")
+	flags.StringVar(&common.token, "token", os.Getenv("DEVICE_SELECTION_TOKEN"), "user token; 'Bearer ' is added if missing")
+	flags.StringVar(&common.format, "format", "table", "output format: table, json or csv")
+	flags.DurationVar(&common.timeout, "timeout", time.Minute, "timeout of the command")
+	flags.IntVar(&common.retries, "retries", 2, "retries of failed requests (429, 502, 503, 504 and network errors)")
+	return flags, common
+}
+
+func parseFlags(flags *flag.FlagSet, common *commonFlags, args []string) error {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, flags.Args())
+	}
+	if _, ok := formatters[common.format]; !ok {
+		return fmt.Errorf("%w: unknown format %q", errUsage, common.format)
+	}
+	return nil
+}
+
+func (this *commonFlags) client() client.Client {
+	return client.NewClient(strings.TrimSuffix(this.url, "/"), client.WithRetries(this.retries, 200*time.Millisecond, 5*time.Second))
+}
+
+func (this *commonFlags) authorization() (string, error) {
+	if this.token == "" {
+		return "", fmt.Errorf("%w: missing token (-token or $DEVICE_SELECTION_TOKEN)", errUsage)
+	}
+	if strings.HasPrefix(strings.ToLower(this.token), "bearer ") {
+		return this.token, nil
+	}
+	return "Bearer " + this.token, nil
+}
+
+func (this *commonFlags) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if this.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, this.timeout)
+}
+
+func envOrDefault(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+// testServer answers bulk v2 requests: function f1 matches d1 and d2, f2 matches d2 and d3; multiple criteria are intersected
+func testServer(t *testing.T) *httptest.Server {
+	matches := map[string][]string{"f1": {"d1", "d2"}, "f2": {"d2", "d3"}}
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/v2/bulk/selectables" || request.Header.Get("Authorization") != "Bearer token" {
+			http.Error(writer, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		bulk := model.BulkRequestV2{}
+		err := json.NewDecoder(request.Body).Decode(&bulk)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := model.BulkResult{}
+		for _, element := range bulk {
+			count := map[string]int{}
+			for _, criterion := range element.Criteria {
+				for _, id := range matches[criterion.FunctionId] {
+					count[id]++
+				}
+			}
+			selectables := []model.Selectable{}
+			for _, id := range []string{"d1", "d2", "d3"} {
+				if count[id] == len(element.Criteria) {
+					device := model.PermSearchDevice{}
+					device.Id = id
+					device.Name = "device " + id
+					selectables = append(selectables, model.Selectable{Device: &device, Services: []devicemodel.Service{{Id: "s1", Name: "service"}}})
+				}
+			}
+			result = append(result, model.BulkResultElement{Id: element.Id, Selectables: selectables})
+		}
+		_ = json.NewEncoder(writer).Encode(result)
+	}))
+}
+
+func runCommand(t *testing.T, args ...string) string {
+	t.Helper()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	code := run(context.Background(), args, stdout, stderr)
+	if code != 0 {
+		t.Fatal(code, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestSelectables(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	out := runCommand(t, "selectables", "-url", server.URL, "-token", "token", "-format", "csv", "-criterion", "function_id=f1,interaction=request")
+	expected := "TYPE,ID,NAME,SERVICES,PATHS\ndevice,d1,device d1,service,\ndevice,d2,device d2,service,\n"
+	if out != expected {
+		t.Errorf("\n%v\n%v", out, expected)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("criteria:\n  - function_id: f1\ninclude_devices: true\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("- function_id: f2\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := runCommand(t, "selectables", "-url", server.URL, "-token", "token", "-format", "json", "-query", filepath.Join(dir, "a.yaml"), "-diff", filepath.Join(dir, "b.yaml"))
+	diff := selectablesDiff{}
+	err = json.Unmarshal([]byte(out), &diff)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Id != "d3" || len(diff.Removed) != 1 || diff.Removed[0].Id != "d1" || diff.Unchanged != 1 {
+		t.Error(out)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	out := runCommand(t, "explain", "-url", server.URL, "-token", "token", "-criterion", "function_id=f1", "-criterion", "function_id=f2")
+	for _, expected := range []string{"all", "d1", "d3"} {
+		if !strings.Contains(out, expected) {
+			t.Error(expected, out)
+		}
+	}
+	out = runCommand(t, "explain", "-url", server.URL, "-token", "token", "-format", "json", "-criterion", "function_id=f1", "-criterion", "function_id=f2")
+	exp := explanation{}
+	err := json.Unmarshal([]byte(out), &exp)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	if exp.Matches != 1 || len(exp.Criteria) != 2 || exp.Criteria[0].Matches != 2 || len(exp.Excluded) != 2 {
+		t.Fatal(out)
+	}
+	if exp.Excluded[0].Id != "d1" || len(exp.Excluded[0].MissingCriteria) != 1 || exp.Excluded[0].MissingCriteria[0] != 2 {
+		t.Error(exp.Excluded[0])
+	}
+}
+
+func TestUsage(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	if code := run(context.Background(), []string{"selectables", "-token", "token"}, &bytes.Buffer{}, stderr); code != 2 {
+		t.Error(code, stderr.String())
+	}
+	if code := run(context.Background(), []string{"unknown"}, &bytes.Buffer{}, stderr); code != 2 {
+		t.Error(code)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+)
+
+// output is written as its tables (table, csv) or as value (json)
+type output struct {
+	value  interface{}
+	tables []table
+}
+
+type table struct {
+	title  string
+	header []string
+	rows   [][]string
+}
+
+var formatters = map[string]func(writer io.Writer, out output) error{
+	"table": writeTables,
+	"csv":   writeCsv,
+	"json":  writeJson,
+}
+
+func write(writer io.Writer, format string, out output) error {
+	return formatters[format](writer, out)
+}
+
+func writeJson(writer io.Writer, out output) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out.value)
+}
+
+func writeTables(writer io.Writer, out output) error {
+	for i, t := range out.tables {
+		if i > 0 {
+			fmt.Fprintln(writer)
+		}
+		if t.title != "" && len(out.tables) > 1 {
+			fmt.Fprintln(writer, t.title+":")
+		}
+		w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		err := w.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCsv separates multiple tables by an empty line
+func writeCsv(writer io.Writer, out output) error {
+	for i, t := range out.tables {
+		if i > 0 {
+			fmt.Fprintln(writer)
+		}
+		w := csv.NewWriter(writer)
+		err := w.Write(t.header)
+		if err != nil {
+			return err
+		}
+		err = w.WriteAll(t.rows)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var selectableHeader = []string{"TYPE", "ID", "NAME", "SERVICES", "PATHS"}
+
+// selectableInfo is the comparable summary of a selectable used for table rows and diffs
+type selectableInfo struct {
+	Type     string   `json:"type"`
+	Id       string   `json:"id"`
+	Name     string   `json:"name"`
+	Services []string `json:"services,omitempty"`
+	Paths    []string `json:"paths,omitempty"`
+}
+
+func (this selectableInfo) key() string {
+	return this.Type + "/" + this.Id
+}
+
+func (this selectableInfo) row() []string {
+	return []string{this.Type, this.Id, this.Name, strings.Join(this.Services, ", "), strings.Join(this.Paths, ", ")}
+}
+
+func getSelectableInfo(selectable model.Selectable) (result selectableInfo) {
+	switch {
+	case selectable.Device != nil:
+		result.Type = "device"
+		result.Id = selectable.Device.Id
+		result.Name = selectable.Device.DisplayName
+		if result.Name == "" {
+			result.Name = selectable.Device.Name
+		}
+	case selectable.DeviceGroup != nil:
+		result.Type = "group"
+		result.Id = selectable.DeviceGroup.Id
+		result.Name = selectable.DeviceGroup.Name
+	case selectable.Import != nil:
+		result.Type = "import"
+		result.Id = selectable.Import.Id
+		result.Name = selectable.Import.Name
+	}
+	for _, service := range selectable.Services {
+		name := service.Name
+		if name == "" {
+			name = service.Id
+		}
+		result.Services = append(result.Services, name)
+	}
+	for _, options := range selectable.ServicePathOptions {
+		for _, option := range options {
+			if option.Path != "" && !slices.Contains(result.Paths, option.Path) {
+				result.Paths = append(result.Paths, option.Path)
+			}
+		}
+	}
+	slices.Sort(result.Services)
+	slices.Sort(result.Paths)
+	return result
+}
+
+func getSelectableInfos(selectables []model.Selectable) (result []selectableInfo) {
+	result = []selectableInfo{}
+	for _, selectable := range selectables {
+		result = append(result, getSelectableInfo(selectable))
+	}
+	return result
+}
+
+func selectablesTable(title string, selectables []model.Selectable) table {
+	result := table{title: title, header: selectableHeader, rows: [][]string{}}
+	for _, info := range getSelectableInfos(selectables) {
+		result.rows = append(result.rows, info.row())
+	}
+	return result
+}
+
+func deviceRow(device model.PermSearchDevice) []string {
+	name := device.DisplayName
+	if name == "" {
+		name = device.Name
+	}
+	return []string{device.Id, name, device.DeviceTypeId}
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+)
+
+func selectablesCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	flags, common := newFlagSet("selectables")
+	queryLocation := flags.String("query", "", "json or yaml file with a list of criteria or a bulk v2 element (criteria and options)")
+	diffLocation := flags.String("diff", "", "json or yaml file with a second criteria set; shows the differences to the results of the first set. option flags apply to both sets")
+	completeServices := flags.Bool("complete-services", false, "add import types and import path options to the result")
+	query := addQueryFlags(flags)
+	err := parseFlags(flags, common, args)
+	if err != nil {
+		return err
+	}
+	element, err := query.build(flags, *queryLocation)
+	if err != nil {
+		return err
+	}
+	element.Id = "query"
+	request := model.BulkRequestV2{element}
+	if *diffLocation != "" {
+		other, err := readQuery(*diffLocation)
+		if err != nil {
+			return err
+		}
+		query.applyOptions(flags, &other, true)
+		other.Id = "diff"
+		request = append(request, other)
+	}
+
+	token, err := common.authorization()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+	result, _, err := common.client().BulkSelectablesV2(ctx, token, request, &client.BulkOptions{CompleteServices: *completeServices})
+	if err != nil {
+		return err
+	}
+	if len(result) != len(request) {
+		return fmt.Errorf("unexpected result count %v", len(result))
+	}
+
+	if *diffLocation == "" {
+		return write(stdout, common.format, output{
+			value:  result[0].Selectables,
+			tables: []table{selectablesTable("selectables", result[0].Selectables)},
+		})
+	}
+	diff := diffSelectables(getSelectableInfos(result[0].Selectables), getSelectableInfos(result[1].Selectables))
+	return write(stdout, common.format, output{value: diff, tables: []table{diff.table()}})
+}
+
+type selectablesDiff struct {
+	Added     []selectableInfo   `json:"added"`
+	Removed   []selectableInfo   `json:"removed"`
+	Changed   []selectableChange `json:"changed"`
+	Unchanged int                `json:"unchanged"`
+}
+
+type selectableChange struct {
+	Before selectableInfo `json:"before"`
+	After  selectableInfo `json:"after"`
+}
+
+// diffSelectables compares the results of two criteria sets; selectables are identified by type and id
+func diffSelectables(before []selectableInfo, after []selectableInfo) (result selectablesDiff) {
+	result = selectablesDiff{Added: []selectableInfo{}, Removed: []selectableInfo{}, Changed: []selectableChange{}}
+	beforeIndex := map[string]selectableInfo{}
+	for _, info := range before {
+		beforeIndex[info.key()] = info
+	}
+	afterIndex := map[string]selectableInfo{}
+	for _, info := range after {
+		afterIndex[info.key()] = info
+		old, ok := beforeIndex[info.key()]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, info)
+		case slices.Equal(old.Services, info.Services) && slices.Equal(old.Paths, info.Paths):
+			result.Unchanged++
+		default:
+			result.Changed = append(result.Changed, selectableChange{Before: old, After: info})
+		}
+	}
+	for _, info := range before {
+		if _, ok := afterIndex[info.key()]; !ok {
+			result.Removed = append(result.Removed, info)
+		}
+	}
+	return result
+}
+
+// table marks rows with +, - and ~; changed rows list added (+) and removed (-) services and paths
+func (this selectablesDiff) table() table {
+	result := table{title: "diff", header: append([]string{"CHANGE"}, selectableHeader...), rows: [][]string{}}
+	for _, info := range this.Removed {
+		result.rows = append(result.rows, append([]string{"-"}, info.row()...))
+	}
+	for _, info := range this.Added {
+		result.rows = append(result.rows, append([]string{"+"}, info.row()...))
+	}
+	for _, change := range this.Changed {
+		result.rows = append(result.rows, []string{
+			"~",
+			change.After.Type,
+			change.After.Id,
+			change.After.Name,
+			diffList(change.Before.Services, change.After.Services),
+			diffList(change.Before.Paths, change.After.Paths),
+		})
+	}
+	return result
+}
+
+func diffList(before []string, after []string) string {
+	result := []string{}
+	for _, element := range before {
+		if !slices.Contains(after, element) {
+			result = append(result, "-"+element)
+		}
+	}
+	for _, element := range after {
+		if !slices.Contains(before, element) {
+			result = append(result, "+"+element)
+		}
+	}
+	return strings.Join(result, ", ")
+}
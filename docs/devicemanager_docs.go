@@ -19,6 +19,37 @@ const docTemplatedevicemanager = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/config": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "returns the config including fields changed by a reload (SIGHUP or config_reload_interval); secrets are redacted. admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "effective config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/configuration.ConfigStruct"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    }
+                }
+            }
+        },
         "/bulk/selectables": {
             "post": {
                 "security": [
@@ -40,6 +71,12 @@ const docTemplatedevicemanager = `{
                 ],
                 "summary": "deprecated bulk selectables",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; selectables are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
                     {
                         "description": "BulkRequest",
                         "name": "message",
@@ -84,6 +121,12 @@ const docTemplatedevicemanager = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -111,6 +154,12 @@ const docTemplatedevicemanager = `{
                 ],
                 "summary": "bulk selectables combined devices",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; selectables are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
                     {
                         "description": "BulkRequest",
                         "name": "message",
@@ -149,6 +198,12 @@ const docTemplatedevicemanager = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -175,6 +230,18 @@ const docTemplatedevicemanager = `{
                 ],
                 "summary": "device group helper",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; selectables are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "exact, descendants (default), ancestors or both; measuring criteria get every aspect matching the device aspects in this mode",
+                        "name": "aspect_match",
+                        "in": "query"
+                    },
                     {
                         "description": "device id list",
                         "name": "message",
@@ -210,12 +277,64 @@ const docTemplatedevicemanager = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
+        "/health/live": {
+            "get": {
+                "description": "returns 200 as long as the service is able to handle requests; dependencies are not checked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "liveness",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "probes every configured dependency (device-repository, import-deploy, import-repository, memcached, kafka) and reports their status. probe results are cached for health_check_cache_duration.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "readiness",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
         "/selectables": {
             "get": {
                 "security": [
@@ -233,6 +352,12 @@ const docTemplatedevicemanager = `{
                 ],
                 "summary": "deprecated selectables",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; selectables are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
                     {
                         "type": "boolean",
                         "description": "result should include matching device-groups",
@@ -298,6 +423,12 @@ const docTemplatedevicemanager = `{
                         "description": "alternative to json and base64 if only one filter criteria is needed",
                         "name": "aspect_id",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "alternative to json and base64 if only one filter criteria is needed; exact, descendants (default), ancestors or both",
+                        "name": "aspect_match",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -325,6 +456,12 @@ const docTemplatedevicemanager = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -351,6 +488,12 @@ const docTemplatedevicemanager = `{
                 ],
                 "summary": "bulk selectables v2",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; selectables are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
                     {
                         "description": "BulkRequestV2",
                         "name": "message",
@@ -368,6 +511,12 @@ const docTemplatedevicemanager = `{
                         "description": "adds full import-type and import path options to the result. device services are already complete, the name is a legacy artefact",
                         "name": "complete_services",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "list (default) or grouped; grouped responds with model.GroupedBulkResult where the devices of every element reference the services and path options of their device-type",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -395,6 +544,97 @@ const docTemplatedevicemanager = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/v2/query/device-types": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "finds device-types that match all provided filter-criteria, with their matching services and path options and the count of devices the user may execute",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "device-types",
+                    "selectables"
+                ],
+                "summary": "device-types",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; device-types and device counts are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "result should include all valid device-type id modifications",
+                        "name": "include_id_modified",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove path options that can not be converted to the target_characteristic_id of their criterion",
+                        "name": "filter_incompatible_characteristics",
+                        "in": "query"
+                    },
+                    {
+                        "description": "criteria list",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/devicemodel.FilterCriteria"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.DeviceTypeSelection"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -417,6 +657,12 @@ const docTemplatedevicemanager = `{
                 ],
                 "summary": "selectables",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; selectables are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
                     {
                         "type": "boolean",
                         "description": "result should include matching devices",
@@ -471,6 +717,30 @@ const docTemplatedevicemanager = `{
                         "name": "filter_devices_by_attr_keys",
                         "in": "query"
                     },
+                    {
+                        "type": "boolean",
+                        "description": "remove path options that can not be converted to the target_characteristic_id of their criterion",
+                        "name": "filter_incompatible_characteristics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json encoded model.ImportFilter; result imports match these config values and properties (like {\u0026quot;configs\u0026quot;:[{\u0026quot;name\u0026quot;:\u0026quot;city\u0026quot;,\u0026quot;equals\u0026quot;:\u0026quot;Leipzig\u0026quot;}]})",
+                        "name": "import_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "event or request; selectables with this interaction are ranked higher (see model.Ranking)",
+                        "name": "preferred_interaction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "list (default) or grouped; grouped responds with model.GroupedSelectables where devices reference the services and path options of their device-type",
+                        "name": "format",
+                        "in": "query"
+                    },
                     {
                         "description": "criteria list",
                         "name": "message",
@@ -509,6 +779,12 @@ const docTemplatedevicemanager = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -531,6 +807,12 @@ const docTemplatedevicemanager = `{
                 ],
                 "summary": "selectables",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin only: user id; selectables are computed as this user would see them",
+                        "name": "X-On-Behalf-Of",
+                        "in": "header"
+                    },
                     {
                         "type": "boolean",
                         "description": "result should include matching devices",
@@ -615,11 +897,41 @@ const docTemplatedevicemanager = `{
                         "name": "aspect_id",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "alternative to json and base64 if only one filter criteria is needed; exact, descendants (default), ancestors or both",
+                        "name": "aspect_match",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "comma seperated list of attribute keys; result devices have these attributes (if one is given)",
                         "name": "filter_devices_by_attr_keys",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove path options that can not be converted to the target_characteristic_id of their criterion",
+                        "name": "filter_incompatible_characteristics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json encoded model.ImportFilter; result imports match these config values and properties (like {\u0026quot;configs\u0026quot;:[{\u0026quot;name\u0026quot;:\u0026quot;city\u0026quot;,\u0026quot;equals\u0026quot;:\u0026quot;Leipzig\u0026quot;}]})",
+                        "name": "import_filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "event or request; selectables with this interaction are ranked higher (see model.Ranking)",
+                        "name": "preferred_interaction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "list (default) or grouped; grouped responds with model.GroupedSelectables where devices reference the services and path options of their device-type",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -647,6 +959,12 @@ const docTemplatedevicemanager = `{
                     "404": {
                         "description": "Not Found"
                     },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -655,6 +973,290 @@ const docTemplatedevicemanager = `{
         }
     },
     "definitions": {
+        "configuration.ConfigStruct": {
+            "type": "object",
+            "properties": {
+                "api_port": {
+                    "type": "string"
+                },
+                "audit_file": {
+                    "description": "used by audit_sink=file",
+                    "type": "string"
+                },
+                "audit_kafka_topic": {
+                    "description": "used by audit_sink=kafka in combination with kafka_url; the topic is not created by init_topics",
+                    "type": "string"
+                },
+                "audit_redact": {
+                    "description": "\"user_id\" (hashed), \"options\", \"device_ids\"",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "audit_sample_ratio": {
+                    "type": "number"
+                },
+                "audit_sink": {
+                    "type": "string"
+                },
+                "auth_audiences": {
+                    "description": "the aud claim must contain one of these; empty disables the check",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auth_client_id": {
+                    "type": "string"
+                },
+                "auth_client_secret": {
+                    "type": "string"
+                },
+                "auth_clock_skew": {
+                    "description": "tolerance for exp and nbf",
+                    "type": "string"
+                },
+                "auth_endpoint": {
+                    "type": "string"
+                },
+                "auth_issuers": {
+                    "description": "accepted iss claims; empty accepts every issuer",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auth_jwks_url": {
+                    "description": "url like https://keycloak/auth/realms/master/protocol/openid-connect/certs or path of a local jwks file",
+                    "type": "string"
+                },
+                "auth_trusted_networks": {
+                    "description": "cidrs of internal callers that may use unverified service tokens like client.InternalAdminToken",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auth_verification": {
+                    "description": "\"none\": tokens are forwarded without validation (expects an ingress that validates them); \"jwks\": signature, expiry, issuer and audience are checked",
+                    "type": "string"
+                },
+                "cache_expiration": {
+                    "description": "expiration of cached device-repository and import-repository results",
+                    "type": "string"
+                },
+                "config_reload_interval": {
+                    "description": "interval in which the config file is checked for changes; \"\" only reloads on SIGHUP",
+                    "type": "string"
+                },
+                "cors_allow_credentials": {
+                    "description": "in combination with \"*\" every origin is reflected (behavior of earlier versions)",
+                    "type": "boolean"
+                },
+                "cors_allowed_headers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cors_allowed_methods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cors_allowed_origins": {
+                    "description": "exact origins or patterns with one \"*\" like \"https://*.example.com\"; \"*\" allows every origin; empty disables cors",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cors_max_age": {
+                    "description": "duration browsers may cache preflight results",
+                    "type": "string"
+                },
+                "debug": {
+                    "type": "boolean"
+                },
+                "device_repo_timeout": {
+                    "type": "string"
+                },
+                "device_repo_url": {
+                    "type": "string"
+                },
+                "health_check_cache_duration": {
+                    "description": "probe results are reused for this duration",
+                    "type": "string"
+                },
+                "health_check_timeout": {
+                    "description": "max duration of a single dependency probe",
+                    "type": "string"
+                },
+                "import_deploy_cache_expiration": {
+                    "description": "expiration of import instances cached per user or token; \"\" or 0 disables the cache",
+                    "type": "string"
+                },
+                "import_deploy_max_pages": {
+                    "description": "import-deploy requests per listing; 0 uses the default of 20",
+                    "type": "integer"
+                },
+                "import_deploy_page_size": {
+                    "description": "instances per import-deploy request; 0 uses the default of 500",
+                    "type": "integer"
+                },
+                "import_deploy_timeout": {
+                    "type": "string"
+                },
+                "import_deploy_url": {
+                    "type": "string"
+                },
+                "import_repo_timeout": {
+                    "type": "string"
+                },
+                "import_repo_url": {
+                    "type": "string"
+                },
+                "init_topics": {
+                    "type": "boolean"
+                },
+                "kafka_consumer_group": {
+                    "type": "string"
+                },
+                "kafka_topics_for_cache_invalidation": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kafka_url": {
+                    "type": "string"
+                },
+                "log_level": {
+                    "type": "string"
+                },
+                "max_bulk_elements": {
+                    "description": "by role; larger bulk requests are answered with 413",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "max_upstream_calls": {
+                    "description": "by role; requests needing more device-repository, import-repository and import-deploy calls are aborted with 413",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "memcached_urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rate_limit_burst": {
+                    "description": "requests above the rate a user may send at once; defaults to one second worth of requests",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "rate_limit_per_minute": {
+                    "description": "requests per minute and user by role; \"default\" applies to users without a listed role; 0 or missing is unlimited",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "security_headers": {
+                    "description": "nosniff, frame, referrer, content-security-policy and no-store headers on every response",
+                    "type": "boolean"
+                },
+                "security_hsts_max_age": {
+                    "description": "\"\" disables Strict-Transport-Security; only set if the service is exclusively reachable by https",
+                    "type": "string"
+                },
+                "strict_config": {
+                    "description": "reject unknown fields instead of logging a warning",
+                    "type": "boolean"
+                },
+                "tracing_exporter": {
+                    "description": "\"\" or \"none\" disables export; \"otlp\" sends spans to TracingOtlpEndpoint",
+                    "type": "string"
+                },
+                "tracing_otlp_endpoint": {
+                    "description": "otlp/http collector url like http://otel-collector:4318",
+                    "type": "string"
+                },
+                "tracing_sample_ratio": {
+                    "type": "number"
+                },
+                "tracing_service_name": {
+                    "type": "string"
+                },
+                "upstream_breaker_failure_threshold": {
+                    "description": "consecutive failures that open the circuit breaker of an upstream service; 0 disables the breaker",
+                    "type": "integer"
+                },
+                "upstream_breaker_open_duration": {
+                    "type": "string"
+                },
+                "upstream_max_abandoned_calls": {
+                    "description": "per repository; timed out device-repository and import-repository calls keep running, further calls are rejected with 503 while this many are running; 0 is unlimited",
+                    "type": "integer"
+                },
+                "upstream_max_retries": {
+                    "description": "retries of failed reads (network errors, 429 and 5xx)",
+                    "type": "integer"
+                },
+                "upstream_retry_backoff": {
+                    "type": "string"
+                },
+                "upstream_retry_max_backoff": {
+                    "type": "string"
+                },
+                "upstream_stale_fallback": {
+                    "description": "while a breaker is open, answer with the last successful result of the same call (same token, same parameters)",
+                    "type": "boolean"
+                },
+                "upstream_stale_fallback_ttl": {
+                    "type": "string"
+                }
+            }
+        },
+        "devicemodel.AspectMatch": {
+            "type": "string",
+            "enum": [
+                "exact",
+                "descendants",
+                "ancestors",
+                "both"
+            ],
+            "x-enum-comments": {
+                "AspectMatchAncestors": "aspect_id and its parent aspects",
+                "AspectMatchBoth": "aspect_id, its sub aspects and its parent aspects",
+                "AspectMatchDescendants": "aspect_id and its sub aspects",
+                "AspectMatchExact": "only aspect_id"
+            },
+            "x-enum-descriptions": [
+                "only aspect_id",
+                "aspect_id and its sub aspects",
+                "aspect_id and its parent aspects",
+                "aspect_id, its sub aspects and its parent aspects"
+            ],
+            "x-enum-varnames": [
+                "AspectMatchExact",
+                "AspectMatchDescendants",
+                "AspectMatchAncestors",
+                "AspectMatchBoth"
+            ]
+        },
         "devicemodel.AspectNode": {
             "type": "object",
             "properties": {
@@ -690,6 +1292,32 @@ const docTemplatedevicemanager = `{
                 }
             }
         },
+        "devicemodel.Characteristic": {
+            "type": "object",
+            "properties": {
+                "display_unit": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "max_value": {},
+                "min_value": {},
+                "name": {
+                    "type": "string"
+                },
+                "sub_characteristics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/devicemodel.Characteristic"
+                    }
+                },
+                "type": {
+                    "$ref": "#/definitions/devicemodel.Type"
+                },
+                "value": {}
+            }
+        },
         "devicemodel.Configurable": {
             "type": "object",
             "properties": {
@@ -711,6 +1339,43 @@ const docTemplatedevicemanager = `{
                 "value": {}
             }
         },
+        "devicemodel.ConfigurableCriteria": {
+            "type": "object",
+            "properties": {
+                "exclude": {
+                    "description": "no configurable may match one of these filters",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/devicemodel.ConfigurableFilter"
+                    }
+                },
+                "none": {
+                    "description": "only path options without configurables",
+                    "type": "boolean"
+                },
+                "require": {
+                    "description": "every filter must match a configurable",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/devicemodel.ConfigurableFilter"
+                    }
+                }
+            }
+        },
+        "devicemodel.ConfigurableFilter": {
+            "type": "object",
+            "properties": {
+                "accepts": {
+                    "description": "the configurable type and the min/max values of its characteristic allow this value"
+                },
+                "characteristic_id": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
         "devicemodel.DeviceGroupFilterCriteria": {
             "type": "object",
             "properties": {
@@ -734,14 +1399,57 @@ const docTemplatedevicemanager = `{
                 "aspect_id": {
                     "type": "string"
                 },
+                "aspect_match": {
+                    "description": "how aspect_id matches the aspects of path options; empty is AspectMatchDescendants",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/devicemodel.AspectMatch"
+                        }
+                    ]
+                },
+                "configurables": {
+                    "description": "path options of this criterion are filtered by their configurables and get model.PathOption.ResolvedConfigurables",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/devicemodel.ConfigurableCriteria"
+                        }
+                    ]
+                },
                 "device_class_id": {
                     "type": "string"
                 },
+                "exclude": {
+                    "description": "removes devices, groups and imports with a service, criterion or output matching interaction (exactly), function, aspect (see AspectMatch) and device class",
+                    "type": "boolean"
+                },
                 "function_id": {
                     "type": "string"
                 },
                 "interaction": {
                     "type": "string"
+                },
+                "optional": {
+                    "description": "v2 only: selectables do not need to match this criterion but are ranked higher if they do",
+                    "type": "boolean"
+                },
+                "target_characteristic_id": {
+                    "description": "path options of this criterion get a model.CharacteristicMatch",
+                    "type": "string"
+                },
+                "value_types": {
+                    "description": "path options must have one of these types; short names like Float or Structure may be used",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/devicemodel.Type"
+                    }
+                },
+                "void": {
+                    "description": "true: only void path options, false: no void path options",
+                    "type": "boolean"
+                },
+                "weight": {
+                    "description": "score of a matched optional criterion; default 1",
+                    "type": "number"
                 }
             }
         },
@@ -805,6 +1513,31 @@ const docTemplatedevicemanager = `{
                 }
             }
         },
+        "devicemodel.Type": {
+            "type": "string",
+            "enum": [
+                "https://schema.org/Text",
+                "https://schema.org/Integer",
+                "https://schema.org/Float",
+                "https://schema.org/Boolean",
+                "https://schema.org/ItemList",
+                "https://schema.org/StructuredValue",
+                "https://schema.org/Text",
+                "https://schema.org/Integer",
+                "https://schema.org/Float",
+                "https://schema.org/Boolean",
+                "https://schema.org/ItemList",
+                "https://schema.org/StructuredValue"
+            ],
+            "x-enum-varnames": [
+                "String",
+                "Integer",
+                "Float",
+                "Boolean",
+                "List",
+                "Structure"
+            ]
+        },
         "github_com_SENERGY-Platform_device-selection_pkg_model.ImportConfig": {
             "type": "object",
             "properties": {
@@ -849,6 +1582,12 @@ const docTemplatedevicemanager = `{
         "github_com_SENERGY-Platform_device-selection_pkg_model.Type": {
             "type": "string",
             "enum": [
+                "https://schema.org/Text",
+                "https://schema.org/Integer",
+                "https://schema.org/Float",
+                "https://schema.org/Boolean",
+                "https://schema.org/ItemList",
+                "https://schema.org/StructuredValue",
                 "https://schema.org/Text",
                 "https://schema.org/Integer",
                 "https://schema.org/Float",
@@ -865,6 +1604,37 @@ const docTemplatedevicemanager = `{
                 "Structure"
             ]
         },
+        "health.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "model.BulkRequestElement": {
             "type": "object",
             "properties": {
@@ -921,9 +1691,15 @@ const docTemplatedevicemanager = `{
                         "type": "string"
                     }
                 },
+                "filter_incompatible_characteristics": {
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "string"
                 },
+                "import_filter": {
+                    "$ref": "#/definitions/model.ImportFilter"
+                },
                 "import_path_trim_first_element": {
                     "type": "boolean"
                 },
@@ -947,6 +1723,9 @@ const docTemplatedevicemanager = `{
                     "items": {
                         "type": "string"
                     }
+                },
+                "preferred_interaction": {
+                    "$ref": "#/definitions/devicemodel.Interaction"
                 }
             }
         },
@@ -964,6 +1743,67 @@ const docTemplatedevicemanager = `{
                 }
             }
         },
+        "model.CharacteristicMatch": {
+            "type": "object",
+            "properties": {
+                "baseCharacteristicId": {
+                    "type": "string"
+                },
+                "conceptId": {
+                    "type": "string"
+                },
+                "displayUnit": {
+                    "description": "display unit of CharacteristicId",
+                    "type": "string"
+                },
+                "targetCharacteristicId": {
+                    "type": "string"
+                },
+                "usability": {
+                    "$ref": "#/definitions/model.CharacteristicUsability"
+                }
+            }
+        },
+        "model.CharacteristicUsability": {
+            "type": "string",
+            "enum": [
+                "direct",
+                "conversion",
+                "incompatible"
+            ],
+            "x-enum-comments": {
+                "CharacteristicConversion": "CharacteristicId and the target characteristic belong to the concept of the function",
+                "CharacteristicDirect": "CharacteristicId is the target characteristic",
+                "CharacteristicIncompatible": "no conversion to the target characteristic is known"
+            },
+            "x-enum-descriptions": [
+                "CharacteristicId is the target characteristic",
+                "CharacteristicId and the target characteristic belong to the concept of the function",
+                "no conversion to the target characteristic is known"
+            ],
+            "x-enum-varnames": [
+                "CharacteristicDirect",
+                "CharacteristicConversion",
+                "CharacteristicIncompatible"
+            ]
+        },
+        "model.CriterionMatch": {
+            "type": "object",
+            "properties": {
+                "criterion": {
+                    "$ref": "#/definitions/devicemodel.FilterCriteria"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "matched": {
+                    "type": "boolean"
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
         "model.DeviceGroup": {
             "type": "object",
             "properties": {
@@ -1009,6 +1849,40 @@ const docTemplatedevicemanager = `{
                 }
             }
         },
+        "model.DeviceTypeSelection": {
+            "type": "object",
+            "properties": {
+                "deviceClassId": {
+                    "type": "string"
+                },
+                "deviceCount": {
+                    "description": "devices of this type the requesting user may execute",
+                    "type": "integer"
+                },
+                "deviceTypeId": {
+                    "description": "may contain an id modifier",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "servicePathOptions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/model.PathOption"
+                        }
+                    }
+                },
+                "services": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/devicemodel.Service"
+                    }
+                }
+            }
+        },
         "model.Import": {
             "type": "object",
             "properties": {
@@ -1038,6 +1912,25 @@ const docTemplatedevicemanager = `{
                 }
             }
         },
+        "model.ImportConfigFilter": {
+            "type": "object",
+            "properties": {
+                "equals": {},
+                "in": {
+                    "type": "array",
+                    "items": {}
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "model.ImportContentVariable": {
             "type": "object",
             "properties": {
@@ -1067,6 +1960,23 @@ const docTemplatedevicemanager = `{
                 }
             }
         },
+        "model.ImportFilter": {
+            "type": "object",
+            "properties": {
+                "configs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ImportConfigFilter"
+                    }
+                },
+                "kafka_topic": {
+                    "type": "string"
+                },
+                "restart": {
+                    "type": "boolean"
+                }
+            }
+        },
         "model.ImportTypeConfig": {
             "type": "object",
             "properties": {
@@ -1091,6 +2001,14 @@ const docTemplatedevicemanager = `{
                 "characteristicId": {
                     "type": "string"
                 },
+                "characteristicMatch": {
+                    "description": "set if the criterion of the option has a target_characteristic_id",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CharacteristicMatch"
+                        }
+                    ]
+                },
                 "configurables": {
                     "type": "array",
                     "items": {
@@ -1109,6 +2027,13 @@ const docTemplatedevicemanager = `{
                 "path": {
                     "type": "string"
                 },
+                "resolvedConfigurables": {
+                    "description": "set if the criterion of the option has configurables",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ResolvedConfigurable"
+                    }
+                },
                 "type": {
                     "$ref": "#/definitions/github_com_SENERGY-Platform_device-selection_pkg_model.Type"
                 },
@@ -1170,6 +2095,50 @@ const docTemplatedevicemanager = `{
                 }
             }
         },
+        "model.Ranking": {
+            "type": "object",
+            "properties": {
+                "criteria": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CriterionMatch"
+                    }
+                },
+                "owned": {
+                    "type": "boolean"
+                },
+                "preferredInteraction": {
+                    "type": "boolean"
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.ResolvedConfigurable": {
+            "type": "object",
+            "properties": {
+                "aspect_node": {
+                    "$ref": "#/definitions/models.AspectNode"
+                },
+                "characteristic": {
+                    "$ref": "#/definitions/devicemodel.Characteristic"
+                },
+                "characteristic_id": {
+                    "type": "string"
+                },
+                "function_id": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/models.Type"
+                },
+                "value": {}
+            }
+        },
         "model.Selectable": {
             "type": "object",
             "properties": {
@@ -1185,6 +2154,21 @@ const docTemplatedevicemanager = `{
                 "importType": {
                     "$ref": "#/definitions/github_com_SENERGY-Platform_device-selection_pkg_model.ImportType"
                 },
+                "matchedImportConfigs": {
+                    "description": "configs of Import that matched the import_filter",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SENERGY-Platform_device-selection_pkg_model.ImportConfig"
+                    }
+                },
+                "ranking": {
+                    "description": "set if the request has optional criteria or a preferred interaction",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Ranking"
+                        }
+                    ]
+                },
                 "servicePathOptions": {
                     "type": "object",
                     "additionalProperties": {
@@ -1316,6 +2300,9 @@ const docTemplatedevicemanager = `{
         "models.Interaction": {
             "type": "string",
             "enum": [
+                "event",
+                "request",
+                "event+request",
                 "event",
                 "request",
                 "event+request"
@@ -1342,6 +2329,12 @@ const docTemplatedevicemanager = `{
         "models.Type": {
             "type": "string",
             "enum": [
+                "https://schema.org/Text",
+                "https://schema.org/Integer",
+                "https://schema.org/Float",
+                "https://schema.org/Boolean",
+                "https://schema.org/ItemList",
+                "https://schema.org/StructuredValue",
                 "https://schema.org/Text",
                 "https://schema.org/Integer",
                 "https://schema.org/Float",
@@ -29,13 +29,12 @@ import (
 )
 
 func main() {
-	configLocation := flag.String("config", "config.json", "configuration file")
+	configLocation := flag.String("config", "config.json", "configuration file (json or yaml)")
 	flag.Parse()
 
 	config, err := configuration.Load(*configLocation)
 	if err != nil {
-		config.GetLogger().Error("FATAL: unable to load configuration", "error", err)
-		log.Fatal(err)
+		log.Fatal("FATAL: unable to load configuration: ", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -45,6 +44,11 @@ func main() {
 		log.Fatal(err)
 	}
 
+	err = configuration.Watch(ctx, config, *configLocation)
+	if err != nil {
+		config.GetLogger().Error("unable to watch config", "error", err)
+	}
+
 	go func() {
 		shutdown := make(chan os.Signal, 1)
 		signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
@@ -19,15 +19,21 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/SENERGY-Platform/device-selection/pkg/api/util"
+	"github.com/SENERGY-Platform/device-selection/pkg/audit"
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/device-selection/pkg/limits"
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
 	"github.com/SENERGY-Platform/service-commons/pkg/accesslog"
 )
 
@@ -38,11 +44,33 @@ type EndpointMethod = func(router *http.ServeMux, config configuration.Config, c
 
 var endpoints = []interface{}{} //list of objects with EndpointMethod
 
+const WriteTimeout = 10 * time.Second
+
+// PublicPaths are reachable without token if auth_verification is enabled
+var PublicPaths = []string{"/", "/health/live", "/health/ready", "/doc"}
+
+// AuditedPathPrefixes select the query endpoints that are recorded by the audit log
+var AuditedPathPrefixes = []string{"/selectables", "/v2/selectables", "/v2/query/", "/bulk/", "/v2/bulk/", "/device-group-helper"}
+
+type RouterOptions struct {
+	Verifier *auth.Verifier //nil: tokens are forwarded without validation
+	Audit    *audit.Logger  //nil: no audit log
+	Limits   *limits.Policy //nil: no rate limits and request budgets
+
+	Cors            util.CorsPolicy //zero value: no cors headers
+	SecurityHeaders bool
+	HstsMaxAge      time.Duration //0: no Strict-Transport-Security header
+}
+
 // starts http server; if wg is not nil it will be set as done when the server is stopped
 func Start(ctx context.Context, wg *sync.WaitGroup, config configuration.Config, ctrl *controller.Controller) (err error) {
 	config.GetLogger().Info("start api on " + config.ApiPort)
-	router := Router(config, ctrl)
-	server := &http.Server{Addr: ":" + config.ApiPort, Handler: router, WriteTimeout: 10 * time.Second, ReadTimeout: 2 * time.Second, ReadHeaderTimeout: 2 * time.Second}
+	options, err := NewRouterOptions(ctx, config)
+	if err != nil {
+		return err
+	}
+	router := RouterWithOptions(config, ctrl, options)
+	server := &http.Server{Addr: ":" + config.ApiPort, Handler: router, WriteTimeout: WriteTimeout, ReadTimeout: 2 * time.Second, ReadHeaderTimeout: 2 * time.Second}
 	wg.Add(1)
 	go func() {
 		config.GetLogger().Info("listening", "address", server.Addr)
@@ -70,12 +98,111 @@ func Start(ctx context.Context, wg *sync.WaitGroup, config configuration.Config,
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func Router(config configuration.Config, ctrl *controller.Controller) http.Handler {
+	options, err := NewRouterOptions(context.Background(), config)
+	if err != nil {
+		config.GetLogger().Error("FATAL: invalid router config", "error", err)
+		log.Fatal(err)
+	}
+	return RouterWithOptions(config, ctrl, options)
+}
+
+// NewRouterOptions creates the optional middleware dependencies; they are closed when ctx is done
+func NewRouterOptions(ctx context.Context, config configuration.Config) (result RouterOptions, err error) {
+	result.Verifier, err = auth.New(ctx, config)
+	if err != nil {
+		return result, err
+	}
+	result.Audit, err = audit.New(ctx, config)
+	if err != nil {
+		return result, err
+	}
+	result.Limits, err = limits.New(config)
+	if err != nil {
+		return result, err
+	}
+	policy := result.Limits
+	config.OnReload(func(config configuration.Config) {
+		err := policy.Update(config)
+		if err != nil {
+			config.GetLogger().Error("unable to update limits", "error", err)
+		}
+	})
+	result.Cors, err = NewCorsPolicy(config)
+	if err != nil {
+		return result, err
+	}
+	result.SecurityHeaders = config.SecurityHeaders
+	result.HstsMaxAge, err = parseOptionalDuration(config.SecurityHstsMaxAge)
+	if err != nil {
+		return result, fmt.Errorf("invalid security_hsts_max_age: %w", err)
+	}
+	return result, nil
+}
+
+func NewCorsPolicy(config configuration.Config) (result util.CorsPolicy, err error) {
+	for _, origin := range config.CorsAllowedOrigins {
+		if strings.Count(origin, "*") > 1 {
+			return result, fmt.Errorf("invalid cors_allowed_origins entry %q: only one wildcard is supported", origin)
+		}
+	}
+	result = util.CorsPolicy{
+		AllowedOrigins:   config.CorsAllowedOrigins,
+		AllowCredentials: config.CorsAllowCredentials,
+		AllowedMethods:   config.CorsAllowedMethods,
+		AllowedHeaders:   config.CorsAllowedHeaders,
+	}
+	result.MaxAge, err = parseOptionalDuration(config.CorsMaxAge)
+	if err != nil {
+		return result, fmt.Errorf("invalid cors_max_age: %w", err)
+	}
+	return result, nil
+}
+
+func parseOptionalDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func RouterWithOptions(config configuration.Config, ctrl *controller.Controller, options RouterOptions) http.Handler {
 	handler := GetRouterWithoutMiddleware(config, ctrl)
-	config.GetLogger().Info("add cors")
-	corsHandler := util.NewCors(handler)
+	config.GetLogger().Info("add request deadline")
+	deadlineHandler := util.NewDeadline(handler, WriteTimeout)
+	var rateLimitHandler http.Handler = deadlineHandler
+	if options.Limits != nil {
+		config.GetLogger().Info("add rate limits")
+		rateLimitHandler = util.NewRateLimit(deadlineHandler, options.Limits, PublicPaths)
+	}
+	var auditHandler http.Handler = rateLimitHandler
+	if options.Audit != nil {
+		config.GetLogger().Info("add audit log")
+		auditHandler = util.NewAudit(rateLimitHandler, options.Audit, AuditedPathPrefixes)
+	}
+	var authHandler http.Handler = auditHandler
+	if options.Verifier != nil {
+		config.GetLogger().Info("add token verification")
+		authHandler = util.NewAuth(auditHandler, options.Verifier, PublicPaths)
+	}
+	config.GetLogger().Info("add cors", "origins", options.Cors.AllowedOrigins)
+	corsHandler := util.NewCors(authHandler, options.Cors)
+	config.OnReload(func(config configuration.Config) {
+		policy, err := NewCorsPolicy(config)
+		if err != nil {
+			config.GetLogger().Error("unable to update cors policy", "error", err)
+			return
+		}
+		corsHandler.SetPolicy(policy)
+	})
+	var securityHandler http.Handler = corsHandler
+	if options.SecurityHeaders {
+		config.GetLogger().Info("add security headers")
+		securityHandler = util.NewSecurityHeaders(corsHandler, options.HstsMaxAge)
+	}
 	config.GetLogger().Info("add logging")
-	logger := accesslog.New(corsHandler)
-	return logger
+	logger := accesslog.New(securityHandler)
+	config.GetLogger().Info("add tracing")
+	return tracing.NewHandler(logger)
 }
 
 func GetRouterWithoutMiddleware(config configuration.Config, command *controller.Controller) http.Handler {
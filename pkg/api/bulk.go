@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"runtime/debug"
 
+	"github.com/SENERGY-Platform/device-selection/pkg/audit"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
@@ -40,35 +41,50 @@ type BulkEndpoints struct{}
 // @Accept       json
 // @Produce      json
 // @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; selectables are computed as this user would see them"
 // @Param        message body model.BulkRequestV2 true "BulkRequestV2"
 // @Param        complete_services query bool false "adds full import-type and import path options to the result. device services are already complete, the name is a legacy artefact"
+// @Param        format query string false "list (default) or grouped; grouped responds with model.GroupedBulkResult where the devices of every element reference the services and path options of their device-type"
 // @Success      200 {array}  model.BulkResult
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      413
+// @Failure      429
 // @Failure      500
 // @Router       /v2/bulk/selectables [POST]
 func (this *BulkEndpoints) SelectablesV2(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
 	router.HandleFunc("POST /v2/bulk/selectables", func(writer http.ResponseWriter, request *http.Request) {
-		token := request.Header.Get("Authorization")
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+
+		format, err := getSelectablesFormatFromRequest(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
 
 		criteria := model.BulkRequestV2{}
-		err := json.NewDecoder(request.Body).Decode(&criteria)
+		err = json.NewDecoder(request.Body).Decode(&criteria)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		audit.FromContext(request.Context()).SetCriteria(criteria)
 
 		config.GetLogger().Debug("bulk request", "criteria", fmt.Sprintf("%+v", criteria))
 
-		result, err, code := ctrl.BulkGetFilteredDevicesV2(token, criteria)
+		result, err, code := ctrl.BulkGetFilteredDevicesV2(request.Context(), token, criteria)
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
 		}
 		if request.URL.Query().Get("complete_services") == "true" {
-			result, err = ctrl.CompleteBulkServicesV2(token, result, criteria)
+			result, err = ctrl.CompleteBulkServicesV2(request.Context(), token, result, criteria)
 			if err != nil {
 				http.Error(writer, err.Error(), http.StatusInternalServerError)
 				return
@@ -77,8 +93,13 @@ func (this *BulkEndpoints) SelectablesV2(router *http.ServeMux, config configura
 
 		config.GetLogger().Debug("bulk request result", "result", fmt.Sprintf("%+v", result))
 
+		audit.FromContext(request.Context()).AddBulkResult(result)
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
-		err = json.NewEncoder(writer).Encode(result)
+		if format == SelectablesFormatGrouped {
+			err = json.NewEncoder(writer).Encode(model.GroupBulkResult(result))
+		} else {
+			err = json.NewEncoder(writer).Encode(result)
+		}
 		if err != nil {
 			config.GetLogger().Error("unable to encode result", "error", err)
 			debug.PrintStack()
@@ -93,6 +114,7 @@ func (this *BulkEndpoints) SelectablesV2(router *http.ServeMux, config configura
 // @Accept       json
 // @Produce      json
 // @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; selectables are computed as this user would see them"
 // @Param        message body model.BulkRequest true "BulkRequest"
 // @Param        complete_services query bool false "adds full import-type and import path options to the result. device services are already complete, the name is a legacy artefact"
 // @Success      200 {array}  model.BulkResult
@@ -100,28 +122,35 @@ func (this *BulkEndpoints) SelectablesV2(router *http.ServeMux, config configura
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      413
+// @Failure      429
 // @Failure      500
 // @Router       /bulk/selectables [POST]
 func (this *BulkEndpoints) Selectables(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
 	router.HandleFunc("POST /bulk/selectables", func(writer http.ResponseWriter, request *http.Request) {
-		token := request.Header.Get("Authorization")
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
 
 		criteria := model.BulkRequest{}
-		err := json.NewDecoder(request.Body).Decode(&criteria)
+		err = json.NewDecoder(request.Body).Decode(&criteria)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		audit.FromContext(request.Context()).SetCriteria(criteria)
 
 		config.GetLogger().Debug("bulk request", "criteria", fmt.Sprintf("%+v", criteria))
 
-		result, err, code := ctrl.BulkGetFilteredDevices(token, criteria)
+		result, err, code := ctrl.BulkGetFilteredDevices(request.Context(), token, criteria)
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
 		}
 		if request.URL.Query().Get("complete_services") == "true" {
-			result, err = ctrl.CompleteBulkServices(token, result, criteria)
+			result, err = ctrl.CompleteBulkServices(request.Context(), token, result, criteria)
 			if err != nil {
 				http.Error(writer, err.Error(), http.StatusInternalServerError)
 				return
@@ -130,6 +159,7 @@ func (this *BulkEndpoints) Selectables(router *http.ServeMux, config configurati
 
 		config.GetLogger().Debug("bulk request result", "result", fmt.Sprintf("%+v", result))
 
+		audit.FromContext(request.Context()).AddBulkResult(result)
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
 		err = json.NewEncoder(writer).Encode(result)
 		if err != nil {
@@ -146,23 +176,31 @@ func (this *BulkEndpoints) Selectables(router *http.ServeMux, config configurati
 // @Accept       json
 // @Produce      json
 // @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; selectables are computed as this user would see them"
 // @Param        message body model.BulkRequest true "BulkRequest"
 // @Success      200 {array}  []model.PermSearchDevice
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      413
+// @Failure      429
 // @Failure      500
 // @Router       /bulk/selectables/combined/devices [POST]
 func (this *BulkEndpoints) SelectablesCombinedDevices(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
 	router.HandleFunc("POST /bulk/selectables/combined/devices", func(writer http.ResponseWriter, request *http.Request) {
-		token := request.Header.Get("Authorization")
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
 		criteria := model.BulkRequest{}
-		err := json.NewDecoder(request.Body).Decode(&criteria)
+		err = json.NewDecoder(request.Body).Decode(&criteria)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		audit.FromContext(request.Context()).SetCriteria(criteria)
 		for _, element := range criteria {
 			if element.IncludeGroups {
 				http.Error(writer, "unable to combine devices when groups are expected (fix: set include_groups to false)", http.StatusBadRequest)
@@ -173,12 +211,13 @@ func (this *BulkEndpoints) SelectablesCombinedDevices(router *http.ServeMux, con
 				return
 			}
 		}
-		temp, err, code := ctrl.BulkGetFilteredDevices(token, criteria)
+		temp, err, code := ctrl.BulkGetFilteredDevices(request.Context(), token, criteria)
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
 		}
 		result := ctrl.CombinedDevices(temp)
+		audit.FromContext(request.Context()).AddDevices(result)
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
 		err = json.NewEncoder(writer).Encode(result)
 		if err != nil {
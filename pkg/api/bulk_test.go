@@ -0,0 +1,103 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestBulkSelectablesV2Grouped(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.LoadFixtures("../tests/environment/testdata/fixtures.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.Devices = append(fixtures.Devices,
+		models.Device{Id: "h2", LocalId: "h2", Name: "h2", DeviceTypeId: "heater"},
+		models.Device{Id: "h3", LocalId: "h3", Name: "h3", DeviceTypeId: "heater"},
+	)
+	env := environment.NewHermetic(ctx, wg)
+	env.Load(fixtures)
+
+	config := &configuration.ConfigStruct{
+		DeviceRepoUrl:   env.DeviceRepoUrl,
+		ImportRepoUrl:   env.ImportRepoUrl,
+		ImportDeployUrl: env.ImportDeploy.Url(),
+	}
+	ctrl, err := environment.NewController(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(Router(config, ctrl))
+	defer server.Close()
+	c := client.NewClient(server.URL)
+
+	request := model.BulkRequestV2{
+		{Id: "set", Criteria: model.FilterCriteriaAndSet{{FunctionId: devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature", AspectId: "air"}}, IncludeDevices: true},
+		{Id: "get", Criteria: model.FilterCriteriaAndSet{{FunctionId: devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature", AspectId: "air"}}, IncludeDevices: true, IncludeImports: true},
+	}
+	expected, code, err := c.BulkSelectablesV2(ctx, client.InternalAdminToken, request, nil)
+	if err != nil || code != http.StatusOK {
+		t.Fatal(err, code)
+	}
+	grouped, code, err := c.BulkGroupedSelectablesV2(ctx, client.InternalAdminToken, request, nil)
+	if err != nil || code != http.StatusOK {
+		t.Fatal(err, code)
+	}
+
+	if len(grouped) != 2 || grouped[0].Id != "set" || grouped[1].Id != "get" {
+		t.Fatalf("%#v", grouped)
+	}
+	if len(grouped[0].DeviceTypes) != 2 || len(grouped[0].DeviceTypes["heater"].Services) != 1 {
+		t.Errorf("%#v", grouped[0].DeviceTypes)
+	}
+	for _, selectable := range grouped[0].Selectables {
+		if selectable.Device == nil || selectable.Services != nil {
+			t.Errorf("%#v", selectable)
+		}
+	}
+	expectedJson, _ := json.Marshal(expected)
+	actualJson, _ := json.Marshal(grouped.Expand())
+	if string(actualJson) != string(expectedJson) {
+		t.Errorf("\na=%s\ne=%s", actualJson, expectedJson)
+	}
+
+	resp, err := http.Post(server.URL+"/v2/bulk/selectables?format=foo", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("expected unknown format to be rejected", resp.StatusCode)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func init() {
+	endpoints = append(endpoints, &ConfigEndpoints{})
+}
+
+type ConfigEndpoints struct{}
+
+// EffectiveConfig godoc
+// @Summary      effective config
+// @Description  returns the config including fields changed by a reload (SIGHUP or config_reload_interval); secrets are redacted. admin only.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  configuration.ConfigStruct
+// @Failure      401
+// @Failure      403
+// @Router       /admin/config [GET]
+func (this *ConfigEndpoints) EffectiveConfig(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
+	router.HandleFunc("GET /admin/config", func(writer http.ResponseWriter, request *http.Request) {
+		result, err, code := ctrl.GetEffectiveConfig(request.Context(), jwt.GetAuthToken(request))
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = json.NewEncoder(writer).Encode(result)
+		if err != nil {
+			config.GetLogger().Error("unable to encode result", "error", err)
+		}
+	})
+}
@@ -24,9 +24,11 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/SENERGY-Platform/device-selection/pkg/audit"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 )
 
 func init() {
@@ -42,24 +44,33 @@ type DeviceGroupsHelper struct{}
 // @Accept       json
 // @Produce      json
 // @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; selectables are computed as this user would see them"
+// @Param        aspect_match query string false "exact, descendants (default), ancestors or both; measuring criteria get every aspect matching the device aspects in this mode"
 // @Param        message body []string true "device id list"
 // @Success      200 {array}  model.DeviceGroupHelperResult
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      413
+// @Failure      429
 // @Failure      500
 // @Router       /device-group-helper [POST]
 func (this *DeviceGroupsHelper) DeviceGroupsHelper(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
 	router.HandleFunc("POST /device-group-helper", func(writer http.ResponseWriter, request *http.Request) {
-		token := request.Header.Get("Authorization")
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
 
 		deviceIds := []string{}
-		err := json.NewDecoder(request.Body).Decode(&deviceIds)
+		err = json.NewDecoder(request.Body).Decode(&deviceIds)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		audit.FromContext(request.Context()).SetCriteria(deviceIds)
 
 		search := model.DeviceGroupHelperPagination{
 			Search: request.URL.Query().Get("search"),
@@ -93,11 +104,18 @@ func (this *DeviceGroupsHelper) DeviceGroupsHelper(router *http.ServeMux, config
 			functionBlockList = strings.Split(functionBlockListStr, ",")
 		}
 
-		result, err, code := ctrl.DeviceGroupHelper(token, deviceIds, search, filterMaintainsGroupUsability, functionBlockList)
+		aspectMatch := devicemodel.AspectMatch(request.URL.Query().Get("aspect_match"))
+
+		result, err, code := ctrl.DeviceGroupHelper(request.Context(), token, deviceIds, search, filterMaintainsGroupUsability, functionBlockList, aspectMatch)
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
 		}
+		offeredDevices := []model.PermSearchDevice{}
+		for _, option := range result.Options {
+			offeredDevices = append(offeredDevices, option.Device)
+		}
+		audit.FromContext(request.Context()).AddDevices(offeredDevices)
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
 		err = json.NewEncoder(writer).Encode(result)
 		if err != nil {
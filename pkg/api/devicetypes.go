@@ -0,0 +1,92 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/audit"
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+)
+
+func init() {
+	endpoints = append(endpoints, &DeviceTypesEndpoints{})
+}
+
+type DeviceTypesEndpoints struct{}
+
+// QueryDeviceTypes godoc
+// @Summary      device-types
+// @Description  finds device-types that match all provided filter-criteria, with their matching services and path options and the count of devices the user may execute
+// @Tags         device-types, selectables
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; device-types and device counts are computed as this user would see them"
+// @Param        include_id_modified query bool false "result should include all valid device-type id modifications"
+// @Param        filter_incompatible_characteristics query bool false "remove path options that can not be converted to the target_characteristic_id of their criterion"
+// @Param        message body model.FilterCriteriaAndSet true "criteria list"
+// @Success      200 {array}  model.DeviceTypeSelection
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      413
+// @Failure      429
+// @Failure      500
+// @Router       /v2/query/device-types [POST]
+func (this *DeviceTypesEndpoints) QueryDeviceTypes(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
+	router.HandleFunc("POST /v2/query/device-types", func(writer http.ResponseWriter, request *http.Request) {
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+
+		var criteria model.FilterCriteriaAndSet
+		err = json.NewDecoder(request.Body).Decode(&criteria)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		audit.FromContext(request.Context()).SetCriteria(criteria)
+
+		includeIdModified, _ := strconv.ParseBool(request.URL.Query().Get("include_id_modified"))
+		filterIncompatibleCharacteristics, _ := strconv.ParseBool(request.URL.Query().Get("filter_incompatible_characteristics"))
+
+		result, err, code := ctrl.QueryDeviceTypes(request.Context(), token, model.QueryDeviceTypesOptions{
+			FilterCriteria:                    criteria,
+			IncludeIdModified:                 includeIdModified,
+			FilterIncompatibleCharacteristics: filterIncompatibleCharacteristics,
+		})
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = json.NewEncoder(writer).Encode(result)
+		if err != nil {
+			config.GetLogger().Error("unable to encode result", "error", err)
+			debug.PrintStack()
+		}
+	})
+}
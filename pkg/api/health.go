@@ -0,0 +1,71 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/health"
+)
+
+func init() {
+	endpoints = append(endpoints, &HealthEndpoints{})
+}
+
+type HealthEndpoints struct{}
+
+// Live godoc
+// @Summary      liveness
+// @Description  returns 200 as long as the service is able to handle requests; dependencies are not checked
+// @Tags         health
+// @Produce      json
+// @Success      200 {object}  health.Report
+// @Router       /health/live [GET]
+func (this *HealthEndpoints) Live(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
+	router.HandleFunc("GET /health/live", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err := json.NewEncoder(writer).Encode(health.Report{Status: health.StatusUp})
+		if err != nil {
+			config.GetLogger().Error("unable to encode result", "error", err)
+		}
+	})
+}
+
+// Ready godoc
+// @Summary      readiness
+// @Description  probes every configured dependency (device-repository, import-deploy, import-repository, memcached, kafka) and reports their status. probe results are cached for health_check_cache_duration.
+// @Tags         health
+// @Produce      json
+// @Success      200 {object}  health.Report
+// @Failure      503 {object}  health.Report
+// @Router       /health/ready [GET]
+func (this *HealthEndpoints) Ready(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
+	router.HandleFunc("GET /health/ready", func(writer http.ResponseWriter, request *http.Request) {
+		report := ctrl.CheckHealth(request.Context())
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if report.Status != health.StatusUp {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+		err := json.NewEncoder(writer).Encode(report)
+		if err != nil {
+			config.GetLogger().Error("unable to encode result", "error", err)
+		}
+	})
+}
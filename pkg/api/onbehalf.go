@@ -0,0 +1,50 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/audit"
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+)
+
+// OnBehalfOfHeader lets admins (e.g. process sync or smart-service repair) request selectables as the named user would see them
+const OnBehalfOfHeader = "X-On-Behalf-Of"
+
+// getToken returns the Authorization header or, if the X-On-Behalf-Of header is set, a token of the named user
+func getToken(config configuration.Config, ctrl *controller.Controller, request *http.Request) (token string, err error, code int) {
+	token = request.Header.Get("Authorization")
+	userId := request.Header.Get(OnBehalfOfHeader)
+	if userId == "" {
+		return token, nil, http.StatusOK
+	}
+	token, caller, err, code := ctrl.GetTokenOnBehalfOf(request.Context(), token, userId)
+	if err == nil {
+		audit.FromContext(request.Context()).SetOnBehalfOf(userId)
+	}
+	config.GetLogger().Info("on behalf of request",
+		"audit", true,
+		"caller", caller.GetUserId(),
+		"on_behalf_of", userId,
+		"method", request.Method,
+		"path", request.URL.Path,
+		"allowed", err == nil,
+	)
+	return token, err, code
+}
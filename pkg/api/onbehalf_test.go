@@ -0,0 +1,150 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	gojwt "github.com/golang-jwt/jwt"
+)
+
+func TestOnBehalfOfNeedsVerifiedToken(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exchanges := 0
+	keycloak := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		exchanges++
+		token, _ := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": request.FormValue("requested_subject")}).SignedString([]byte("test"))
+		json.NewEncoder(writer).Encode(map[string]interface{}{"access_token": token, "expires_in": 300})
+	}))
+	defer keycloak.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kid": "test-key",
+		"kty": "RSA",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(jwksFile, set, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := func(roles ...string) gojwt.MapClaims {
+		return gojwt.MapClaims{"sub": "caller", "exp": time.Now().Add(time.Minute).Unix(), "realm_access": map[string]interface{}{"roles": roles}}
+	}
+	signed := func(claims gojwt.MapClaims) string {
+		token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test-key"
+		result, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + result
+	}
+	unsigned, err := gojwt.NewWithClaims(gojwt.SigningMethodNone, claims("admin")).SignedString(gojwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := environment.NewHermetic(ctx, wg)
+	newServer := func(verification string) *httptest.Server {
+		config := &configuration.ConfigStruct{
+			DeviceRepoUrl:    env.DeviceRepoUrl,
+			ImportRepoUrl:    env.ImportRepoUrl,
+			ImportDeployUrl:  env.ImportDeploy.Url(),
+			AuthEndpoint:     keycloak.URL,
+			AuthClientId:     "device-selection",
+			AuthClientSecret: "secret",
+			AuthVerification: verification,
+			AuthJwksUrl:      jwksFile,
+		}
+		ctrl, err := environment.NewController(ctx, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewServer(Router(config, ctrl))
+		t.Cleanup(server.Close)
+		return server
+	}
+	request := func(server *httptest.Server, token string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v2/query/selectables?include_devices=true", strings.NewReader(`[{"function_id":"urn:infai:ses:measuring-function:getTemperature"}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", token)
+		req.Header.Set(OnBehalfOfHeader, "user1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	unverified := newServer(auth.VerificationNone)
+	if code := request(unverified, "Bearer "+unsigned); code != http.StatusForbidden {
+		t.Error("unsigned admin token without verification", code)
+	}
+	if code := request(unverified, signed(claims("admin"))); code != http.StatusForbidden {
+		t.Error("signed admin token without verification", code)
+	}
+
+	verified := newServer(auth.VerificationJwks)
+	if code := request(verified, "Bearer "+unsigned); code != http.StatusUnauthorized {
+		t.Error("unsigned admin token with verification", code)
+	}
+	if code := request(verified, signed(claims("user"))); code != http.StatusForbidden {
+		t.Error("user token with verification", code)
+	}
+	if exchanges != 0 {
+		t.Error("unexpected token exchange", exchanges)
+	}
+	if code := request(verified, signed(claims("admin"))); code != http.StatusOK {
+		t.Error("admin token with verification", code)
+	}
+	if exchanges != 1 {
+		t.Error("expected token exchange", exchanges)
+	}
+}
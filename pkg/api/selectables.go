@@ -19,11 +19,13 @@ package api
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"runtime/debug"
 	"strconv"
 	"strings"
 
+	"github.com/SENERGY-Platform/device-selection/pkg/audit"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
@@ -42,6 +44,7 @@ type SelectablesEndpoints struct{}
 // @Tags         selectables, deprecated
 // @Produce      json
 // @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; selectables are computed as this user would see them"
 // @Param        include_groups query bool false "result should include matching device-groups"
 // @Param        include_imports query bool false "result should include matching imports"
 // @Param        local_devices query string false "comma seperated list of local device ids; result devices must be in this list (if one is given)"
@@ -53,21 +56,29 @@ type SelectablesEndpoints struct{}
 // @Param        function_id query string false "alternative to json and base64 if only one filter criteria is needed"
 // @Param        device_class_id query string false "alternative to json and base64 if only one filter criteria is needed"
 // @Param        aspect_id query string false "alternative to json and base64 if only one filter criteria is needed"
+// @Param        aspect_match query string false "alternative to json and base64 if only one filter criteria is needed; exact, descendants (default), ancestors or both"
 // @Success      200 {array}  []model.Selectable
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      413
+// @Failure      429
 // @Failure      500
 // @Router       /selectables [GET]
 func (this *DeviceGroupsHelper) Selectables(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
 	router.HandleFunc("GET /selectables", func(writer http.ResponseWriter, request *http.Request) {
-		token := request.Header.Get("Authorization")
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
 		criteria, blockedProtocols, blockedInteraction, err := getCriteriaFromRequest(request)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		audit.FromContext(request.Context()).SetCriteria(criteria)
 
 		includeGroups, _ := strconv.ParseBool(request.URL.Query().Get("include_groups"))
 		includeImports, _ := strconv.ParseBool(request.URL.Query().Get("include_imports"))
@@ -80,18 +91,19 @@ func (this *DeviceGroupsHelper) Selectables(router *http.ServeMux, config config
 			}
 		}
 
-		result, err, code := ctrl.GetFilteredDevices(token, criteria, blockedProtocols, blockedInteraction, includeGroups, includeImports, withLocalDeviceIds)
+		result, err, code := ctrl.GetFilteredDevices(request.Context(), token, criteria, blockedProtocols, blockedInteraction, includeGroups, includeImports, withLocalDeviceIds)
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
 		}
 		if request.URL.Query().Get("complete_services") == "true" {
-			result, err = ctrl.CompleteServices(token, result, criteria)
+			result, err = ctrl.CompleteServices(request.Context(), token, result, criteria)
 			if err != nil {
 				http.Error(writer, err.Error(), http.StatusInternalServerError)
 				return
 			}
 		}
+		audit.FromContext(request.Context()).AddSelectables(result)
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
 		err = json.NewEncoder(writer).Encode(result)
 		if err != nil {
@@ -107,6 +119,7 @@ func (this *DeviceGroupsHelper) Selectables(router *http.ServeMux, config config
 // @Tags         selectables
 // @Produce      json
 // @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; selectables are computed as this user would see them"
 // @Param        include_devices query bool false "result should include matching devices"
 // @Param        include_groups query bool false "result should include matching device-groups"
 // @Param        include_imports query bool false "result should include matching imports"
@@ -121,28 +134,41 @@ func (this *DeviceGroupsHelper) Selectables(router *http.ServeMux, config config
 // @Param        function_id query string false "alternative to json and base64 if only one filter criteria is needed"
 // @Param        device_class_id query string false "alternative to json and base64 if only one filter criteria is needed"
 // @Param        aspect_id query string false "alternative to json and base64 if only one filter criteria is needed"
+// @Param        aspect_match query string false "alternative to json and base64 if only one filter criteria is needed; exact, descendants (default), ancestors or both"
 // @Param        filter_devices_by_attr_keys query string false "comma seperated list of attribute keys; result devices have these attributes (if one is given)"
+// @Param        filter_incompatible_characteristics query bool false "remove path options that can not be converted to the target_characteristic_id of their criterion"
+// @Param        import_filter query string false "json encoded model.ImportFilter; result imports match these config values and properties (like {&quot;configs&quot;:[{&quot;name&quot;:&quot;city&quot;,&quot;equals&quot;:&quot;Leipzig&quot;}]})"
+// @Param        preferred_interaction query string false "event or request; selectables with this interaction are ranked higher (see model.Ranking)"
+// @Param        format query string false "list (default) or grouped; grouped responds with model.GroupedSelectables where devices reference the services and path options of their device-type"
 // @Success      200 {array}  []model.Selectable
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      413
+// @Failure      429
 // @Failure      500
 // @Router       /v2/selectables [GET]
 func (this *DeviceGroupsHelper) SelectablesV2(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
 	router.HandleFunc("GET /v2/selectables", func(writer http.ResponseWriter, request *http.Request) {
-		token := request.Header.Get("Authorization")
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
 		criteria, err := getCriteriaFromRequestV2(request)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		audit.FromContext(request.Context()).SetCriteria(criteria)
 
 		includeGroups, _ := strconv.ParseBool(request.URL.Query().Get("include_groups"))
 		includeImports, _ := strconv.ParseBool(request.URL.Query().Get("include_imports"))
 		includeDevices, _ := strconv.ParseBool(request.URL.Query().Get("include_devices"))
 		includeIdModified, _ := strconv.ParseBool(request.URL.Query().Get("include_id_modified"))
 		importPathTrimFirstElement, _ := strconv.ParseBool(request.URL.Query().Get("import_path_trim_first_element"))
+		filterIncompatibleCharacteristics, _ := strconv.ParseBool(request.URL.Query().Get("filter_incompatible_characteristics"))
 
 		var withLocalDeviceIds []string
 		localDevicesQueryParam := request.URL.Query().Get("local_devices")
@@ -172,7 +198,19 @@ func (this *DeviceGroupsHelper) SelectablesV2(router *http.ServeMux, config conf
 			}
 		}
 
-		result, err, code := ctrl.GetFilteredDevicesV2(token, model.GetFilteredDevicesV2Options{
+		importFilter, err := getImportFilterFromRequest(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		format, err := getSelectablesFormatFromRequest(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.GetFilteredDevicesV2(request.Context(), token, model.GetFilteredDevicesV2Options{
 			FilterCriteria:              criteria,
 			IncludeDevices:              includeDevices,
 			IncludeGroups:               includeGroups,
@@ -183,13 +221,22 @@ func (this *DeviceGroupsHelper) SelectablesV2(router *http.ServeMux, config conf
 			LocalDeviceOwner:            localDeviceOwner,
 			FilterByDeviceAttributeKeys: filterDevicesByAttributeKeys,
 			ImportPathTrimFirstElement:  importPathTrimFirstElement,
+			ImportFilter:                importFilter,
+
+			FilterIncompatibleCharacteristics: filterIncompatibleCharacteristics,
+			PreferredInteraction:              devicemodel.Interaction(request.URL.Query().Get("preferred_interaction")),
 		})
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
 		}
+		audit.FromContext(request.Context()).AddSelectables(result)
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
-		err = json.NewEncoder(writer).Encode(result)
+		if format == SelectablesFormatGrouped {
+			err = json.NewEncoder(writer).Encode(model.GroupSelectables(result))
+		} else {
+			err = json.NewEncoder(writer).Encode(result)
+		}
 		if err != nil {
 			config.GetLogger().Error("unable to encode result", "error", err)
 			debug.PrintStack()
@@ -203,6 +250,7 @@ func (this *DeviceGroupsHelper) SelectablesV2(router *http.ServeMux, config conf
 // @Tags         selectables
 // @Produce      json
 // @Security Bearer
+// @Param        X-On-Behalf-Of header string false "admin only: user id; selectables are computed as this user would see them"
 // @Param        include_devices query bool false "result should include matching devices"
 // @Param        include_groups query bool false "result should include matching device-groups"
 // @Param        include_imports query bool false "result should include matching imports"
@@ -212,30 +260,42 @@ func (this *DeviceGroupsHelper) SelectablesV2(router *http.ServeMux, config conf
 // @Param        local_devices query string false "comma seperated list of local device ids; result devices must be in this list (if one is given)"
 // @Param        local_device_owner query string false "used in combination with local_devices to identify devices, default is the requesting user"
 // @Param        filter_devices_by_attr_keys query string false "comma seperated list of attribute keys; result devices have these attributes (if one is given)"
+// @Param        filter_incompatible_characteristics query bool false "remove path options that can not be converted to the target_characteristic_id of their criterion"
+// @Param        import_filter query string false "json encoded model.ImportFilter; result imports match these config values and properties (like {&quot;configs&quot;:[{&quot;name&quot;:&quot;city&quot;,&quot;equals&quot;:&quot;Leipzig&quot;}]})"
+// @Param        preferred_interaction query string false "event or request; selectables with this interaction are ranked higher (see model.Ranking)"
+// @Param        format query string false "list (default) or grouped; grouped responds with model.GroupedSelectables where devices reference the services and path options of their device-type"
 // @Param        message body model.FilterCriteriaAndSet true "criteria list"
 // @Success      200 {array}  []model.Selectable
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      413
+// @Failure      429
 // @Failure      500
 // @Router       /v2/query/selectables [POST]
 func (this *DeviceGroupsHelper) QuerySelectables(router *http.ServeMux, config configuration.Config, ctrl *controller.Controller) {
 	router.HandleFunc("POST /v2/query/selectables", func(writer http.ResponseWriter, request *http.Request) {
-		token := request.Header.Get("Authorization")
+		token, err, code := getToken(config, ctrl, request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
 
 		var criteria model.FilterCriteriaAndSet
-		err := json.NewDecoder(request.Body).Decode(&criteria)
+		err = json.NewDecoder(request.Body).Decode(&criteria)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		audit.FromContext(request.Context()).SetCriteria(criteria)
 
 		includeGroups, _ := strconv.ParseBool(request.URL.Query().Get("include_groups"))
 		includeImports, _ := strconv.ParseBool(request.URL.Query().Get("include_imports"))
 		includeDevices, _ := strconv.ParseBool(request.URL.Query().Get("include_devices"))
 		includeIdModified, _ := strconv.ParseBool(request.URL.Query().Get("include_id_modified"))
 		importPathTrimFirstElement, _ := strconv.ParseBool(request.URL.Query().Get("import_path_trim_first_element"))
+		filterIncompatibleCharacteristics, _ := strconv.ParseBool(request.URL.Query().Get("filter_incompatible_characteristics"))
 
 		var withLocalDeviceIds []string = nil
 		localDevicesQueryParam := request.URL.Query().Get("local_devices")
@@ -263,7 +323,19 @@ func (this *DeviceGroupsHelper) QuerySelectables(router *http.ServeMux, config c
 			}
 		}
 
-		result, err, code := ctrl.GetFilteredDevicesV2(token, model.GetFilteredDevicesV2Options{
+		importFilter, err := getImportFilterFromRequest(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		format, err := getSelectablesFormatFromRequest(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.GetFilteredDevicesV2(request.Context(), token, model.GetFilteredDevicesV2Options{
 			FilterCriteria:              criteria,
 			IncludeDevices:              includeDevices,
 			IncludeGroups:               includeGroups,
@@ -274,13 +346,22 @@ func (this *DeviceGroupsHelper) QuerySelectables(router *http.ServeMux, config c
 			LocalDeviceOwner:            localDeviceOwner,
 			FilterByDeviceAttributeKeys: filterDevicesByAttributeKeys,
 			ImportPathTrimFirstElement:  importPathTrimFirstElement,
+			ImportFilter:                importFilter,
+
+			FilterIncompatibleCharacteristics: filterIncompatibleCharacteristics,
+			PreferredInteraction:              devicemodel.Interaction(request.URL.Query().Get("preferred_interaction")),
 		})
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
 		}
+		audit.FromContext(request.Context()).AddSelectables(result)
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
-		err = json.NewEncoder(writer).Encode(result)
+		if format == SelectablesFormatGrouped {
+			err = json.NewEncoder(writer).Encode(model.GroupSelectables(result))
+		} else {
+			err = json.NewEncoder(writer).Encode(result)
+		}
 		if err != nil {
 			config.GetLogger().Error("unable to encode result", "error", err)
 			debug.PrintStack()
@@ -288,6 +369,35 @@ func (this *DeviceGroupsHelper) QuerySelectables(router *http.ServeMux, config c
 	})
 }
 
+const SelectablesFormatList = "list"
+const SelectablesFormatGrouped = "grouped" //model.GroupedSelectables
+
+// getSelectablesFormatFromRequest returns SelectablesFormatList if the request has no format
+func getSelectablesFormatFromRequest(request *http.Request) (string, error) {
+	switch format := request.URL.Query().Get("format"); format {
+	case "", SelectablesFormatList:
+		return SelectablesFormatList, nil
+	case SelectablesFormatGrouped:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// getImportFilterFromRequest returns nil if the request has no import_filter
+func getImportFilterFromRequest(request *http.Request) (result *model.ImportFilter, err error) {
+	param := request.URL.Query().Get("import_filter")
+	if param == "" {
+		return nil, nil
+	}
+	result = &model.ImportFilter{}
+	err = json.Unmarshal([]byte(param), result)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import_filter: %w", err)
+	}
+	return result, result.Validate()
+}
+
 func getCriteriaFromRequest(request *http.Request) (criteria model.FilterCriteriaAndSet, protocolBlockList []string, blockedInteraction devicemodel.Interaction, err error) {
 	if filterProtocols := request.URL.Query().Get("filter_protocols"); filterProtocols != "" {
 		protocolBlockList = strings.Split(filterProtocols, ",")
@@ -314,6 +424,7 @@ func getCriteriaFromRequest(request *http.Request) (criteria model.FilterCriteri
 		FunctionId:    request.URL.Query().Get("function_id"),
 		DeviceClassId: request.URL.Query().Get("device_class_id"),
 		AspectId:      request.URL.Query().Get("aspect_id"),
+		AspectMatch:   devicemodel.AspectMatch(request.URL.Query().Get("aspect_match")),
 	}}
 	return
 }
@@ -334,6 +445,7 @@ func getCriteriaFromRequestV2(request *http.Request) (criteria model.FilterCrite
 		FunctionId:    request.URL.Query().Get("function_id"),
 		DeviceClassId: request.URL.Query().Get("device_class_id"),
 		AspectId:      request.URL.Query().Get("aspect_id"),
+		AspectMatch:   devicemodel.AspectMatch(request.URL.Query().Get("aspect_match")),
 	}}
 	return
 }
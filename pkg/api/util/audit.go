@@ -0,0 +1,68 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/audit"
+)
+
+// NewAudit writes an audit record for every request to a path starting with one of pathPrefixes
+func NewAudit(handler http.Handler, logger *audit.Logger, pathPrefixes []string) *AuditMiddleware {
+	return &AuditMiddleware{handler: handler, logger: logger, pathPrefixes: pathPrefixes}
+}
+
+type AuditMiddleware struct {
+	handler      http.Handler
+	logger       *audit.Logger
+	pathPrefixes []string
+}
+
+func (this *AuditMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if !this.isAudited(req.URL.Path) {
+		this.handler.ServeHTTP(res, req)
+		return
+	}
+	start := time.Now()
+	ctx, entry := audit.NewEntryContext(req.Context())
+	recorder := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+	req = req.WithContext(ctx)
+	this.handler.ServeHTTP(recorder, req)
+	this.logger.Log(entry.Record(req, recorder.status, start))
+}
+
+func (this *AuditMiddleware) isAudited(path string) bool {
+	for _, prefix := range this.pathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (this *statusRecorder) WriteHeader(status int) {
+	this.status = status
+	this.ResponseWriter.WriteHeader(status)
+}
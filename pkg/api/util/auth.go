@@ -0,0 +1,49 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// NewAuth rejects requests with invalid tokens; requests to publicPaths and from trusted networks are passed without verification
+func NewAuth(handler http.Handler, verifier *auth.Verifier, publicPaths []string) *AuthMiddleware {
+	return &AuthMiddleware{handler: handler, verifier: verifier, publicPaths: publicPaths}
+}
+
+type AuthMiddleware struct {
+	handler     http.Handler
+	verifier    *auth.Verifier
+	publicPaths []string
+}
+
+func (this *AuthMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if slices.Contains(this.publicPaths, req.URL.Path) || this.verifier.IsTrusted(req.RemoteAddr) {
+		this.handler.ServeHTTP(res, req)
+		return
+	}
+	token, err := this.verifier.Verify(req.Context(), jwt.GetAuthToken(req))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	this.handler.ServeHTTP(res, req.WithContext(auth.WithVerifiedToken(jwt.AddTokenToContext(req.Context(), token), token)))
+}
@@ -16,29 +16,106 @@
 
 package util
 
-import "net/http"
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
 
-func NewCors(handler http.Handler) *CorsMiddleware {
-	return &CorsMiddleware{handler: handler}
+var DefaultCorsMethods = []string{"GET", "POST"}
+var DefaultCorsHeaders = []string{"Origin", "X-Requested-With", "Content-Type", "Accept", "Authorization", "X-On-Behalf-Of"}
+
+// CorsPolicy selects the origins that may call the api from a browser; without AllowedOrigins no cors headers are sent
+type CorsPolicy struct {
+	AllowedOrigins   []string //exact origins or patterns with one "*" like "https://*.example.com"; "*" matches every origin
+	AllowCredentials bool     //in combination with "*" every origin is reflected
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	MaxAge           time.Duration //duration browsers may cache preflight results
+}
+
+func NewCors(handler http.Handler, policy CorsPolicy) *CorsMiddleware {
+	result := &CorsMiddleware{handler: handler}
+	result.SetPolicy(policy)
+	return result
 }
 
 type CorsMiddleware struct {
 	handler http.Handler
+	policy  atomic.Pointer[CorsPolicy]
+}
+
+// SetPolicy replaces the policy; used on config reload
+func (this *CorsMiddleware) SetPolicy(policy CorsPolicy) {
+	if len(policy.AllowedMethods) == 0 {
+		policy.AllowedMethods = DefaultCorsMethods
+	}
+	if len(policy.AllowedHeaders) == 0 {
+		policy.AllowedHeaders = DefaultCorsHeaders
+	}
+	this.policy.Store(&policy)
 }
 
 func (this *CorsMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	policy := this.policy.Load()
 	origin := req.Header.Get("Origin")
-	if origin == "" {
-		origin = "*"
+	if origin != "" && len(policy.AllowedOrigins) > 0 {
+		res.Header().Add("Vary", "Origin")
+		if allowed := policy.allowedOrigin(origin); allowed != "" {
+			res.Header().Set("Access-Control-Allow-Origin", allowed)
+			if policy.AllowCredentials {
+				res.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			if req.Method == http.MethodOptions {
+				res.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+				res.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+				if policy.MaxAge > 0 {
+					res.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+				}
+			}
+		}
 	}
-	res.Header().Set("Access-Control-Allow-Origin", origin)
-	res.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, authorization, Authorization")
-	res.Header().Set("Access-Control-Allow-Credentials", "true")
-	res.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 
-	if req.Method == "OPTIONS" {
+	if req.Method == http.MethodOptions {
 		res.WriteHeader(http.StatusOK)
 	} else {
 		this.handler.ServeHTTP(res, req)
 	}
 }
+
+// allowedOrigin returns the value of Access-Control-Allow-Origin or "" if origin is not allowed
+func (this *CorsPolicy) allowedOrigin(origin string) string {
+	if slices.Contains(this.AllowedOrigins, "*") {
+		if this.AllowCredentials {
+			return origin
+		}
+		return "*"
+	}
+	for _, pattern := range this.AllowedOrigins {
+		if MatchOrigin(pattern, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// MatchOrigin compares origin to an exact origin or a pattern with one "*"; the wildcard may span multiple subdomains or a port
+func MatchOrigin(pattern string, origin string) bool {
+	if pattern == "*" {
+		return true
+	}
+	prefix, suffix, isPattern := strings.Cut(pattern, "*")
+	if !isPattern {
+		return strings.EqualFold(pattern, origin)
+	}
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	wildcard := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(wildcard, "/@")
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		origin   string
+		expected bool
+	}{
+		{pattern: "https://ui.example.com", origin: "https://ui.example.com", expected: true},
+		{pattern: "https://ui.example.com", origin: "https://evil.com", expected: false},
+		{pattern: "https://*.example.com", origin: "https://a.b.example.com", expected: true},
+		{pattern: "https://*.example.com", origin: "https://example.com", expected: false},
+		{pattern: "https://*.example.com", origin: "https://evilexample.com", expected: false},
+		{pattern: "https://*.example.com", origin: "https://evil.com/.example.com", expected: false},
+		{pattern: "http://localhost:*", origin: "http://localhost:8080", expected: true},
+	}
+	for _, test := range tests {
+		if actual := MatchOrigin(test.pattern, test.origin); actual != test.expected {
+			t.Errorf("%v %v: %v != %v", test.pattern, test.origin, actual, test.expected)
+		}
+	}
+}
+
+func TestCors(t *testing.T) {
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {})
+	request := func(policy CorsPolicy, method string, origin string) http.Header {
+		req := httptest.NewRequest(method, "/selectables", nil)
+		req.Header.Set("Origin", origin)
+		res := httptest.NewRecorder()
+		NewCors(handler, policy).ServeHTTP(res, req)
+		return res.Header()
+	}
+
+	header := request(CorsPolicy{}, http.MethodGet, "https://evil.com")
+	if header.Get("Access-Control-Allow-Origin") != "" {
+		t.Error("cors must be disabled by default", header)
+	}
+
+	policy := CorsPolicy{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true}
+	header = request(policy, http.MethodGet, "https://evil.com")
+	if header.Get("Access-Control-Allow-Origin") != "" || header.Get("Access-Control-Allow-Credentials") != "" {
+		t.Error(header)
+	}
+	header = request(policy, http.MethodOptions, "https://ui.example.com")
+	if header.Get("Access-Control-Allow-Origin") != "https://ui.example.com" || header.Get("Access-Control-Allow-Credentials") != "true" || header.Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Error(header)
+	}
+
+	header = request(CorsPolicy{AllowedOrigins: []string{"*"}}, http.MethodGet, "https://evil.com")
+	if header.Get("Access-Control-Allow-Origin") != "*" || header.Get("Access-Control-Allow-Credentials") != "" {
+		t.Error(header)
+	}
+	header = request(CorsPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}, http.MethodGet, "https://evil.com")
+	if header.Get("Access-Control-Allow-Origin") != "https://evil.com" || header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Error(header)
+	}
+}
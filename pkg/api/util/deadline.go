@@ -0,0 +1,40 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// NewDeadline cancels the request context after timeout, so that upstream calls stop
+// once the server is no longer able to write the response
+func NewDeadline(handler http.Handler, timeout time.Duration) *DeadlineMiddleware {
+	return &DeadlineMiddleware{handler: handler, timeout: timeout}
+}
+
+type DeadlineMiddleware struct {
+	handler http.Handler
+	timeout time.Duration
+}
+
+func (this *DeadlineMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), this.timeout)
+	defer cancel()
+	this.handler.ServeHTTP(res, req.WithContext(ctx))
+}
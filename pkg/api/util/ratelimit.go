@@ -0,0 +1,84 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
+	"github.com/SENERGY-Platform/device-selection/pkg/limits"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// NewRateLimit answers requests of users above their rate limit with 429 and adds the request budget of the user to the context;
+// requests to publicPaths are not limited. the roles of unverified tokens could be chosen by the caller,
+// so only tokens verified by AuthMiddleware get the limits of their roles; all other requests get the limits of limits.DefaultRole.
+func NewRateLimit(handler http.Handler, policy *limits.Policy, publicPaths []string) *RateLimitMiddleware {
+	return &RateLimitMiddleware{handler: handler, policy: policy, limiter: limits.NewRateLimiter(), publicPaths: publicPaths}
+}
+
+type RateLimitMiddleware struct {
+	handler     http.Handler
+	policy      *limits.Policy
+	limiter     *limits.RateLimiter
+	publicPaths []string
+}
+
+func (this *RateLimitMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if slices.Contains(this.publicPaths, req.URL.Path) || !this.policy.Enabled() {
+		this.handler.ServeHTTP(res, req)
+		return
+	}
+	token, ok := jwt.GetTokenFromContext(req.Context())
+	if !ok {
+		token, _ = jwt.Parse(jwt.GetAuthToken(req))
+	}
+	user := token.GetUserId()
+	if user == "" {
+		user, _, _ = net.SplitHostPort(req.RemoteAddr)
+	}
+	roles := []string{}
+	if verified, ok := auth.VerifiedTokenFromContext(req.Context()); ok {
+		roles = verified.GetRoles()
+	}
+	userLimits := this.policy.ForRoles(roles)
+	allowed, retryAfter := this.limiter.Allow(user, userLimits)
+	if !allowed {
+		res.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		http.Error(res, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	budget := limits.NewBudget(userLimits)
+	this.handler.ServeHTTP(&budgetResponseWriter{ResponseWriter: res, budget: budget}, req.WithContext(limits.WithBudget(req.Context(), budget)))
+}
+
+// budgetResponseWriter reports exceeded budgets as 413, even if the controller passes the upstream error on as 500
+type budgetResponseWriter struct {
+	http.ResponseWriter
+	budget *limits.Budget
+}
+
+func (this *budgetResponseWriter) WriteHeader(status int) {
+	if status >= 500 && this.budget.Exceeded() {
+		status = limits.StatusCode
+	}
+	this.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/limits"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func TestRateLimitRolesNeedVerification(t *testing.T) {
+	policy, err := limits.New(&configuration.ConfigStruct{MaxBulkElements: map[string]int64{limits.DefaultRole: 10, "admin": 500}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var maxBulkElements int
+	handler := NewRateLimit(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		maxBulkElements = 0
+		for limits.FromContext(request.Context()).CheckBulkSize(maxBulkElements+1) == nil {
+			maxBulkElements++
+		}
+	}), policy, nil)
+	//unsigned token with the admin role
+	raw := "Bearer " + base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"u1","realm_access":{"roles":["admin"]}}`)) + "."
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/selectables", nil)
+	req.Header.Set("Authorization", raw)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if maxBulkElements != 10 {
+		t.Error("unverified tokens should get the default limits", maxBulkElements)
+	}
+
+	token, err := jwt.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/v2/selectables", nil)
+	req = req.WithContext(auth.WithVerifiedToken(jwt.AddTokenToContext(req.Context(), token), token))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if maxBulkElements != 500 {
+		t.Error("verified tokens should get the limits of their roles", maxBulkElements)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NewSecurityHeaders prevents browsers from rendering, framing or caching api responses; hstsMaxAge > 0 adds Strict-Transport-Security
+func NewSecurityHeaders(handler http.Handler, hstsMaxAge time.Duration) *SecurityHeadersMiddleware {
+	return &SecurityHeadersMiddleware{handler: handler, hstsMaxAge: hstsMaxAge}
+}
+
+type SecurityHeadersMiddleware struct {
+	handler    http.Handler
+	hstsMaxAge time.Duration
+}
+
+func (this *SecurityHeadersMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	header := res.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("Cache-Control", "no-store")
+	if this.hstsMaxAge > 0 {
+		header.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(int(this.hstsMaxAge.Seconds())))
+	}
+	this.handler.ServeHTTP(res, req)
+}
@@ -0,0 +1,326 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	SinkNone   = "none"
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkKafka  = "kafka"
+)
+
+// fields that may be listed in audit_redact
+const (
+	RedactUserId    = "user_id"    //user_id and on_behalf_of are replaced by a sha256 hash
+	RedactOptions   = "options"    //options are removed
+	RedactDeviceIds = "device_ids" //device ids are removed, the result count is kept
+)
+
+const bufferSize = 1000
+
+type Record struct {
+	Time         time.Time         `json:"time"`
+	TraceId      string            `json:"trace_id,omitempty"`
+	UserId       string            `json:"user_id"`
+	OnBehalfOf   string            `json:"on_behalf_of,omitempty"`
+	Method       string            `json:"method"`
+	Endpoint     string            `json:"endpoint"`
+	CriteriaHash string            `json:"criteria_hash,omitempty"`
+	Options      map[string]string `json:"options,omitempty"`
+	ResultCount  int               `json:"result_count"`
+	DeviceIds    []string          `json:"device_ids,omitempty"`
+	DurationMs   int64             `json:"duration_ms"`
+	Status       int               `json:"status"`
+	Outcome      string            `json:"outcome"` //"success" or "error"
+}
+
+type Sink interface {
+	Write(record Record) error
+	Close() error
+}
+
+// Logger writes records asynchronously to a sink, so that a slow sink does not delay responses.
+// records are dropped (and counted in a warning) if the buffer is full.
+type Logger struct {
+	sink        Sink
+	sampleRatio float64
+	redact      []string
+	records     chan Record
+	done        chan struct{}
+	dropped     int64
+	closed      bool
+	mux         sync.Mutex
+}
+
+// New returns nil if config.AuditSink is empty or "none"; the sink is closed when ctx is done
+func New(ctx context.Context, config configuration.Config) (*Logger, error) {
+	var sink Sink
+	var err error
+	switch config.AuditSink {
+	case "", SinkNone:
+		return nil, nil
+	case SinkStdout:
+		sink = NewStdoutSink()
+	case SinkFile:
+		sink, err = NewFileSink(config.AuditFile)
+	case SinkKafka:
+		sink, err = NewKafkaSink(config)
+	default:
+		return nil, fmt.Errorf("unknown audit_sink %q (expected none, stdout, file or kafka)", config.AuditSink)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range config.AuditRedact {
+		if !slices.Contains([]string{RedactUserId, RedactOptions, RedactDeviceIds}, field) {
+			return nil, fmt.Errorf("unknown audit_redact field %q", field)
+		}
+	}
+	sampleRatio := config.AuditSampleRatio
+	if sampleRatio <= 0 || sampleRatio > 1 {
+		sampleRatio = 1
+	}
+	result := NewLogger(sink, sampleRatio, config.AuditRedact)
+	go func() {
+		<-ctx.Done()
+		result.Close()
+	}()
+	return result, nil
+}
+
+func NewLogger(sink Sink, sampleRatio float64, redact []string) *Logger {
+	result := &Logger{
+		sink:        sink,
+		sampleRatio: sampleRatio,
+		redact:      redact,
+		records:     make(chan Record, bufferSize),
+		done:        make(chan struct{}),
+	}
+	go result.run()
+	return result
+}
+
+func (this *Logger) run() {
+	defer close(this.done)
+	for record := range this.records {
+		err := this.sink.Write(record)
+		if err != nil {
+			slog.Error("unable to write audit record", "error", err)
+		}
+	}
+}
+
+// Close writes all buffered records and closes the sink
+func (this *Logger) Close() {
+	this.mux.Lock()
+	if this.closed {
+		this.mux.Unlock()
+		return
+	}
+	this.closed = true
+	close(this.records)
+	this.mux.Unlock()
+	<-this.done
+	err := this.sink.Close()
+	if err != nil {
+		slog.Error("unable to close audit sink", "error", err)
+	}
+}
+
+func (this *Logger) Log(record Record) {
+	if this.sampleRatio < 1 && rand.Float64() >= this.sampleRatio {
+		return
+	}
+	record = this.applyRedaction(record)
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.closed {
+		return
+	}
+	select {
+	case this.records <- record:
+	default:
+		this.dropped++
+		slog.Warn("audit buffer full, record dropped", "dropped", this.dropped)
+	}
+}
+
+func (this *Logger) applyRedaction(record Record) Record {
+	if slices.Contains(this.redact, RedactUserId) {
+		record.UserId = pseudonym(record.UserId)
+		record.OnBehalfOf = pseudonym(record.OnBehalfOf)
+	}
+	if slices.Contains(this.redact, RedactOptions) {
+		record.Options = nil
+	}
+	if slices.Contains(this.redact, RedactDeviceIds) {
+		record.DeviceIds = nil
+	}
+	return record
+}
+
+func pseudonym(value string) string {
+	if value == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+type entryContextKey struct{}
+
+// Entry collects the parts of a record that are only known to the endpoint handler
+type Entry struct {
+	mux          sync.Mutex
+	onBehalfOf   string
+	criteriaHash string
+	resultCount  int
+	deviceIds    []string
+}
+
+// FromContext returns the entry of the current request or nil if the request is not audited.
+// all methods of Entry may be called on nil.
+func FromContext(ctx context.Context) *Entry {
+	entry, _ := ctx.Value(entryContextKey{}).(*Entry)
+	return entry
+}
+
+// NewEntryContext adds a new entry to ctx, which may be filled by endpoint handlers
+func NewEntryContext(ctx context.Context) (context.Context, *Entry) {
+	entry := &Entry{}
+	return context.WithValue(ctx, entryContextKey{}, entry), entry
+}
+
+// query parameters that contain criteria; they are part of the criteria hash and not repeated as options
+var criteriaQueryParameters = []string{"json", "base64", "function_id", "aspect_id", "device_class_id", "interaction"}
+
+// Record combines the entry with the request and its outcome
+func (this *Entry) Record(request *http.Request, status int, start time.Time) Record {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result := Record{
+		Time:         start,
+		OnBehalfOf:   this.onBehalfOf,
+		Method:       request.Method,
+		Endpoint:     request.URL.Path,
+		CriteriaHash: this.criteriaHash,
+		ResultCount:  this.resultCount,
+		DeviceIds:    this.deviceIds,
+		DurationMs:   time.Since(start).Milliseconds(),
+		Status:       status,
+		Outcome:      "success",
+	}
+	if status >= 400 {
+		result.Outcome = "error"
+	}
+	if spanContext := trace.SpanContextFromContext(request.Context()); spanContext.HasTraceID() {
+		result.TraceId = spanContext.TraceID().String()
+	}
+	token, ok := jwt.GetTokenFromContext(request.Context())
+	if !ok {
+		token, _ = jwt.Parse(jwt.GetAuthToken(request))
+	}
+	result.UserId = token.GetUserId()
+	for key, values := range request.URL.Query() {
+		if slices.Contains(criteriaQueryParameters, key) || len(values) == 0 {
+			continue
+		}
+		if result.Options == nil {
+			result.Options = map[string]string{}
+		}
+		result.Options[key] = strings.Join(values, ",")
+	}
+	return result
+}
+
+func (this *Entry) SetOnBehalfOf(userId string) {
+	if this == nil {
+		return
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.onBehalfOf = userId
+}
+
+func (this *Entry) SetCriteria(criteria interface{}) {
+	if this == nil {
+		return
+	}
+	hash, err := CriteriaHash(criteria)
+	if err != nil {
+		slog.Warn("unable to hash criteria for audit log", "error", err)
+		return
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.criteriaHash = hash
+}
+
+// AddSelectables may be called multiple times, e.g. for every element of a bulk result
+func (this *Entry) AddSelectables(selectables []model.Selectable) {
+	if this == nil {
+		return
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.resultCount += len(selectables)
+	for _, selectable := range selectables {
+		if selectable.Device != nil {
+			this.deviceIds = append(this.deviceIds, selectable.Device.Id)
+		}
+	}
+}
+
+func (this *Entry) AddBulkResult(result model.BulkResult) {
+	if this == nil {
+		return
+	}
+	for _, element := range result {
+		this.AddSelectables(element.Selectables)
+	}
+}
+
+func (this *Entry) AddDevices(devices []model.PermSearchDevice) {
+	if this == nil {
+		return
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.resultCount += len(devices)
+	for _, device := range devices {
+		this.deviceIds = append(this.deviceIds, device.Id)
+	}
+}
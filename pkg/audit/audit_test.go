@@ -0,0 +1,97 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+func TestCriteriaHash(t *testing.T) {
+	a, err := CriteriaHash(model.FilterCriteriaAndSet{
+		{FunctionId: "f1", AspectId: "a1"},
+		{FunctionId: "f2", DeviceClassId: "dc1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CriteriaHash([]map[string]interface{}{
+		{"device_class_id": "dc1", "function_id": "f2", "aspect_id": ""},
+		{"aspect_id": "a1", "function_id": "f1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("equivalent criteria should have the same hash")
+	}
+	c, err := CriteriaHash(model.FilterCriteriaAndSet{{FunctionId: "f1", AspectId: "a2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == c {
+		t.Error("different criteria should have different hashes")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(sink, 1, []string{RedactUserId, RedactDeviceIds})
+
+	entry := &Entry{}
+	entry.AddSelectables([]model.Selectable{
+		{Device: &model.PermSearchDevice{Device: devicemodel.Device{Id: "d1"}}},
+		{DeviceGroup: &model.DeviceGroup{Id: "g1"}},
+	})
+	var nilEntry *Entry
+	nilEntry.AddSelectables([]model.Selectable{{}}) //must not panic
+
+	logger.Log(Record{UserId: "user1", Endpoint: "/v2/bulk/selectables", ResultCount: entry.resultCount, DeviceIds: entry.deviceIds, Options: map[string]string{"include_groups": "true"}})
+	logger.Close()
+
+	file, err := os.Open(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records := []Record{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := Record{}
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 1 {
+		t.Fatal(records)
+	}
+	if records[0].UserId != pseudonym("user1") || records[0].DeviceIds != nil || records[0].ResultCount != 2 || records[0].Options["include_groups"] != "true" {
+		t.Errorf("%#v", records[0])
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// CriteriaHash returns a sha256 hash of criteria that does not depend on the order of list elements or map keys
+// and ignores empty values, so that equivalent queries result in the same hash
+func CriteriaHash(criteria interface{}) (string, error) {
+	temp, err := json.Marshal(criteria)
+	if err != nil {
+		return "", err
+	}
+	var generic interface{}
+	err = json.Unmarshal(temp, &generic)
+	if err != nil {
+		return "", err
+	}
+	normalized, err := json.Marshal(normalize(generic))
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(normalized)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, element := range v {
+			element = normalize(element)
+			if !isEmpty(element) {
+				result[key] = element
+			}
+		}
+		return result
+	case []interface{}:
+		elements := []string{}
+		for _, element := range v {
+			temp, _ := json.Marshal(normalize(element))
+			elements = append(elements, string(temp))
+		}
+		sort.Strings(elements)
+		result := []interface{}{}
+		for _, element := range elements {
+			result = append(result, json.RawMessage(element))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
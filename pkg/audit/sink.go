@@ -0,0 +1,98 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/cacheinvalidator/kafka"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// StreamSink writes one json encoded record per line
+type StreamSink struct {
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+func NewStdoutSink() *StreamSink {
+	return &StreamSink{encoder: json.NewEncoder(os.Stdout)}
+}
+
+func NewFileSink(location string) (*StreamSink, error) {
+	if location == "" {
+		return nil, errors.New("audit_sink=file requires audit_file")
+	}
+	file, err := os.OpenFile(location, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamSink{encoder: json.NewEncoder(file), closer: file}, nil
+}
+
+func (this *StreamSink) Write(record Record) error {
+	return this.encoder.Encode(record)
+}
+
+func (this *StreamSink) Close() error {
+	if this.closer == nil {
+		return nil
+	}
+	return this.closer.Close()
+}
+
+// KafkaSink publishes records to config.AuditKafkaTopic, using the user id as message key.
+// the topic is not created by kafka.InitTopic, because its compaction would drop records.
+type KafkaSink struct {
+	writer *kafkago.Writer
+}
+
+func NewKafkaSink(config configuration.Config) (*KafkaSink, error) {
+	if config.KafkaUrl == "" || config.AuditKafkaTopic == "" {
+		return nil, errors.New("audit_sink=kafka requires kafka_url and audit_kafka_topic")
+	}
+	broker, err := kafka.GetBroker(config.KafkaUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaSink{writer: &kafkago.Writer{
+		Addr:         kafkago.TCP(broker...),
+		Topic:        config.AuditKafkaTopic,
+		Balancer:     &kafkago.Hash{},
+		BatchTimeout: 100 * time.Millisecond,
+	}}, nil
+}
+
+func (this *KafkaSink) Write(record Record) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return this.writer.WriteMessages(ctx, kafkago.Message{Key: []byte(record.UserId), Value: value, Time: record.Time})
+}
+
+func (this *KafkaSink) Close() error {
+	return this.writer.Close()
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultExchangeTimeout limits a single token-exchange request to keycloak
+const DefaultExchangeTimeout = 10 * time.Second
+
+// TokenExchange requests user tokens from keycloak with the token-exchange grant of a confidential client
+// and reuses them until they expire.
+// concurrent requests for the same user share one exchange; exchanges for different users run in parallel.
+type TokenExchange struct {
+	endpoint     string
+	clientId     string
+	clientSecret string
+	client       *http.Client
+	timeout      time.Duration
+	mux          sync.Mutex
+	tokens       map[string]exchangedToken
+	exchanges    singleflight.Group
+}
+
+type exchangedToken struct {
+	token   jwt.Token
+	expires time.Time
+}
+
+func NewTokenExchange(endpoint string, clientId string, clientSecret string) *TokenExchange {
+	return &TokenExchange{
+		endpoint:     endpoint,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		client:       http.DefaultClient,
+		timeout:      DefaultExchangeTimeout,
+		tokens:       map[string]exchangedToken{},
+	}
+}
+
+// GetUserToken returns a cached token of userId or exchanges a new one.
+// the exchange is not bound to ctx, because other requests may wait for the same exchange; ctx only ends the wait of this caller.
+func (this *TokenExchange) GetUserToken(ctx context.Context, userId string) (token jwt.Token, err error) {
+	if cached, ok := this.getCached(userId); ok {
+		return cached, nil
+	}
+	exchange := this.exchanges.DoChan(userId, func() (interface{}, error) {
+		if cached, ok := this.getCached(userId); ok {
+			return cached, nil
+		}
+		return this.exchange(userId)
+	})
+	select {
+	case <-ctx.Done():
+		return token, ctx.Err()
+	case result := <-exchange:
+		if result.Err != nil {
+			return token, result.Err
+		}
+		return result.Val.(jwt.Token), nil
+	}
+}
+
+func (this *TokenExchange) getCached(userId string) (token jwt.Token, ok bool) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	now := time.Now()
+	for id, cached := range this.tokens {
+		if !now.Before(cached.expires) {
+			delete(this.tokens, id)
+		}
+	}
+	cached, ok := this.tokens[userId]
+	return cached.token, ok
+}
+
+func (this *TokenExchange) setCached(userId string, token jwt.Token, expires time.Time) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.tokens[userId] = exchangedToken{token: token, expires: expires}
+}
+
+// exchange works like jwt.ExchangeUserToken, but limits the request to this.timeout and caches the result
+func (this *TokenExchange) exchange(userId string) (token jwt.Token, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), this.timeout)
+	defer cancel()
+	requested := time.Now()
+	form := url.Values{
+		"client_id":         {this.clientId},
+		"client_secret":     {this.clientSecret},
+		"grant_type":        {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"requested_subject": {userId},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, this.endpoint+"/auth/realms/master/protocol/openid-connect/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return token, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := this.client.Do(req)
+	if err != nil {
+		return token, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return token, fmt.Errorf("token exchange denied with status %v", resp.StatusCode)
+	}
+	var openIdToken jwt.OpenidToken
+	err = json.NewDecoder(resp.Body).Decode(&openIdToken)
+	if err != nil {
+		return token, err
+	}
+	token, err = jwt.Parse("Bearer " + openIdToken.AccessToken)
+	if err != nil {
+		return token, err
+	}
+	//subtract 5 seconds from expiration as a buffer (as jwt.ExchangeUserToken)
+	this.setCached(userId, token, requested.Add(time.Duration(openIdToken.ExpiresIn-5)*time.Second))
+	return token, nil
+}
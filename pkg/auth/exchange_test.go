@@ -0,0 +1,121 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt"
+)
+
+func TestTokenExchange(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		if request.URL.Path != "/auth/realms/master/protocol/openid-connect/token" {
+			http.Error(writer, "unexpected path", http.StatusNotFound)
+			return
+		}
+		if request.FormValue("client_id") != "device-selection" || request.FormValue("client_secret") != "secret" {
+			http.Error(writer, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		token, _ := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": request.FormValue("requested_subject")}).SignedString([]byte("test"))
+		json.NewEncoder(writer).Encode(map[string]interface{}{"access_token": token, "expires_in": 300})
+	}))
+	defer server.Close()
+
+	exchange := NewTokenExchange(server.URL, "device-selection", "secret")
+	for i := 0; i < 2; i++ {
+		token, err := exchange.GetUserToken(context.Background(), "user1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.GetUserId() != "user1" {
+			t.Error(token.GetUserId())
+		}
+	}
+	token, err := exchange.GetUserToken(context.Background(), "user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.GetUserId() != "user2" {
+		t.Error(token.GetUserId())
+	}
+	if requests != 2 {
+		t.Error("expected cached token for user1", requests)
+	}
+
+	_, err = NewTokenExchange(server.URL, "device-selection", "wrong").GetUserToken(context.Background(), "user1")
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestTokenExchangeConcurrency(t *testing.T) {
+	requests := map[string]*atomic.Int64{"user1": {}, "user2": {}, "user3": {}}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		user := request.FormValue("requested_subject")
+		requests[user].Add(1)
+		time.Sleep(200 * time.Millisecond)
+		token, _ := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": user}).SignedString([]byte("test"))
+		json.NewEncoder(writer).Encode(map[string]interface{}{"access_token": token, "expires_in": 300})
+	}))
+	defer server.Close()
+
+	exchange := NewTokenExchange(server.URL, "device-selection", "secret")
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		for _, user := range []string{"user1", "user2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := exchange.GetUserToken(context.Background(), user)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if token.GetUserId() != user {
+					t.Error(token.GetUserId(), user)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	if requests["user1"].Load() != 1 || requests["user2"].Load() != 1 {
+		t.Error("expected one exchange per user", requests["user1"].Load(), requests["user2"].Load())
+	}
+	if time.Since(start) > 350*time.Millisecond {
+		t.Error("exchanges of different users should not wait for each other", time.Since(start))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := exchange.GetUserToken(ctx, "user3")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("caller should stop waiting when its context is done", err)
+	}
+}
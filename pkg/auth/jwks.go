@@ -0,0 +1,206 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minimal duration between two jwks downloads triggered by unknown key ids
+const jwksRefreshCooldown = time.Minute
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string   `json:"kid"`
+	Kty string   `json:"kty"`
+	Use string   `json:"use"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	Crv string   `json:"crv"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5C []string `json:"x5c"`
+}
+
+// KeySet holds the public keys of a jwks, loaded from a url or a local file.
+// keys from a url are reloaded when a token references an unknown key id.
+type KeySet struct {
+	location   string
+	httpClient *http.Client
+	mux        sync.Mutex
+	keys       map[string]interface{}
+	lastLoad   time.Time
+}
+
+func NewKeySet(location string) *KeySet {
+	return &KeySet{location: location, httpClient: &http.Client{Timeout: 10 * time.Second}, keys: map[string]interface{}{}}
+}
+
+func (this *KeySet) isUrl() bool {
+	return strings.HasPrefix(this.location, "http://") || strings.HasPrefix(this.location, "https://")
+}
+
+// Load reads the jwks; errors of remote key sets may be ignored because they are reloaded on demand
+func (this *KeySet) Load(ctx context.Context) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	return this.load(ctx)
+}
+
+func (this *KeySet) load(ctx context.Context) (err error) {
+	this.lastLoad = time.Now()
+	var content []byte
+	if this.isUrl() {
+		content, err = this.download(ctx)
+	} else {
+		content, err = os.ReadFile(strings.TrimPrefix(this.location, "file://"))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to load jwks from %v: %w", this.location, err)
+	}
+	set := jwks{}
+	err = json.Unmarshal(content, &set)
+	if err != nil {
+		return fmt.Errorf("unable to parse jwks from %v: %w", this.location, err)
+	}
+	keys := map[string]interface{}{}
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return fmt.Errorf("unable to parse jwk %v from %v: %w", key.Kid, this.location, err)
+		}
+		keys[key.Kid] = publicKey
+	}
+	this.keys = keys
+	return nil
+}
+
+func (this *KeySet) download(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, this.location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := this.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Get returns the public key with the given key id
+func (this *KeySet) Get(ctx context.Context, kid string) (interface{}, error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if key, ok := this.keys[kid]; ok {
+		return key, nil
+	}
+	if this.isUrl() && time.Since(this.lastLoad) > jwksRefreshCooldown {
+		err := this.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := this.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (this jwk) publicKey() (interface{}, error) {
+	if len(this.X5C) > 0 && this.N == "" && this.X == "" {
+		der, err := base64.StdEncoding.DecodeString(this.X5C[0])
+		if err != nil {
+			return nil, err
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	switch this.Kty {
+	case "RSA":
+		n, err := decodeBigInt(this.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(this.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch this.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", this.Crv)
+		}
+		x, err := decodeBigInt(this.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(this.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", this.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	temp, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(temp), nil
+}
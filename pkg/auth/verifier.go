@@ -0,0 +1,210 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	gojwt "github.com/golang-jwt/jwt"
+)
+
+const (
+	VerificationNone = "none"
+	VerificationJwks = "jwks"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// asymmetric algorithms only; a jwks never contains the secret of a hmac signature
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Verifier checks signature, expiry, issuer and audience of tokens
+type Verifier struct {
+	keys            *KeySet
+	issuers         []string
+	audiences       []string
+	clockSkew       time.Duration
+	trustedNetworks []*net.IPNet
+}
+
+// New returns nil if config.AuthVerification is empty or "none"
+func New(ctx context.Context, config configuration.Config) (*Verifier, error) {
+	switch config.AuthVerification {
+	case "", VerificationNone:
+		return nil, nil
+	case VerificationJwks:
+	default:
+		return nil, fmt.Errorf("unknown auth_verification %q (expected none or jwks)", config.AuthVerification)
+	}
+	if config.AuthJwksUrl == "" {
+		return nil, errors.New("auth_verification=jwks requires auth_jwks_url")
+	}
+	result := &Verifier{
+		keys:      NewKeySet(config.AuthJwksUrl),
+		issuers:   config.AuthIssuers,
+		audiences: config.AuthAudiences,
+	}
+	var err error
+	if config.AuthClockSkew != "" {
+		result.clockSkew, err = time.ParseDuration(config.AuthClockSkew)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth_clock_skew: %w", err)
+		}
+	}
+	for _, cidr := range config.AuthTrustedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth_trusted_networks entry %q: %w", cidr, err)
+		}
+		result.trustedNetworks = append(result.trustedNetworks, network)
+	}
+	err = result.keys.Load(ctx)
+	if err != nil {
+		if !result.keys.isUrl() {
+			return nil, err
+		}
+		//the identity provider may start after this service; keys are loaded again on the first request
+		config.GetLogger().Warn("unable to load jwks, retry on first request", "error", err)
+	}
+	return result, nil
+}
+
+// Verify checks the token (with or without "Bearer " prefix) and returns its parsed claims
+func (this *Verifier) Verify(ctx context.Context, token string) (result jwt.Token, err error) {
+	if token == "" {
+		return result, jwt.ErrMissingAuthToken
+	}
+	raw := token
+	if len(raw) > 7 && strings.ToLower(raw[:7]) == "bearer " {
+		raw = raw[7:]
+	}
+	parser := gojwt.Parser{ValidMethods: validMethods, SkipClaimsValidation: true}
+	claims := gojwt.MapClaims{}
+	_, err = parser.ParseWithClaims(raw, claims, func(t *gojwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return this.keys.Get(ctx, kid)
+	})
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	err = this.validateClaims(claims, time.Now())
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return jwt.Parse(token)
+}
+
+type verifiedTokenKey struct{}
+
+// WithVerifiedToken marks token as verified; other middlewares may add unverified tokens to the context with jwt.AddTokenToContext
+func WithVerifiedToken(ctx context.Context, token jwt.Token) context.Context {
+	return context.WithValue(ctx, verifiedTokenKey{}, token)
+}
+
+// VerifiedTokenFromContext returns the token added with WithVerifiedToken; ok is false if tokens are not verified or the request is from a trusted network
+func VerifiedTokenFromContext(ctx context.Context) (token jwt.Token, ok bool) {
+	token, ok = ctx.Value(verifiedTokenKey{}).(jwt.Token)
+	return token, ok
+}
+
+func (this *Verifier) validateClaims(claims gojwt.MapClaims, now time.Time) error {
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	if now.After(exp.Add(this.clockSkew)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(this.clockSkew).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+	if len(this.issuers) > 0 {
+		iss, _ := claims["iss"].(string)
+		if !slices.Contains(this.issuers, iss) {
+			return fmt.Errorf("issuer %q is not accepted", iss)
+		}
+	}
+	if len(this.audiences) > 0 {
+		found := false
+		for _, aud := range audiences(claims["aud"]) {
+			if slices.Contains(this.audiences, aud) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("audience is not accepted")
+		}
+	}
+	return nil
+}
+
+// IsTrusted reports if the caller (http.Request.RemoteAddr) belongs to auth_trusted_networks.
+// trusted callers may use unverifiable service tokens like client.InternalAdminToken.
+func (this *Verifier) IsTrusted(remoteAddr string) bool {
+	if len(this.trustedNetworks) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range this.trustedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func numericDate(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// the aud claim may be a single string or a list
+func audiences(value interface{}) (result []string) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	gojwt "github.com/golang-jwt/jwt"
+)
+
+func TestVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := json.Marshal(jwks{Keys: []jwk{{
+		Kid: "test-key",
+		Kty: "RSA",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(claims gojwt.MapClaims) string {
+		token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test-key"
+		result, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + result
+	}
+	validClaims := func() gojwt.MapClaims {
+		return gojwt.MapClaims{
+			"sub": "user",
+			"iss": "https://auth.example.com/realms/master",
+			"aud": []string{"device-selection", "account"},
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(file, set, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write(set)
+	}))
+	defer server.Close()
+
+	for name, location := range map[string]string{"file": file, "url": server.URL} {
+		t.Run(name, func(t *testing.T) {
+			verifier, err := New(context.Background(), &configuration.ConfigStruct{
+				AuthVerification:    VerificationJwks,
+				AuthJwksUrl:         location,
+				AuthIssuers:         []string{"https://auth.example.com/realms/master"},
+				AuthAudiences:       []string{"device-selection"},
+				AuthClockSkew:       "5s",
+				AuthTrustedNetworks: []string{"10.0.0.0/8"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := verifier.Verify(context.Background(), sign(validClaims()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.GetUserId() != "user" {
+				t.Error(token.GetUserId())
+			}
+
+			invalid := map[string]string{}
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			invalid["expired"] = sign(claims)
+			claims = validClaims()
+			delete(claims, "exp")
+			invalid["missing exp"] = sign(claims)
+			claims = validClaims()
+			claims["iss"] = "https://evil.example.com"
+			invalid["issuer"] = sign(claims)
+			claims = validClaims()
+			claims["aud"] = "account"
+			invalid["audience"] = sign(claims)
+			hmac, _ := gojwt.NewWithClaims(gojwt.SigningMethodHS256, validClaims()).SignedString([]byte("secret"))
+			invalid["hmac"] = "Bearer " + hmac
+			other, _ := rsa.GenerateKey(rand.Reader, 2048)
+			forged := gojwt.NewWithClaims(gojwt.SigningMethodRS256, validClaims())
+			forged.Header["kid"] = "test-key"
+			forgedStr, _ := forged.SignedString(other)
+			invalid["signature"] = "Bearer " + forgedStr
+
+			for name, token := range invalid {
+				_, err = verifier.Verify(context.Background(), token)
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Error(name, err)
+				}
+			}
+
+			if !verifier.IsTrusted("10.1.2.3:1234") || verifier.IsTrusted("192.168.1.2:1234") {
+				t.Error("unexpected IsTrusted() result")
+			}
+		})
+	}
+}
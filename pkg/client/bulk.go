@@ -0,0 +1,58 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+)
+
+type BulkOptions struct {
+	CompleteServices bool //adds full import-types and import path options to the result
+}
+
+func (c *ClientImpl) BulkSelectablesV2(ctx context.Context, token string, request model.BulkRequestV2, options *BulkOptions) (model.BulkResult, int, error) {
+	return do[model.BulkResult](ctx, c, http.MethodPost, "/v2/bulk/selectables", bulkQuery(options), token, request)
+}
+
+// BulkGroupedSelectablesV2 responds with the compact form of BulkSelectablesV2; use model.GroupedBulkResult.Expand to get the BulkResult
+func (c *ClientImpl) BulkGroupedSelectablesV2(ctx context.Context, token string, request model.BulkRequestV2, options *BulkOptions) (model.GroupedBulkResult, int, error) {
+	query := bulkQuery(options)
+	query.Set("format", "grouped")
+	return do[model.GroupedBulkResult](ctx, c, http.MethodPost, "/v2/bulk/selectables", query, token, request)
+}
+
+// BulkSelectables uses the deprecated POST /bulk/selectables endpoint
+func (c *ClientImpl) BulkSelectables(ctx context.Context, token string, request model.BulkRequest, options *BulkOptions) (model.BulkResult, int, error) {
+	return do[model.BulkResult](ctx, c, http.MethodPost, "/bulk/selectables", bulkQuery(options), token, request)
+}
+
+// BulkSelectablesCombinedDevices returns the devices matching any element of request; IncludeGroups and IncludeImports must be false
+func (c *ClientImpl) BulkSelectablesCombinedDevices(ctx context.Context, token string, request model.BulkRequest) ([]model.PermSearchDevice, int, error) {
+	return do[[]model.PermSearchDevice](ctx, c, http.MethodPost, "/bulk/selectables/combined/devices", nil, token, request)
+}
+
+func bulkQuery(options *BulkOptions) url.Values {
+	query := url.Values{}
+	if options != nil && options.CompleteServices {
+		query.Set("complete_services", "true")
+	}
+	return query
+}
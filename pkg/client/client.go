@@ -17,47 +17,170 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/models/go/models"
 )
 
-// InternalAdminToken is expired and invalid. but because this service does not validate the received tokens,
+// InternalAdminToken is expired and invalid. but because this service does not validate the received tokens by default,
 // it may be used by trusted internal services which are within the same network (kubernetes cluster).
-// requests with this token may not be routed over an ingres with token validation
+// requests with this token may not be routed over an ingres with token validation.
+// if auth_verification is enabled, the caller must be part of auth_trusted_networks
 const InternalAdminToken = `Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJleHAiOjEwMDAwMDAwMDAsImlhdCI6MTAwMDAwMDAwMCwiYXV0aF90aW1lIjoxMDAwMDAwMDAwLCJpc3MiOiJpbnRlcm5hbCIsImF1ZCI6W10sInN1YiI6ImRkNjllYTBkLWY1NTMtNDMzNi04MGYzLTdmNDU2N2Y4NWM3YiIsInR5cCI6IkJlYXJlciIsImF6cCI6ImZyb250ZW5kIiwicmVhbG1fYWNjZXNzIjp7InJvbGVzIjpbImFkbWluIiwiZGV2ZWxvcGVyIiwidXNlciJdfSwicmVzb3VyY2VfYWNjZXNzIjp7Im1hc3Rlci1yZWFsbSI6eyJyb2xlcyI6W119LCJCYWNrZW5kLXJlYWxtIjp7InJvbGVzIjpbXX0sImFjY291bnQiOnsicm9sZXMiOltdfX0sInJvbGVzIjpbImFkbWluIiwiZGV2ZWxvcGVyIiwidXNlciJdLCJuYW1lIjoiU2VwbCBBZG1pbiIsInByZWZlcnJlZF91c2VybmFtZSI6InNlcGwiLCJnaXZlbl9uYW1lIjoiU2VwbCIsImxvY2FsZSI6ImVuIiwiZmFtaWx5X25hbWUiOiJBZG1pbiIsImVtYWlsIjoic2VwbEBzZXBsLmRlIn0.HZyG6n-BfpnaPAmcDoSEh0SadxUx-w4sEt2RVlQ9e5I`
 
 type Client interface {
 	GetSelectables(token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) ([]model.Selectable, int, error)
+	GetSelectablesWithContext(ctx context.Context, token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) ([]model.Selectable, int, error)
+	GetGroupedSelectables(ctx context.Context, token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) (model.GroupedSelectables, int, error)
+	BulkSelectablesV2(ctx context.Context, token string, request model.BulkRequestV2, options *BulkOptions) (model.BulkResult, int, error)
+	BulkGroupedSelectablesV2(ctx context.Context, token string, request model.BulkRequestV2, options *BulkOptions) (model.GroupedBulkResult, int, error)
+	BulkSelectablesCombinedDevices(ctx context.Context, token string, request model.BulkRequest) ([]model.PermSearchDevice, int, error)
+	DeviceGroupHelper(ctx context.Context, token string, deviceIds []string, options *DeviceGroupHelperOptions) (model.DeviceGroupHelperResult, int, error)
+	QueryDeviceTypes(ctx context.Context, token string, criteria model.FilterCriteriaAndSet, options *QueryDeviceTypesOptions) ([]model.DeviceTypeSelection, int, error)
+
+	// Deprecated: use GetSelectablesWithContext
+	GetSelectablesV1(ctx context.Context, token string, criteria model.FilterCriteriaAndSet, options *GetSelectablesV1Options) ([]model.Selectable, int, error)
+	// Deprecated: use BulkSelectablesV2
+	BulkSelectables(ctx context.Context, token string, request model.BulkRequest, options *BulkOptions) (model.BulkResult, int, error)
 }
 
 type ClientImpl struct {
-	baseUrl string
+	baseUrl    string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+type Option func(client *ClientImpl)
+
+// WithHttpClient replaces http.DefaultClient, e.g. to set timeouts or a traced transport
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(client *ClientImpl) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithRetries repeats requests that failed with a network error, 429, 502, 503 or 504 up to maxRetries times.
+// the wait time starts with backoff and doubles with every attempt up to maxBackoff; a Retry-After header takes precedence.
+func WithRetries(maxRetries int, backoff time.Duration, maxBackoff time.Duration) Option {
+	return func(client *ClientImpl) {
+		client.maxRetries = maxRetries
+		client.backoff = backoff
+		client.maxBackoff = maxBackoff
+	}
+}
+
+func NewClient(baseUrl string, options ...Option) Client {
+	result := &ClientImpl{baseUrl: baseUrl, httpClient: http.DefaultClient}
+	for _, option := range options {
+		option(result)
+	}
+	return result
+}
+
+// Error is returned for responses with a status code >= 300
+type Error struct {
+	StatusCode int
+	Message    string        //response body
+	RetryAfter time.Duration //value of the Retry-After header (429, 503)
+}
+
+func (this *Error) Error() string {
+	return fmt.Sprintf("unexpected statuscode %v: %v", this.StatusCode, this.Message)
 }
 
-// Client only implements GetSelectables. Client should be extended if more API functionality is required
-func NewClient(baseUrl string) Client {
-	return &ClientImpl{baseUrl: baseUrl}
+func do[T any](ctx context.Context, c *ClientImpl, method string, path string, query url.Values, token string, body interface{}) (result T, code int, err error) {
+	var payload []byte
+	if body != nil {
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return result, http.StatusInternalServerError, err
+		}
+	}
+	endpoint := c.baseUrl + path
+	if len(query) > 0 {
+		endpoint = endpoint + "?" + query.Encode()
+	}
+	for attempt := 0; ; attempt++ {
+		var retryable bool
+		result, code, err, retryable = send[T](ctx, c, method, endpoint, token, payload)
+		if err == nil || !retryable || attempt >= c.maxRetries {
+			return result, code, err
+		}
+		wait := c.wait(attempt)
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, code, err
+		case <-timer.C:
+		}
+	}
 }
 
-func do[T any](req *http.Request) (result T, code int, err error) {
-	resp, err := http.DefaultClient.Do(req)
+func send[T any](ctx context.Context, c *ClientImpl, method string, endpoint string, token string, payload []byte) (result T, code int, err error, retryable bool) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return result, http.StatusInternalServerError, err, false
+	}
+	req.Header.Set("Authorization", token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return result, http.StatusInternalServerError, err
+		return result, http.StatusInternalServerError, err, ctx.Err() == nil
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode > 299 {
 		temp, _ := io.ReadAll(resp.Body) //read error response end ensure that resp.Body is read to EOF
-		return result, resp.StatusCode, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp))
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(temp))}
+		if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return result, resp.StatusCode, apiErr, isRetryable(resp.StatusCode)
 	}
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
 		_, _ = io.ReadAll(resp.Body) //ensure resp.Body is read to EOF
-		return result, http.StatusInternalServerError, err
+		return result, http.StatusInternalServerError, err, false
+	}
+	return result, resp.StatusCode, nil, false
+}
+
+func isRetryable(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *ClientImpl) wait(attempt int) time.Duration {
+	result := c.backoff << attempt
+	if c.maxBackoff > 0 && (result > c.maxBackoff || result <= 0) {
+		result = c.maxBackoff
 	}
-	return
+	return result
 }
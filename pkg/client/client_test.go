@@ -0,0 +1,108 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+)
+
+var _ Client = &TestClient{}
+
+func TestClientRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls++
+		if request.URL.Path != "/v2/bulk/selectables" || request.URL.Query().Get("complete_services") != "true" || request.Header.Get("Authorization") != "token" {
+			http.Error(writer, "unexpected request "+request.URL.String(), http.StatusBadRequest)
+			return
+		}
+		if calls == 1 {
+			http.Error(writer, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		bulk := model.BulkRequestV2{}
+		err := json.NewDecoder(request.Body).Decode(&bulk)
+		if err != nil || len(bulk) != 1 {
+			http.Error(writer, "unexpected body", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(writer).Encode(model.BulkResult{{Id: bulk[0].Id}})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, WithRetries(2, time.Millisecond, 10*time.Millisecond))
+	result, code, err := c.BulkSelectablesV2(context.Background(), "token", model.BulkRequestV2{{Id: "a"}}, &BulkOptions{CompleteServices: true})
+	if err != nil || code != http.StatusOK || len(result) != 1 || result[0].Id != "a" || calls != 2 {
+		t.Error(result, code, err, calls)
+	}
+}
+
+func TestClientError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls++
+		writer.Header().Set("Retry-After", "7")
+		http.Error(writer, "rate limit exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, code, err := NewClient(server.URL).DeviceGroupHelper(context.Background(), "token", []string{"d1"}, nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || code != http.StatusTooManyRequests || apiErr.Message != "rate limit exceeded" || apiErr.RetryAfter != 7*time.Second {
+		t.Error(code, err)
+	}
+	if calls != 1 {
+		t.Error("requests must not be retried without WithRetries", calls)
+	}
+}
+
+func TestTestClient(t *testing.T) {
+	c := NewTestClient()
+	c.SetResponse([]model.Selectable{{}}, http.StatusOK, nil)
+	c.SetMethodHandler("BulkSelectablesV2", func(call Call) (interface{}, int, error) {
+		return model.BulkResult{{Id: call.Request.(model.BulkRequestV2)[0].Id}}, http.StatusOK, nil
+	})
+	c.SetMethodResponse("DeviceGroupHelper", model.BulkResult{}, http.StatusOK, nil)
+
+	selectables, _, err := c.GetSelectables("token", nil, nil)
+	if err != nil || len(selectables) != 1 {
+		t.Error(selectables, err)
+	}
+	bulk, _, err := c.BulkSelectablesV2(context.Background(), "token", model.BulkRequestV2{{Id: "a"}}, nil)
+	if err != nil || len(bulk) != 1 || bulk[0].Id != "a" {
+		t.Error(bulk, err)
+	}
+	_, code, err := c.DeviceGroupHelper(context.Background(), "token", nil, nil)
+	if err == nil || code != http.StatusInternalServerError {
+		t.Error("expected type error", code, err)
+	}
+	devices, code, err := c.BulkSelectablesCombinedDevices(context.Background(), "token", nil)
+	if err != nil || code != http.StatusOK || devices == nil {
+		t.Error(devices, code, err)
+	}
+	if len(c.Calls()) != 4 || len(c.Calls("BulkSelectablesV2")) != 1 || c.Calls("GetSelectables")[0].Token != "token" {
+		t.Error(c.Calls())
+	}
+}
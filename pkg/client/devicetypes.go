@@ -0,0 +1,43 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+)
+
+type QueryDeviceTypesOptions struct {
+	IncludeIdModified                 bool
+	FilterIncompatibleCharacteristics bool
+}
+
+func (c *ClientImpl) QueryDeviceTypes(ctx context.Context, token string, criteria model.FilterCriteriaAndSet, options *QueryDeviceTypesOptions) ([]model.DeviceTypeSelection, int, error) {
+	query := url.Values{}
+	if options != nil {
+		if options.IncludeIdModified {
+			query.Set("include_id_modified", "true")
+		}
+		if options.FilterIncompatibleCharacteristics {
+			query.Set("filter_incompatible_characteristics", "true")
+		}
+	}
+	return do[[]model.DeviceTypeSelection](ctx, c, http.MethodPost, "/v2/query/device-types", query, token, criteria)
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+type DeviceGroupHelperOptions struct {
+	Search                  string
+	Limit                   int64 //server default is 100
+	Offset                  int64
+	MaintainsGroupUsability bool //only offer devices that keep the group usable
+	FunctionBlockList       []string
+	AspectMatch             devicemodel.AspectMatch //empty is devicemodel.AspectMatchDescendants
+}
+
+func (c *ClientImpl) DeviceGroupHelper(ctx context.Context, token string, deviceIds []string, options *DeviceGroupHelperOptions) (model.DeviceGroupHelperResult, int, error) {
+	query := url.Values{}
+	if options != nil {
+		if options.Search != "" {
+			query.Set("search", options.Search)
+		}
+		if options.Limit > 0 {
+			query.Set("limit", strconv.FormatInt(options.Limit, 10))
+		}
+		if options.Offset > 0 {
+			query.Set("offset", strconv.FormatInt(options.Offset, 10))
+		}
+		if options.MaintainsGroupUsability {
+			query.Set("maintains_group_usability", "true")
+		}
+		if len(options.FunctionBlockList) > 0 {
+			query.Set("function_block_list", strings.Join(options.FunctionBlockList, ","))
+		}
+		if options.AspectMatch != "" {
+			query.Set("aspect_match", string(options.AspectMatch))
+		}
+	}
+	if deviceIds == nil {
+		deviceIds = []string{}
+	}
+	return do[model.DeviceGroupHelperResult](ctx, c, http.MethodPost, "/device-group-helper", query, token, deviceIds)
+}
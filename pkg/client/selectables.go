@@ -17,7 +17,7 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 	"github.com/SENERGY-Platform/models/go/models"
 )
 
@@ -33,19 +34,49 @@ type GetSelectablesOptions struct {
 	IncludeImports              bool
 	IncludeDevices              bool
 	IncludeIdModified           bool
+	ImportPathTrimFirstElement  bool
 	WithDeviceIds               []string
 	WithLocalDeviceIds          []string
 	LocalDeviceOwner            string
 	FilterByDeviceAttributeKeys []string
+	ImportFilter                *model.ImportFilter
+
+	FilterIncompatibleCharacteristics bool
+	PreferredInteraction              devicemodel.Interaction //ranks selectables with this interaction higher; optional criteria need BulkSelectablesV2
 }
 
 func (c *ClientImpl) GetSelectables(token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) ([]model.Selectable, int, error) {
+	return c.GetSelectablesWithContext(context.Background(), token, criteria, options)
+}
+
+func (c *ClientImpl) GetSelectablesWithContext(ctx context.Context, token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) ([]model.Selectable, int, error) {
+	query, err := getSelectablesQuery(options)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return do[[]model.Selectable](ctx, c, http.MethodPost, "/v2/query/selectables", query, token, criteria)
+}
+
+// GetGroupedSelectables responds with the compact form of GetSelectablesWithContext; use model.GroupedSelectables.Expand to get the selectable list
+func (c *ClientImpl) GetGroupedSelectables(ctx context.Context, token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) (model.GroupedSelectables, int, error) {
+	query, err := getSelectablesQuery(options)
+	if err != nil {
+		return model.GroupedSelectables{}, http.StatusInternalServerError, err
+	}
+	query.Set("format", "grouped")
+	return do[model.GroupedSelectables](ctx, c, http.MethodPost, "/v2/query/selectables", query, token, criteria)
+}
+
+func getSelectablesQuery(options *GetSelectablesOptions) (url.Values, error) {
 	query := url.Values{}
 	if options != nil {
 		query.Set("include_groups", strconv.FormatBool(options.IncludeGroups))
 		query.Set("include_imports", strconv.FormatBool(options.IncludeImports))
 		query.Set("include_devices", strconv.FormatBool(options.IncludeDevices))
 		query.Set("include_id_modified", strconv.FormatBool(options.IncludeIdModified))
+		if options.ImportPathTrimFirstElement {
+			query.Set("import_path_trim_first_element", "true")
+		}
 		if options.WithLocalDeviceIds != nil {
 			query.Set("local_devices", strings.Join(options.WithLocalDeviceIds, ","))
 		}
@@ -58,15 +89,55 @@ func (c *ClientImpl) GetSelectables(token string, criteria []models.DeviceGroupF
 		if len(options.FilterByDeviceAttributeKeys) > 0 {
 			query.Set("filter_devices_by_attr_keys", strings.Join(options.FilterByDeviceAttributeKeys, ","))
 		}
+		if options.FilterIncompatibleCharacteristics {
+			query.Set("filter_incompatible_characteristics", "true")
+		}
+		if options.PreferredInteraction != "" {
+			query.Set("preferred_interaction", string(options.PreferredInteraction))
+		}
+		if options.ImportFilter != nil {
+			importFilter, err := json.Marshal(options.ImportFilter)
+			if err != nil {
+				return nil, err
+			}
+			query.Set("import_filter", string(importFilter))
+		}
 	}
-	b, err := json.Marshal(criteria)
+	return query, nil
+}
+
+type GetSelectablesV1Options struct {
+	IncludeGroups      bool
+	IncludeImports     bool
+	CompleteServices   bool
+	WithLocalDeviceIds []string
+	FilterProtocols    []string
+	FilterInteraction  devicemodel.Interaction
+}
+
+// GetSelectablesV1 uses the deprecated GET /selectables endpoint
+func (c *ClientImpl) GetSelectablesV1(ctx context.Context, token string, criteria model.FilterCriteriaAndSet, options *GetSelectablesV1Options) ([]model.Selectable, int, error) {
+	criteriaJson, err := json.Marshal(criteria)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
-	req, err := http.NewRequest(http.MethodPost, c.baseUrl+"/v2/query/selectables?"+query.Encode(), bytes.NewBuffer(b))
-	if err != nil {
-		return nil, http.StatusInternalServerError, err
+	query := url.Values{}
+	query.Set("json", string(criteriaJson))
+	if options != nil {
+		query.Set("include_groups", strconv.FormatBool(options.IncludeGroups))
+		query.Set("include_imports", strconv.FormatBool(options.IncludeImports))
+		if options.CompleteServices {
+			query.Set("complete_services", "true")
+		}
+		if options.WithLocalDeviceIds != nil {
+			query.Set("local_devices", strings.Join(options.WithLocalDeviceIds, ","))
+		}
+		if len(options.FilterProtocols) > 0 {
+			query.Set("filter_protocols", strings.Join(options.FilterProtocols, ","))
+		}
+		if options.FilterInteraction != "" {
+			query.Set("filter_interaction", string(options.FilterInteraction))
+		}
 	}
-	req.Header.Set("Authorization", token)
-	return do[[]model.Selectable](req)
+	return do[[]model.Selectable](ctx, c, http.MethodGet, "/selectables", query, token, nil)
 }
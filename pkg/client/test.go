@@ -17,28 +17,141 @@
 package client
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"slices"
+	"sync"
 
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/models/go/models"
 )
 
+// TestClient is a programmable fake of Client: it records every call and answers with the response set for the method.
+// methods are identified by their name in Client; GetSelectables and GetSelectablesWithContext share "GetSelectables".
+// methods without response return an empty result with 200.
 type TestClient struct {
-	value []model.Selectable
-	code  int
-	err   error
+	mux       sync.Mutex
+	calls     []Call
+	responses map[string]Response
+}
+
+// Call is a recorded call of TestClient
+type Call struct {
+	Method  string
+	Token   string
+	Request interface{} //criteria, bulk request or device ids
+	Options interface{} //options pointer of the call, nil if the method has no options
+}
+
+type Response struct {
+	Value interface{} //must have the result type of the method
+	Code  int
+	Err   error
+	// Handler is used instead of Value, Code and Err if set
+	Handler func(call Call) (value interface{}, code int, err error)
 }
 
 func NewTestClient() *TestClient {
-	return &TestClient{value: []model.Selectable{}, code: http.StatusOK, err: nil}
+	return &TestClient{responses: map[string]Response{}}
+}
+
+// SetResponse sets the response of GetSelectables
+func (c *TestClient) SetResponse(value []model.Selectable, code int, err error) {
+	c.SetMethodResponse("GetSelectables", value, code, err)
+}
+
+func (c *TestClient) SetMethodResponse(method string, value interface{}, code int, err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.responses[method] = Response{Value: value, Code: code, Err: err}
+}
+
+// SetMethodHandler computes the responses of method, e.g. to return different results per call
+func (c *TestClient) SetMethodHandler(method string, handler func(call Call) (value interface{}, code int, err error)) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.responses[method] = Response{Handler: handler}
+}
+
+// Calls returns all recorded calls; if methods are given only calls of these methods
+func (c *TestClient) Calls(methods ...string) (result []Call) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for _, call := range c.calls {
+		if len(methods) == 0 || slices.Contains(methods, call.Method) {
+			result = append(result, call)
+		}
+	}
+	return result
+}
+
+// Reset removes recorded calls and responses
+func (c *TestClient) Reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.calls = nil
+	c.responses = map[string]Response{}
+}
+
+func respond[T any](c *TestClient, call Call, empty T) (T, int, error) {
+	c.mux.Lock()
+	c.calls = append(c.calls, call)
+	response, ok := c.responses[call.Method]
+	c.mux.Unlock()
+	if !ok {
+		return empty, http.StatusOK, nil
+	}
+	value, code, err := response.Value, response.Code, response.Err
+	if response.Handler != nil {
+		value, code, err = response.Handler(call)
+	}
+	if value == nil {
+		return empty, code, err
+	}
+	result, ok := value.(T)
+	if !ok {
+		return empty, http.StatusInternalServerError, fmt.Errorf("TestClient: response of %v is %T, expected %T", call.Method, value, empty)
+	}
+	return result, code, err
 }
 
 func (c *TestClient) GetSelectables(token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) ([]model.Selectable, int, error) {
-	return c.value, c.code, c.err
+	return c.GetSelectablesWithContext(context.Background(), token, criteria, options)
 }
 
-func (c *TestClient) SetResponse(value []model.Selectable, code int, err error) {
-	c.value = value
-	c.code = code
-	c.err = err
+func (c *TestClient) GetSelectablesWithContext(ctx context.Context, token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) ([]model.Selectable, int, error) {
+	return respond(c, Call{Method: "GetSelectables", Token: token, Request: criteria, Options: options}, []model.Selectable{})
+}
+
+func (c *TestClient) GetGroupedSelectables(ctx context.Context, token string, criteria []models.DeviceGroupFilterCriteria, options *GetSelectablesOptions) (model.GroupedSelectables, int, error) {
+	return respond(c, Call{Method: "GetGroupedSelectables", Token: token, Request: criteria, Options: options}, model.GroupedSelectables{})
+}
+
+func (c *TestClient) GetSelectablesV1(ctx context.Context, token string, criteria model.FilterCriteriaAndSet, options *GetSelectablesV1Options) ([]model.Selectable, int, error) {
+	return respond(c, Call{Method: "GetSelectablesV1", Token: token, Request: criteria, Options: options}, []model.Selectable{})
+}
+
+func (c *TestClient) BulkSelectablesV2(ctx context.Context, token string, request model.BulkRequestV2, options *BulkOptions) (model.BulkResult, int, error) {
+	return respond(c, Call{Method: "BulkSelectablesV2", Token: token, Request: request, Options: options}, model.BulkResult{})
+}
+
+func (c *TestClient) BulkGroupedSelectablesV2(ctx context.Context, token string, request model.BulkRequestV2, options *BulkOptions) (model.GroupedBulkResult, int, error) {
+	return respond(c, Call{Method: "BulkGroupedSelectablesV2", Token: token, Request: request, Options: options}, model.GroupedBulkResult{})
+}
+
+func (c *TestClient) BulkSelectables(ctx context.Context, token string, request model.BulkRequest, options *BulkOptions) (model.BulkResult, int, error) {
+	return respond(c, Call{Method: "BulkSelectables", Token: token, Request: request, Options: options}, model.BulkResult{})
+}
+
+func (c *TestClient) BulkSelectablesCombinedDevices(ctx context.Context, token string, request model.BulkRequest) ([]model.PermSearchDevice, int, error) {
+	return respond(c, Call{Method: "BulkSelectablesCombinedDevices", Token: token, Request: request}, []model.PermSearchDevice{})
+}
+
+func (c *TestClient) DeviceGroupHelper(ctx context.Context, token string, deviceIds []string, options *DeviceGroupHelperOptions) (model.DeviceGroupHelperResult, int, error) {
+	return respond(c, Call{Method: "DeviceGroupHelper", Token: token, Request: deviceIds, Options: options}, model.DeviceGroupHelperResult{})
+}
+
+func (c *TestClient) QueryDeviceTypes(ctx context.Context, token string, criteria model.FilterCriteriaAndSet, options *QueryDeviceTypesOptions) ([]model.DeviceTypeSelection, int, error) {
+	return respond(c, Call{Method: "QueryDeviceTypes", Token: token, Request: criteria, Options: options}, []model.DeviceTypeSelection{})
 }
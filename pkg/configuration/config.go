@@ -17,54 +17,195 @@
 package configuration
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	struct_logger "github.com/SENERGY-Platform/go-service-base/struct-logger"
+	"gopkg.in/yaml.v3"
 )
 
+// ConfigStruct is validated by the config tag:
+// required, url, duration, cidr, ratio (0-1), min=0 and oneof=a|b describe valid values ("" is only rejected by required),
+// reloadable fields are applied by Reload without restart and secret fields are redacted by Redacted
 type ConfigStruct struct {
-	ApiPort         string   `json:"api_port"`
-	DeviceRepoUrl   string   `json:"device_repo_url"`
-	ImportDeployUrl string   `json:"import_deploy_url"`
-	ImportRepoUrl   string   `json:"import_repo_url"`
+	ApiPort         string   `json:"api_port" config:"required"`
+	DeviceRepoUrl   string   `json:"device_repo_url" config:"required,url"`
+	ImportDeployUrl string   `json:"import_deploy_url" config:"url"`
+	ImportRepoUrl   string   `json:"import_repo_url" config:"url"`
 	MemcachedUrls   []string `json:"memcached_urls"`
 	Debug           bool     `json:"debug"`
 
+	CacheExpiration string `json:"cache_expiration" config:"duration,reloadable"` //expiration of cached device-repository and import-repository results
+
+	DeviceRepoTimeout   string `json:"device_repo_timeout" config:"duration"`
+	ImportDeployTimeout string `json:"import_deploy_timeout" config:"duration"`
+	ImportRepoTimeout   string `json:"import_repo_timeout" config:"duration"`
+
+	UpstreamMaxAbandonedCalls int64 `json:"upstream_max_abandoned_calls" config:"min=0"` //per repository; timed out device-repository and import-repository calls keep running, further calls are rejected with 503 while this many are running; 0 is unlimited
+
+	ImportDeployPageSize        int64  `json:"import_deploy_page_size" config:"min=0"`           //instances per import-deploy request; 0 uses the default of 500
+	ImportDeployMaxPages        int64  `json:"import_deploy_max_pages" config:"min=0"`           //import-deploy requests per listing; 0 uses the default of 20
+	ImportDeployCacheExpiration string `json:"import_deploy_cache_expiration" config:"duration"` //expiration of import instances cached per user or token; "" or 0 disables the cache
+
+	UpstreamMaxRetries              int64  `json:"upstream_max_retries" config:"min=0"` //retries of failed reads (network errors, 429 and 5xx)
+	UpstreamRetryBackoff            string `json:"upstream_retry_backoff" config:"duration"`
+	UpstreamRetryMaxBackoff         string `json:"upstream_retry_max_backoff" config:"duration"`
+	UpstreamBreakerFailureThreshold int64  `json:"upstream_breaker_failure_threshold" config:"min=0"` //consecutive failures that open the circuit breaker of an upstream service; 0 disables the breaker
+	UpstreamBreakerOpenDuration     string `json:"upstream_breaker_open_duration" config:"duration"`
+	UpstreamStaleFallback           bool   `json:"upstream_stale_fallback"`                       //while a breaker is open, answer with the last successful result of the same call (same token, same parameters)
+	UpstreamStaleFallbackTtl        string `json:"upstream_stale_fallback_ttl" config:"duration"` //required by upstream_stale_fallback; stale results expire after this duration
+
 	KafkaUrl                        string   `json:"kafka_url"`
 	KafkaConsumerGroup              string   `json:"kafka_consumer_group"`
 	KafkaTopicsForCacheInvalidation []string `json:"kafka_topics_for_cache_invalidation"`
 
 	InitTopics bool `json:"init_topics"`
 
-	LogLevel string       `json:"log_level"`
+	AuthVerification    string   `json:"auth_verification" config:"oneof=none|jwks"` //"none": tokens are forwarded without validation (expects an ingress that validates them); "jwks": signature, expiry, issuer and audience are checked
+	AuthJwksUrl         string   `json:"auth_jwks_url"`                              //url like https://keycloak/auth/realms/master/protocol/openid-connect/certs or path of a local jwks file
+	AuthIssuers         []string `json:"auth_issuers"`                               //accepted iss claims; empty accepts every issuer
+	AuthAudiences       []string `json:"auth_audiences"`                             //the aud claim must contain one of these; empty disables the check
+	AuthClockSkew       string   `json:"auth_clock_skew" config:"duration"`          //tolerance for exp and nbf
+	AuthTrustedNetworks []string `json:"auth_trusted_networks" config:"cidr"`        //cidrs of internal callers that may use unverified service tokens like client.InternalAdminToken
+
+	AuthEndpoint     string `json:"auth_endpoint" config:"url"`
+	AuthClientId     string `json:"auth_client_id"`
+	AuthClientSecret string `json:"auth_client_secret" config:"secret"`
+
+	RateLimitPerMinute map[string]float64 `json:"rate_limit_per_minute" config:"min=0,reloadable"` //requests per minute and user by role; "default" applies to users without a listed role; 0 or missing is unlimited
+	RateLimitBurst     map[string]int64   `json:"rate_limit_burst" config:"min=0,reloadable"`      //requests above the rate a user may send at once; defaults to one second worth of requests
+	MaxBulkElements    map[string]int64   `json:"max_bulk_elements" config:"min=0,reloadable"`     //by role; larger bulk requests are answered with 413
+	MaxUpstreamCalls   map[string]int64   `json:"max_upstream_calls" config:"min=0,reloadable"`    //by role; requests needing more device-repository, import-repository and import-deploy calls are aborted with 413
+
+	AuditSink        string   `json:"audit_sink" config:"oneof=none|stdout|file|kafka"`
+	AuditFile        string   `json:"audit_file"`        //used by audit_sink=file
+	AuditKafkaTopic  string   `json:"audit_kafka_topic"` //used by audit_sink=kafka in combination with kafka_url; the topic is not created by init_topics
+	AuditSampleRatio float64  `json:"audit_sample_ratio" config:"ratio"`
+	AuditRedact      []string `json:"audit_redact" config:"oneof=user_id|options|device_ids"` //"user_id" (hashed), "options", "device_ids"
+
+	TracingExporter     string  `json:"tracing_exporter" config:"oneof=none|otlp"` //"" or "none" disables export; "otlp" sends spans to TracingOtlpEndpoint
+	TracingOtlpEndpoint string  `json:"tracing_otlp_endpoint" config:"url"`        //otlp/http collector url like http://otel-collector:4318
+	TracingServiceName  string  `json:"tracing_service_name"`
+	TracingSampleRatio  float64 `json:"tracing_sample_ratio" config:"ratio"`
+
+	HealthCheckTimeout       string `json:"health_check_timeout" config:"duration"`        //max duration of a single dependency probe
+	HealthCheckCacheDuration string `json:"health_check_cache_duration" config:"duration"` //probe results are reused for this duration
+
+	CorsAllowedOrigins   []string `json:"cors_allowed_origins" config:"reloadable"`   //exact origins or patterns with one "*" like "https://*.example.com"; "*" allows every origin; empty disables cors
+	CorsAllowCredentials bool     `json:"cors_allow_credentials" config:"reloadable"` //in combination with "*" every origin is reflected (behavior of earlier versions)
+	CorsAllowedMethods   []string `json:"cors_allowed_methods" config:"reloadable"`
+	CorsAllowedHeaders   []string `json:"cors_allowed_headers" config:"reloadable"`
+	CorsMaxAge           string   `json:"cors_max_age" config:"duration,reloadable"` //duration browsers may cache preflight results
+
+	SecurityHeaders    bool   `json:"security_headers"`                        //nosniff, frame, referrer, content-security-policy and no-store headers on every response
+	SecurityHstsMaxAge string `json:"security_hsts_max_age" config:"duration"` //"" disables Strict-Transport-Security; only set if the service is exclusively reachable by https
+
+	ConfigReloadInterval string `json:"config_reload_interval" config:"duration"` //interval in which the config file is checked for changes; "" only reloads on SIGHUP
+	StrictConfig         bool   `json:"strict_config"`                            //reject unknown fields instead of logging a warning
+
+	LogLevel string       `json:"log_level" config:"oneof=debug|info|warn|error,reloadable"`
 	logger   *slog.Logger `json:"-"`
+	runtime  *runtime     `json:"-"`
 }
 
 type Config = *ConfigStruct
 
+// Load reads a json or yaml (.yaml, .yml) file, applies environment variables and validates the result.
+// unknown fields (e.g. typos or fields of older versions) are logged as warning; with strict_config they are rejected.
 func Load(location string) (config Config, err error) {
-	file, err := os.Open(location)
+	config, unknown, err := loadFile(location)
+	if err != nil {
+		return config, err
+	}
+	err = HandleEnvironmentVars(config)
+	if err != nil {
+		return config, fmt.Errorf("error on config load: %w", err)
+	}
+	err = Validate(config)
 	if err != nil {
-		return config, fmt.Errorf("error on config load, unable to open file: %w", err)
+		return config, fmt.Errorf("invalid config: %w", err)
 	}
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&config)
+	err = checkUnknownFields(config.StrictConfig, unknown, config.GetLogger())
 	if err != nil {
-		return config, fmt.Errorf("error on config load, not valid json: %w", err)
+		return config, fmt.Errorf("invalid config: %w", err)
 	}
-	HandleEnvironmentVars(config)
 	return config, nil
 }
 
+func loadFile(location string) (config Config, unknown []string, err error) {
+	content, err := os.ReadFile(location)
+	if err != nil {
+		return config, unknown, fmt.Errorf("error on config load, unable to open file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".yaml", ".yml":
+		var temp interface{}
+		err = yaml.Unmarshal(content, &temp)
+		if err != nil {
+			return config, unknown, fmt.Errorf("error on config load, not valid yaml: %w", err)
+		}
+		content, err = json.Marshal(temp)
+		if err != nil {
+			return config, unknown, fmt.Errorf("error on config load, unable to convert yaml: %w", err)
+		}
+	}
+	err = json.Unmarshal(content, &config)
+	if err != nil {
+		return config, unknown, fmt.Errorf("error on config load, invalid content: %w", err)
+	}
+	if config == nil {
+		return config, unknown, errors.New("error on config load, empty file")
+	}
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(content, &fields)
+	if err != nil {
+		return config, unknown, fmt.Errorf("error on config load, invalid content: %w", err)
+	}
+	known := knownFields()
+	for name := range fields {
+		if !slices.Contains(known, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+	return config, unknown, nil
+}
+
+// checkUnknownFields rejects unknown fields if strict is set and logs them otherwise
+func checkUnknownFields(strict bool, unknown []string, logger *slog.Logger) error {
+	if len(unknown) == 0 {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("unknown fields %v (strict_config)", strings.Join(unknown, ", "))
+	}
+	logger.Warn("unknown config fields are ignored", "fields", unknown)
+	return nil
+}
+
+func knownFields() (result []string) {
+	configType := reflect.TypeOf(ConfigStruct{})
+	for index := 0; index < configType.NumField(); index++ {
+		field := configType.Field(index)
+		if field.IsExported() {
+			result = append(result, jsonFieldName(field))
+		}
+	}
+	return result
+}
+
 var camel = regexp.MustCompile("(^[^A-Z]*|[A-Z]*)([A-Z][^A-Z]+|$)")
 
 func fieldNameToEnvName(s string) string {
@@ -80,57 +221,104 @@ func fieldNameToEnvName(s string) string {
 	return strings.ToUpper(strings.Join(a, "_"))
 }
 
-// preparations for docker
-func HandleEnvironmentVars(config Config) {
+// HandleEnvironmentVars overwrites fields with the environment variable of the upper snake case field name (e.g. DEVICE_REPO_URL).
+// lists are comma separated, maps are written as key:value,key:value.
+// used variables are printed; values of secret fields are redacted.
+func HandleEnvironmentVars(config Config) error {
 	configValue := reflect.Indirect(reflect.ValueOf(config))
 	configType := configValue.Type()
+	errs := []error{}
 	for index := 0; index < configType.NumField(); index++ {
-		fieldName := configType.Field(index).Name
-		envName := fieldNameToEnvName(fieldName)
+		field := configType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+		envName := fieldNameToEnvName(field.Name)
 		envValue := os.Getenv(envName)
 		if envValue != "" {
-			fmt.Println("use environment variable: ", envName, " = ", envValue)
-			if configValue.FieldByName(fieldName).Kind() == reflect.Int64 {
-				i, _ := strconv.ParseInt(envValue, 10, 64)
-				configValue.FieldByName(fieldName).SetInt(i)
-			}
-			if configValue.FieldByName(fieldName).Kind() == reflect.Float64 {
-				f, _ := strconv.ParseFloat(envValue, 64)
-				configValue.FieldByName(fieldName).SetFloat(f)
-			}
-			if configValue.FieldByName(fieldName).Kind() == reflect.String {
-				configValue.FieldByName(fieldName).SetString(envValue)
+			logValue := envValue
+			if isSecret(field) {
+				logValue = redactedValue
 			}
-			if configValue.FieldByName(fieldName).Kind() == reflect.Bool {
-				b, _ := strconv.ParseBool(envValue)
-				configValue.FieldByName(fieldName).SetBool(b)
+			fmt.Println("use environment variable: ", envName, " = ", logValue)
+			err := setFieldFromString(configValue.Field(index), envValue)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid environment variable %v: %w", envName, err))
 			}
-			if configValue.FieldByName(fieldName).Kind() == reflect.Slice {
-				val := []string{}
-				for _, element := range strings.Split(envValue, ",") {
-					val = append(val, strings.TrimSpace(element))
-				}
-				configValue.FieldByName(fieldName).Set(reflect.ValueOf(val))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setFieldFromString(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %v", field.Type())
+		}
+		list := []string{}
+		for _, element := range strings.Split(value, ",") {
+			list = append(list, strings.TrimSpace(element))
+		}
+		field.Set(reflect.ValueOf(list))
+		return nil
+	case reflect.Map:
+		result := reflect.MakeMap(field.Type())
+		for _, element := range strings.Split(value, ",") {
+			key, val, found := strings.Cut(element, ":")
+			if !found {
+				return fmt.Errorf("expected key:value, got %q", element)
 			}
-			if configValue.FieldByName(fieldName).Kind() == reflect.Map {
-				value := map[string]string{}
-				for _, element := range strings.Split(envValue, ",") {
-					keyVal := strings.Split(element, ":")
-					key := strings.TrimSpace(keyVal[0])
-					val := strings.TrimSpace(keyVal[1])
-					value[key] = val
-				}
-				configValue.FieldByName(fieldName).Set(reflect.ValueOf(value))
+			parsed := reflect.New(field.Type().Elem()).Elem()
+			err := setFieldFromString(parsed, strings.TrimSpace(val))
+			if err != nil {
+				return fmt.Errorf("value of %v: %w", key, err)
 			}
+			result.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), parsed)
+		}
+		field.Set(result)
+		return nil
+	default:
+		return setScalarFromString(field, value)
+	}
+}
+
+func setScalarFromString(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
 		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %v", field.Type())
 	}
+	return nil
 }
 
 func (this *ConfigStruct) GetLogger() *slog.Logger {
 	if this.logger == nil {
-		if this.Debug {
-			this.LogLevel = "debug"
-		}
 		info, ok := debug.ReadBuildInfo()
 		project := ""
 		org := ""
@@ -140,10 +328,10 @@ func (this *ConfigStruct) GetLogger() *slog.Logger {
 				org = strings.Join(parts[:2], "/")
 			}
 		}
-		this.logger = struct_logger.New(
+		logger := struct_logger.New(
 			struct_logger.Config{
 				Handler:    struct_logger.JsonHandlerSelector,
-				Level:      this.LogLevel,
+				Level:      struct_logger.LevelDebug, //filtered by levelHandler to allow level changes on reload
 				TimeFormat: time.RFC3339Nano,
 				TimeUtc:    true,
 				AddMeta:    true,
@@ -152,8 +340,35 @@ func (this *ConfigStruct) GetLogger() *slog.Logger {
 			org,
 			project,
 		)
+		level := this.getRuntime().level
+		level.Set(logLevel(this))
+		this.logger = slog.New(&levelHandler{Handler: logger.Handler(), level: level})
 		slog.SetDefault(this.logger)
 		slog.SetLogLoggerLevel(slog.LevelInfo)
 	}
 	return this.logger
 }
+
+func logLevel(config Config) slog.Level {
+	if config.Debug {
+		return slog.LevelDebug
+	}
+	return struct_logger.GetLevel(config.LogLevel, slog.LevelInfo).Level()
+}
+
+type levelHandler struct {
+	slog.Handler
+	level *slog.LevelVar
+}
+
+func (this *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= this.level.Level() && this.Handler.Enabled(ctx, level)
+}
+
+func (this *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: this.Handler.WithAttrs(attrs), level: this.level}
+}
+
+func (this *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: this.Handler.WithGroup(name), level: this.level}
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package configuration
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadDefaultConfig(t *testing.T) {
+	_, err := Load("../../config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadYaml(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(location, []byte("api_port: \"8080\"\ndevice_repo_url: http://device-repo:8080\nmemcached_urls: [\"memcached:11211\"]\nmax_bulk_elements:\n  default: 10\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DeviceRepoUrl != "http://device-repo:8080" || config.MaxBulkElements["default"] != 10 || len(config.MemcachedUrls) != 1 {
+		t.Errorf("%#v", config)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "unknown.json")
+	err := os.WriteFile(unknown, []byte(`{"api_port": "8080", "device_repo_url": "http://device-repo:8080", "devcie_repo_timeout": "5s"}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(unknown)
+	if err != nil {
+		t.Error("unknown fields should only be rejected with strict_config", err)
+	}
+	t.Setenv("STRICT_CONFIG", "true")
+	_, err = Load(unknown)
+	if err == nil || !strings.Contains(err.Error(), "devcie_repo_timeout") {
+		t.Error(err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	err = os.WriteFile(invalid, []byte(`{"api_port": "8080", "device_repo_url": "device-repo", "device_repo_timeout": "5", "audit_sample_ratio": 2, "audit_redact": ["user_id", "token"], "max_upstream_calls": {"default": -1}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(invalid)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, field := range []string{"device_repo_url", "device_repo_timeout", "audit_sample_ratio", "audit_redact", "max_upstream_calls"} {
+		if !strings.Contains(err.Error(), field+":") {
+			t.Errorf("missing %v in %v", field, err)
+		}
+	}
+	if strings.Contains(err.Error(), "audit_sink") {
+		t.Errorf("empty values must be valid: %v", err)
+	}
+}
+
+func TestValidateCombinations(t *testing.T) {
+	config := &ConfigStruct{ApiPort: "8080", DeviceRepoUrl: "http://device-repo:8080", UpstreamStaleFallback: true}
+	err := Validate(config)
+	if err == nil || !strings.Contains(err.Error(), "upstream_stale_fallback_ttl:") {
+		t.Error(err)
+	}
+	config.UpstreamStaleFallbackTtl = "0s"
+	if err = Validate(config); err == nil {
+		t.Error("expected error")
+	}
+	config.UpstreamStaleFallbackTtl = "1h"
+	if err = Validate(config); err != nil {
+		t.Error(err)
+	}
+
+	config = &ConfigStruct{ApiPort: "8080", DeviceRepoUrl: "http://device-repo:8080", AuthVerification: "jwks", AuditSink: "file", TracingExporter: "otlp"}
+	err = Validate(config)
+	for _, field := range []string{"auth_jwks_url", "audit_file", "tracing_otlp_endpoint"} {
+		if err == nil || !strings.Contains(err.Error(), field+": required by") {
+			t.Errorf("missing %v in %v", field, err)
+		}
+	}
+	config.AuditSink = "kafka"
+	err = Validate(config)
+	for _, field := range []string{"kafka_url", "audit_kafka_topic"} {
+		if err == nil || !strings.Contains(err.Error(), field+": required by") {
+			t.Errorf("missing %v in %v", field, err)
+		}
+	}
+	config.AuthJwksUrl = "http://keycloak:8080/certs"
+	config.KafkaUrl = "kafka:9092"
+	config.AuditKafkaTopic = "audit"
+	config.TracingOtlpEndpoint = "http://otel-collector:4318"
+	if err = Validate(config); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandleEnvironmentVars(t *testing.T) {
+	t.Setenv("UPSTREAM_MAX_RETRIES", "5")
+	t.Setenv("RATE_LIMIT_PER_MINUTE", "default:60, admin:0")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+	config := &ConfigStruct{}
+	err := HandleEnvironmentVars(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.UpstreamMaxRetries != 5 || config.RateLimitPerMinute["default"] != 60 || config.RateLimitPerMinute["admin"] != 0 || len(config.CorsAllowedOrigins) != 2 {
+		t.Errorf("%#v", config)
+	}
+
+	t.Setenv("AUTH_CLIENT_SECRET", "very-secret")
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+	config = &ConfigStruct{}
+	err = HandleEnvironmentVars(config)
+	os.Stdout = stdout
+	writer.Close()
+	output, _ := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.AuthClientSecret != "very-secret" || strings.Contains(string(output), "very-secret") || !strings.Contains(string(output), "AUTH_CLIENT_SECRET") {
+		t.Errorf("secret should be used but not printed: %v", string(output))
+	}
+
+	t.Setenv("MAX_BULK_ELEMENTS", "default")
+	t.Setenv("UPSTREAM_BREAKER_FAILURE_THRESHOLD", "ten")
+	err = HandleEnvironmentVars(&ConfigStruct{})
+	if err == nil || !strings.Contains(err.Error(), "MAX_BULK_ELEMENTS") || !strings.Contains(err.Error(), "UPSTREAM_BREAKER_FAILURE_THRESHOLD") {
+		t.Error(err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(location, []byte(`{"api_port": "8080", "device_repo_url": "http://device-repo:8080", "log_level": "info", "auth_client_secret": "secret"}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := []Config{}
+	config.OnReload(func(config Config) {
+		reloaded = append(reloaded, config)
+	})
+
+	err = os.WriteFile(location, []byte(`{"api_port": "8081", "device_repo_url": "http://device-repo:8080", "log_level": "debug", "auth_client_secret": "secret", "max_bulk_elements": {"default": 10}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := Reload(config, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(changed)
+	if !slices.Equal(changed, []string{"log_level", "max_bulk_elements"}) {
+		t.Error(changed)
+	}
+	effective := config.Effective()
+	if len(reloaded) != 1 || reloaded[0] != effective {
+		t.Error(reloaded)
+	}
+	if effective.LogLevel != "debug" || effective.MaxBulkElements["default"] != 10 || effective.ApiPort != "8080" {
+		t.Errorf("%#v", effective)
+	}
+	if config.LogLevel != "info" {
+		t.Error("the loaded config must not be modified")
+	}
+
+	err = os.WriteFile(location, []byte(`{"api_port": "8080", "device_repo_url": ""}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Reload(config, location)
+	if err == nil || config.Effective() != effective {
+		t.Error("invalid config must not be applied", err)
+	}
+
+	if Redacted(effective).AuthClientSecret != redactedValue || effective.AuthClientSecret != "secret" {
+		t.Error("secret not redacted")
+	}
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package configuration
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// runtime is shared by a config and the copies created by Reload
+type runtime struct {
+	mux       sync.Mutex
+	reloadMux sync.Mutex
+	effective Config
+	listeners []func(config Config)
+	level     *slog.LevelVar
+}
+
+var runtimeInitMux sync.Mutex
+
+func (this *ConfigStruct) getRuntime() *runtime {
+	runtimeInitMux.Lock()
+	defer runtimeInitMux.Unlock()
+	if this.runtime == nil {
+		this.runtime = &runtime{level: new(slog.LevelVar)}
+	}
+	return this.runtime
+}
+
+// OnReload registers a listener that receives the effective config after every Reload that changed a reloadable field
+func (this *ConfigStruct) OnReload(listener func(config Config)) {
+	r := this.getRuntime()
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Effective returns the config with the reloadable fields of the latest Reload
+func (this *ConfigStruct) Effective() Config {
+	r := this.getRuntime()
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.effective == nil {
+		return this
+	}
+	return r.effective
+}
+
+// Reload reads location like Load and applies the reloadable fields; changes of other fields are logged and need a restart.
+// an invalid file leaves the effective config unchanged.
+func Reload(config Config, location string) (changed []string, err error) {
+	next, unknown, err := loadFile(location)
+	if err != nil {
+		return nil, err
+	}
+	err = HandleEnvironmentVars(next)
+	if err != nil {
+		return nil, err
+	}
+	err = checkUnknownFields(next.StrictConfig, unknown, config.GetLogger())
+	if err != nil {
+		return nil, err
+	}
+	err = Validate(next)
+	if err != nil {
+		return nil, err
+	}
+
+	r := config.getRuntime()
+	r.reloadMux.Lock()
+	defer r.reloadMux.Unlock()
+
+	result := *config.Effective()
+	reloadable := ReloadableFields()
+	ignored := []string{}
+	resultValue := reflect.ValueOf(&result).Elem()
+	nextValue := reflect.ValueOf(next).Elem()
+	for index := 0; index < resultValue.NumField(); index++ {
+		field := resultValue.Type().Field(index)
+		if !field.IsExported() || reflect.DeepEqual(resultValue.Field(index).Interface(), nextValue.Field(index).Interface()) {
+			continue
+		}
+		jsonName := jsonFieldName(field)
+		if slices.Contains(reloadable, jsonName) {
+			resultValue.Field(index).Set(nextValue.Field(index))
+			changed = append(changed, jsonName)
+		} else {
+			ignored = append(ignored, jsonName)
+		}
+	}
+	if len(ignored) > 0 {
+		config.GetLogger().Warn("config changes need a restart and are ignored", "fields", ignored)
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+
+	r.mux.Lock()
+	r.effective = &result
+	listeners := slices.Clone(r.listeners)
+	r.mux.Unlock()
+	r.level.Set(logLevel(&result))
+	for _, listener := range listeners {
+		listener(&result)
+	}
+	config.GetLogger().Info("config reloaded", "fields", changed)
+	return changed, nil
+}
+
+// Watch calls Reload on SIGHUP and, if config_reload_interval is set, when the modification time of location changes
+func Watch(ctx context.Context, config Config, location string) error {
+	var interval time.Duration
+	if config.ConfigReloadInterval != "" {
+		var err error
+		interval, err = time.ParseDuration(config.ConfigReloadInterval)
+		if err != nil {
+			return err
+		}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	var ticks <-chan time.Time
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		ticks = ticker.C
+	}
+	lastModified := modificationTime(location)
+	reload := func() {
+		_, err := Reload(config, location)
+		if err != nil {
+			config.GetLogger().Error("unable to reload config, keep previous config", "error", err)
+		}
+	}
+	go func() {
+		defer signal.Stop(signals)
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				config.GetLogger().Info("received SIGHUP, reload config")
+				reload()
+			case <-ticks:
+				if modified := modificationTime(location); !modified.Equal(lastModified) {
+					lastModified = modified
+					reload()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func modificationTime(location string) time.Time {
+	info, err := os.Stat(location)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redactedValue = "***"
+
+// Validate checks every field against the rules of its config tag and fields that depend on each other (see validateCombinations);
+// all violations are returned at once
+func Validate(config Config) error {
+	if config == nil {
+		return errors.New("missing config")
+	}
+	errs := []error{}
+	forEachTaggedField(config, func(name string, rules []string, value reflect.Value) {
+		for _, rule := range rules {
+			err := validateRule(rule, value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%v: %w", name, err))
+			}
+		}
+	})
+	errs = append(errs, validateCombinations(config)...)
+	return errors.Join(errs...)
+}
+
+// validateCombinations checks fields that are required by other fields; invalid values are reported by the field rules
+func validateCombinations(config Config) (errs []error) {
+	if config.AuthVerification == "jwks" && config.AuthJwksUrl == "" {
+		errs = append(errs, errors.New("auth_jwks_url: required by auth_verification=jwks"))
+	}
+	if config.AuditSink == "file" && config.AuditFile == "" {
+		errs = append(errs, errors.New("audit_file: required by audit_sink=file"))
+	}
+	if config.AuditSink == "kafka" && config.KafkaUrl == "" {
+		errs = append(errs, errors.New("kafka_url: required by audit_sink=kafka"))
+	}
+	if config.AuditSink == "kafka" && config.AuditKafkaTopic == "" {
+		errs = append(errs, errors.New("audit_kafka_topic: required by audit_sink=kafka"))
+	}
+	if config.TracingExporter == "otlp" && config.TracingOtlpEndpoint == "" {
+		errs = append(errs, errors.New("tracing_otlp_endpoint: required by tracing_exporter=otlp"))
+	}
+	if config.UpstreamStaleFallback && !isPositiveOrInvalidDuration(config.UpstreamStaleFallbackTtl) {
+		//stale results are stored per token, so they have to expire
+		errs = append(errs, errors.New("upstream_stale_fallback_ttl: a positive duration is required by upstream_stale_fallback"))
+	}
+	return errs
+}
+
+func isPositiveOrInvalidDuration(value string) bool {
+	duration, err := time.ParseDuration(value)
+	return value != "" && (err != nil || duration > 0)
+}
+
+// ReloadableFields returns the json names of all fields that are applied by Reload
+func ReloadableFields() (result []string) {
+	forEachTaggedField(&ConfigStruct{}, func(name string, rules []string, value reflect.Value) {
+		if slices.Contains(rules, "reloadable") {
+			result = append(result, name)
+		}
+	})
+	return result
+}
+
+// Redacted returns a copy of config without the values of secret fields
+func Redacted(config Config) Config {
+	result := *config
+	forEachTaggedField(&result, func(name string, rules []string, value reflect.Value) {
+		if slices.Contains(rules, "secret") && value.Kind() == reflect.String && value.String() != "" {
+			value.SetString(redactedValue)
+		}
+	})
+	return &result
+}
+
+func isSecret(field reflect.StructField) bool {
+	return slices.Contains(strings.Split(field.Tag.Get("config"), ","), "secret")
+}
+
+func forEachTaggedField(config Config, f func(name string, rules []string, value reflect.Value)) {
+	configValue := reflect.Indirect(reflect.ValueOf(config))
+	configType := configValue.Type()
+	for index := 0; index < configType.NumField(); index++ {
+		field := configType.Field(index)
+		tag, ok := field.Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		f(jsonFieldName(field), strings.Split(tag, ","), configValue.Field(index))
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+func validateRule(rule string, value reflect.Value) error {
+	name, parameter, _ := strings.Cut(rule, "=")
+	switch name {
+	case "required":
+		if value.IsZero() {
+			return errors.New("is required")
+		}
+		return nil
+	case "reloadable", "secret":
+		return nil
+	}
+	errs := []error{}
+	for _, element := range elements(value) {
+		err := validateValue(name, parameter, element)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// elements returns the value itself or the elements of lists and maps
+func elements(value reflect.Value) (result []reflect.Value) {
+	switch value.Kind() {
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			result = append(result, value.Index(i))
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			result = append(result, iter.Value())
+		}
+	default:
+		result = append(result, value)
+	}
+	return result
+}
+
+func validateValue(rule string, parameter string, value reflect.Value) error {
+	if value.Kind() == reflect.String && value.String() == "" {
+		return nil
+	}
+	switch rule {
+	case "url":
+		parsed, err := url.Parse(value.String())
+		if err != nil {
+			return err
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%q is not an absolute url", value.String())
+		}
+	case "duration":
+		duration, err := time.ParseDuration(value.String())
+		if err != nil {
+			return err
+		}
+		if duration < 0 {
+			return fmt.Errorf("%q is negative", value.String())
+		}
+	case "cidr":
+		_, _, err := net.ParseCIDR(value.String())
+		if err != nil {
+			return err
+		}
+	case "ratio":
+		if value.Float() < 0 || value.Float() > 1 {
+			return fmt.Errorf("%v is not between 0 and 1", value.Float())
+		}
+	case "min":
+		minimum, err := strconv.ParseFloat(parameter, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rule %v=%v", rule, parameter)
+		}
+		if number(value) < minimum {
+			return fmt.Errorf("%v is less than %v", number(value), parameter)
+		}
+	case "oneof":
+		if !slices.Contains(strings.Split(parameter, "|"), value.String()) {
+			return fmt.Errorf("%q is not one of %v", value.String(), strings.ReplaceAll(parameter, "|", ", "))
+		}
+	default:
+		return fmt.Errorf("unknown rule %v", rule)
+	}
+	return nil
+}
+
+func number(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
@@ -17,13 +17,26 @@
 package controller
 
 import (
+	"context"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 )
 
-func (this *Controller) GetAspectNode(id string, token string) (result devicemodel.AspectNode, err error) {
-	err = this.cache.Use("aspect-nodes."+id, func() (interface{}, error) {
-		aspect, err, _ := this.devicerepo.GetAspectNode(id)
+func (this *Controller) GetAspectNode(ctx context.Context, id string, token string) (result devicemodel.AspectNode, err error) {
+	err = this.cache.Use(ctx, "aspect-nodes."+id, func(ctx context.Context) (interface{}, error) {
+		aspect, err, _ := this.devicerepo.GetAspectNode(ctx, id)
 		return aspect, err
 	}, &result)
 	return
 }
+
+// getUpstreamAspectId returns the aspect id to query the device-repository with (see devicemodel.AspectMatch.UpstreamAspectId)
+func (this *Controller) getUpstreamAspectId(ctx context.Context, token string, criteria devicemodel.FilterCriteria) (string, error) {
+	if !criteria.HasAspectConstraints() {
+		return criteria.AspectId, nil
+	}
+	aspect, err := this.GetAspectNode(ctx, criteria.AspectId, token)
+	if err != nil {
+		return "", err
+	}
+	return criteria.AspectMatch.UpstreamAspectId(aspect), nil
+}
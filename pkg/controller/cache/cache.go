@@ -16,7 +16,11 @@
 
 package cache
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 var LocalCacheExpirationInSec = 600        // 10 min
 var GlobalCacheExpirationInSec int32 = 600 // 10 min
@@ -24,8 +28,10 @@ var GlobalCacheExpirationInSec int32 = 600 // 10 min
 var ErrNotFound = errors.New("key not found in cache")
 
 type Cache interface {
-	Use(key string, getter func() (interface{}, error), result interface{}) (err error)
+	Use(ctx context.Context, key string, getter func(ctx context.Context) (interface{}, error), result interface{}) (err error)
 	Invalidate()
+	Ping() error                            //checks if the cache backend is reachable
+	SetExpiration(expiration time.Duration) //applies to values set afterwards
 }
 
 func New(memcachedUrls []string) Cache {
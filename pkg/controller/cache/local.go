@@ -17,21 +17,27 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
 	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type LocalCache struct {
 	l1         *cache.Cache
-	expiration int
+	expiration atomic.Int64
 }
 
 func NewLocal(expiration int) *LocalCache {
-	return &LocalCache{l1: cache.New(time.Duration(expiration)*time.Second, time.Duration(expiration)*time.Second), expiration: expiration}
+	result := &LocalCache{l1: cache.New(time.Duration(expiration)*time.Second, time.Duration(expiration)*time.Second)}
+	result.SetExpiration(time.Duration(expiration) * time.Second)
+	return result
 }
 
 func (this *LocalCache) Get(key string) (value []byte, err error) {
@@ -52,20 +58,32 @@ func (this *LocalCache) Invalidate() {
 	this.l1.Flush()
 }
 
+func (this *LocalCache) Ping() error {
+	return nil
+}
+
+func (this *LocalCache) SetExpiration(expiration time.Duration) {
+	this.expiration.Store(int64(expiration))
+}
+
 func (this *LocalCache) Set(key string, value []byte) {
-	this.l1.Set(key, value, 0)
+	this.l1.Set(key, value, time.Duration(this.expiration.Load()))
 	return
 }
 
-func (this *LocalCache) Use(key string, getter func() (interface{}, error), result interface{}) (err error) {
+func (this *LocalCache) Use(ctx context.Context, key string, getter func(ctx context.Context) (interface{}, error), result interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "cache.Use", attribute.String("cache.key", key))
+	defer func() { tracing.Finish(span, err) }()
 	value, err := this.Get(key)
 	if err == nil {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
 		err = json.Unmarshal(value, result)
 		return
 	} else if !errors.Is(err, ErrNotFound) {
 		slog.Warn("err in LocalCache::l1.Get()", "error", err)
 	}
-	temp, err := getter()
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	temp, err := getter(ctx)
 	if err != nil {
 		return err
 	}
@@ -17,20 +17,27 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
 	"github.com/bradfitz/gomemcache/memcache"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type GlobalCache struct {
 	l1         *memcache.Client
-	expiration int32
+	expiration atomic.Int32 //seconds
 }
 
 func NewGlobal(urls []string, expiration int32) *GlobalCache {
-	return &GlobalCache{l1: memcache.New(urls...), expiration: expiration}
+	result := &GlobalCache{l1: memcache.New(urls...)}
+	result.expiration.Store(expiration)
+	return result
 }
 
 func (this *GlobalCache) Get(key string) (value []byte, err error) {
@@ -49,11 +56,19 @@ func (this *GlobalCache) Invalidate() {
 	this.l1.DeleteAll()
 }
 
+func (this *GlobalCache) Ping() error {
+	return this.l1.Ping()
+}
+
+func (this *GlobalCache) SetExpiration(expiration time.Duration) {
+	this.expiration.Store(int32(expiration.Seconds()))
+}
+
 func (this *GlobalCache) Set(key string, value []byte) {
 	err := this.l1.Set(&memcache.Item{
 		Key:        key,
 		Value:      value,
-		Expiration: this.expiration,
+		Expiration: this.expiration.Load(),
 	})
 	if err != nil {
 		slog.Warn("err in LocalCache::l1.Set()", "error", err)
@@ -61,15 +76,19 @@ func (this *GlobalCache) Set(key string, value []byte) {
 	return
 }
 
-func (this *GlobalCache) Use(key string, getter func() (interface{}, error), result interface{}) (err error) {
+func (this *GlobalCache) Use(ctx context.Context, key string, getter func(ctx context.Context) (interface{}, error), result interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "cache.Use", attribute.String("cache.key", key))
+	defer func() { tracing.Finish(span, err) }()
 	value, err := this.Get(key)
 	if err == nil {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
 		err = json.Unmarshal(value, result)
 		return
 	} else if !errors.Is(err, ErrNotFound) {
 		slog.Warn("err in GlobalCache::l1.Get()", "error", err)
 	}
-	temp, err := getter()
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	temp, err := getter(ctx)
 	if err != nil {
 		return err
 	}
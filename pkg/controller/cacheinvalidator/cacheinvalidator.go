@@ -24,15 +24,42 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/controller/cacheinvalidator/kafka"
 )
 
-func StartCacheInvalidator(ctx context.Context, config configuration.Config, cache cache.Cache) error {
-	for _, topic := range config.KafkaTopicsForCacheInvalidation {
-		err := kafka.NewConsumer(ctx, config, topic, func(delivery []byte) error {
-			cache.Invalidate()
+// Source calls invalidate whenever cached upstream data may have changed
+type Source interface {
+	Start(ctx context.Context, invalidate func()) error
+}
+
+// NewKafkaSource invalidates on every message of config.KafkaTopicsForCacheInvalidation
+func NewKafkaSource(config configuration.Config) Source {
+	return &KafkaSource{config: config}
+}
+
+type KafkaSource struct {
+	config configuration.Config
+}
+
+// Start starts a consumer per topic; if one of them fails, the already started consumers are stopped
+func (this *KafkaSource) Start(ctx context.Context, invalidate func()) error {
+	ctx, stop := context.WithCancel(ctx) //on success, the consumers stop with the parent context
+	started := false
+	defer func() {
+		if !started {
+			stop()
+		}
+	}()
+	for _, topic := range this.config.KafkaTopicsForCacheInvalidation {
+		err := kafka.NewConsumer(ctx, this.config, topic, func(delivery []byte) error {
+			invalidate()
 			return nil
 		})
 		if err != nil {
 			return fmt.Errorf("unable to start kafka consumer for cache invalidation on topic %v: %w", topic, err)
 		}
 	}
+	started = true
 	return nil
 }
+
+func StartCacheInvalidator(ctx context.Context, config configuration.Config, cache cache.Cache) error {
+	return NewKafkaSource(config).Start(ctx, cache.Invalidate)
+}
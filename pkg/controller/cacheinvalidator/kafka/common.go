@@ -17,6 +17,7 @@
 package kafka
 
 import (
+	"context"
 	"github.com/segmentio/kafka-go"
 	"net"
 	"strconv"
@@ -79,6 +80,23 @@ func InitTopic(bootstrapUrl string, topics ...string) (err error) {
 	return controllerConn.CreateTopics(topicConfigs...)
 }
 
+// Ping checks if the kafka cluster is reachable by requesting its broker list
+func Ping(ctx context.Context, bootstrapUrl string) error {
+	conn, err := kafka.DialContext(ctx, "tcp", bootstrapUrl)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = conn.Brokers()
+	return err
+}
+
 func GetBroker(bootstrapUrl string) (brokers []string, err error) {
 	return getBroker(bootstrapUrl)
 }
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+// annotateCharacteristics sets PathOption.CharacteristicMatch for options of criteria with a target_characteristic_id.
+// with filterIncompatible, incompatible options are removed, as well as services without options
+// and selectables that have no option left for one of these criteria.
+func (this *Controller) annotateCharacteristics(ctx context.Context, token string, selectables []model.Selectable, criteria model.FilterCriteriaAndSet, filterIncompatible bool) (result []model.Selectable, err error) {
+	update, required := this.getCharacteristicsUpdate(ctx, token, criteria, filterIncompatible)
+	if update == nil {
+		return selectables, nil
+	}
+	return updatePathOptions(selectables, required, update)
+}
+
+// getCharacteristicsUpdate returns a path option update for updatePathOptions or nil if no criterion has a target_characteristic_id.
+// required are the mandatory criteria whose options may be removed by the update.
+func (this *Controller) getCharacteristicsUpdate(ctx context.Context, token string, criteria model.FilterCriteriaAndSet, filterIncompatible bool) (update func(option *model.PathOption) (keep bool, err error), required model.FilterCriteriaAndSet) {
+	targeted := model.FilterCriteriaAndSet{}
+	for _, c := range criteria {
+		if c.TargetCharacteristicId != "" {
+			targeted = append(targeted, c)
+			if filterIncompatible && !c.Exclude && !c.Optional {
+				required = append(required, c)
+			}
+		}
+	}
+	if len(targeted) == 0 {
+		return nil, nil
+	}
+	aspectCache := &map[string]devicemodel.AspectNode{}
+	matches := map[string]*model.CharacteristicMatch{}
+	update = func(option *model.PathOption) (keep bool, err error) {
+		option.CharacteristicMatch, err = this.getCharacteristicMatch(ctx, token, *option, targeted, aspectCache, matches)
+		if err != nil {
+			return false, err
+		}
+		return !filterIncompatible || option.CharacteristicMatch == nil || option.CharacteristicMatch.Usability != model.CharacteristicIncompatible, nil
+	}
+	return update, required
+}
+
+// getCharacteristicMatch uses the criterion of option (see findCriterionOfPathOption); matches caches results by characteristic, function and target
+func (this *Controller) getCharacteristicMatch(ctx context.Context, token string, option model.PathOption, targeted model.FilterCriteriaAndSet, aspectCache *map[string]devicemodel.AspectNode, matches map[string]*model.CharacteristicMatch) (result *model.CharacteristicMatch, err error) {
+	criterion, err := this.findCriterionOfPathOption(ctx, token, option, targeted, aspectCache)
+	if err != nil || criterion == nil {
+		return nil, err
+	}
+	key := option.CharacteristicId + "|" + option.FunctionId + "|" + criterion.TargetCharacteristicId
+	if cached, ok := matches[key]; ok {
+		return cached, nil
+	}
+	result = &model.CharacteristicMatch{
+		TargetCharacteristicId: criterion.TargetCharacteristicId,
+		Usability:              model.CharacteristicIncompatible,
+	}
+	function, err := this.GetFunction(ctx, option.FunctionId, token)
+	if err != nil {
+		//unknown functions have no concept
+		this.config.GetLogger().Debug("getCharacteristicMatch()::unable to load function", "functionId", option.FunctionId, "error", err)
+	}
+	if function.ConceptId != "" {
+		concept, err := this.GetConcept(ctx, function.ConceptId, token)
+		if err != nil {
+			return nil, err
+		}
+		result.ConceptId = concept.Id
+		result.BaseCharacteristicId = concept.BaseCharacteristicId
+		if option.CharacteristicId != "" && listContains(concept.CharacteristicIds, option.CharacteristicId) && listContains(concept.CharacteristicIds, criterion.TargetCharacteristicId) {
+			result.Usability = model.CharacteristicConversion
+		}
+	}
+	if option.CharacteristicId != "" {
+		characteristic, err := this.GetCharacteristic(ctx, option.CharacteristicId, token)
+		if err != nil {
+			return nil, err
+		}
+		result.DisplayUnit = characteristic.DisplayUnit
+		if option.CharacteristicId == criterion.TargetCharacteristicId {
+			result.Usability = model.CharacteristicDirect
+		}
+	}
+	matches[key] = result
+	return result, nil
+}
+
+// findCriterionOfPathOption returns the first criterion with the function of option and an aspect matching option (see devicemodel.AspectMatch) or nil
+func (this *Controller) findCriterionOfPathOption(ctx context.Context, token string, option model.PathOption, criteria model.FilterCriteriaAndSet, aspectCache *map[string]devicemodel.AspectNode) (*devicemodel.FilterCriteria, error) {
+	for _, c := range criteria {
+		if c.FunctionId != option.FunctionId {
+			continue
+		}
+		if c.AspectId != "" && c.AspectId != option.AspectNode.Id {
+			aspect, err := this.getAspectNodeWithCache(ctx, token, aspectCache, c.AspectId)
+			if err != nil {
+				return nil, err
+			}
+			if !listContains(c.AspectMatch.AspectIds(aspect), option.AspectNode.Id) {
+				continue
+			}
+		}
+		return &c, nil
+	}
+	return nil, nil
+}
@@ -17,6 +17,7 @@
 package controller
 
 import (
+	"context"
 	"errors"
 
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
@@ -24,13 +25,16 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 )
 
-func (this *Controller) CompleteServices(token string, selectables []model.Selectable, filter []devicemodel.FilterCriteria) ([]model.Selectable, error) {
-	return this.completeServices(token, selectables, filter)
+func (this *Controller) CompleteServices(ctx context.Context, token string, selectables []model.Selectable, filter []devicemodel.FilterCriteria) ([]model.Selectable, error) {
+	return this.completeServices(ctx, token, selectables, filter)
 }
 
-func (this *Controller) CompleteBulkServices(token string, bulk model.BulkResult, request model.BulkRequest) (_ model.BulkResult, err error) {
+func (this *Controller) CompleteBulkServices(ctx context.Context, token string, bulk model.BulkResult, request model.BulkRequest) (_ model.BulkResult, err error) {
 	for index, element := range bulk {
-		bulk[index].Selectables, err = this.completeServices(token, element.Selectables, request[index].Criteria)
+		if err = ctx.Err(); err != nil {
+			return bulk, err
+		}
+		bulk[index].Selectables, err = this.completeServices(ctx, token, element.Selectables, request[index].Criteria)
 		if err != nil {
 			return bulk, err
 		}
@@ -38,9 +42,12 @@ func (this *Controller) CompleteBulkServices(token string, bulk model.BulkResult
 	return bulk, nil
 }
 
-func (this *Controller) CompleteBulkServicesV2(token string, bulk model.BulkResult, request model.BulkRequestV2) (_ model.BulkResult, err error) {
+func (this *Controller) CompleteBulkServicesV2(ctx context.Context, token string, bulk model.BulkResult, request model.BulkRequestV2) (_ model.BulkResult, err error) {
 	for index, element := range bulk {
-		bulk[index].Selectables, err = this.completeServices(token, element.Selectables, request[index].Criteria)
+		if err = ctx.Err(); err != nil {
+			return bulk, err
+		}
+		bulk[index].Selectables, err = this.completeServices(ctx, token, element.Selectables, request[index].Criteria)
 		if err != nil {
 			return bulk, err
 		}
@@ -48,14 +55,15 @@ func (this *Controller) CompleteBulkServicesV2(token string, bulk model.BulkResu
 	return bulk, nil
 }
 
-func (this *Controller) completeServices(token string, selectables []model.Selectable, filter []devicemodel.FilterCriteria) (_ []model.Selectable, err error) {
+func (this *Controller) completeServices(ctx context.Context, token string, selectables []model.Selectable, filter []devicemodel.FilterCriteria) (_ []model.Selectable, err error) {
+	filter, _, _ = splitExclusions(filter) //excluded imports are already removed; their criteria must not add path options
 	aspectCache := &map[string]devicemodel.AspectNode{}
 	for selectableIndex, selectable := range selectables {
 		selectable.ServicePathOptions = map[string][]model.PathOption{}
 		if selectable.Device != nil {
 			//already fully handled
 		} else if selectable.Import != nil {
-			fullType, err := this.getFullImportType(token, selectable.ImportType.Id)
+			fullType, err := this.getFullImportType(ctx, token, selectable.ImportType.Id)
 			if err != nil {
 				return nil, err
 			}
@@ -66,7 +74,7 @@ func (this *Controller) completeServices(token string, selectables []model.Selec
 				var pathCharacteristicPairs []model.PathOption
 				for _, subOutput := range fullType.Output.SubContentVariables { // root element has to be ignored to find correct path
 					var subPathCharacteristicPairs []model.PathOption
-					err = this.findPathCharacteristicPairs(&subOutput, filter, "", &subPathCharacteristicPairs, token, aspectCache)
+					err = this.findPathCharacteristicPairs(ctx, &subOutput, filter, "", &subPathCharacteristicPairs, token, aspectCache)
 					if err != nil {
 						return nil, err
 					}
@@ -80,7 +88,7 @@ func (this *Controller) completeServices(token string, selectables []model.Selec
 	return selectables, nil
 }
 
-func (this *Controller) findPathCharacteristicPairs(contentVariable basecontentvariable.Descriptor, filterCriteria []devicemodel.FilterCriteria, prefix string, res *[]model.PathOption, token string, aspectCache *map[string]devicemodel.AspectNode) (err error) {
+func (this *Controller) findPathCharacteristicPairs(ctx context.Context, contentVariable basecontentvariable.Descriptor, filterCriteria []devicemodel.FilterCriteria, prefix string, res *[]model.PathOption, token string, aspectCache *map[string]devicemodel.AspectNode) (err error) {
 	if res == nil || contentVariable == nil {
 		return errors.New("encountered nil pointer")
 	}
@@ -92,12 +100,12 @@ func (this *Controller) findPathCharacteristicPairs(contentVariable basecontentv
 	}
 	path += contentVariable.GetName()
 
-	ok, err := this.contentVariableContainsAnyCriteria(contentVariable, filterCriteria, token, aspectCache)
+	ok, err := this.contentVariableContainsAnyCriteria(ctx, contentVariable, filterCriteria, token, aspectCache)
 	if err != nil {
 		return err
 	}
 	if ok {
-		aspectNode, err := this.getAspectNodeWithCache(token, aspectCache, contentVariable.GetAspectId())
+		aspectNode, err := this.getAspectNodeWithCache(ctx, token, aspectCache, contentVariable.GetAspectId())
 		if err != nil {
 			return err
 		}
@@ -107,10 +115,11 @@ func (this *Controller) findPathCharacteristicPairs(contentVariable basecontentv
 			AspectNode:       aspectNode,
 			FunctionId:       contentVariable.GetFunctionId(),
 			IsVoid:           contentVariable.GetIsVoid(),
+			Type:             contentVariable.GetType(),
 		})
 	}
 	for _, subContentVariable := range contentVariable.GetSubContentVariables() {
-		err = this.findPathCharacteristicPairs(subContentVariable, filterCriteria, path, res, token, aspectCache)
+		err = this.findPathCharacteristicPairs(ctx, subContentVariable, filterCriteria, path, res, token, aspectCache)
 		if err != nil {
 			return
 		}
@@ -118,9 +127,9 @@ func (this *Controller) findPathCharacteristicPairs(contentVariable basecontentv
 	return
 }
 
-func (this *Controller) contentVariableContainsAnyCriteria(variable basecontentvariable.Descriptor, criteria []devicemodel.FilterCriteria, token string, aspectCache *map[string]devicemodel.AspectNode) (result bool, err error) {
+func (this *Controller) contentVariableContainsAnyCriteria(ctx context.Context, variable basecontentvariable.Descriptor, criteria []devicemodel.FilterCriteria, token string, aspectCache *map[string]devicemodel.AspectNode) (result bool, err error) {
 	for _, c := range criteria {
-		temp, err := this.contentVariableContainsCriteria(variable, c, token, aspectCache)
+		temp, err := this.contentVariableContainsCriteria(ctx, variable, c, token, aspectCache)
 		if err != nil {
 			return result, err
 		}
@@ -131,28 +140,27 @@ func (this *Controller) contentVariableContainsAnyCriteria(variable basecontentv
 	return false, nil
 }
 
-func (this *Controller) contentVariableContainsCriteria(variable basecontentvariable.Descriptor, criteria devicemodel.FilterCriteria, token string, aspectCache *map[string]devicemodel.AspectNode) (result bool, err error) {
+func (this *Controller) contentVariableContainsCriteria(ctx context.Context, variable basecontentvariable.Descriptor, criteria devicemodel.FilterCriteria, token string, aspectCache *map[string]devicemodel.AspectNode) (result bool, err error) {
 	aspectNode := devicemodel.AspectNode{}
 	if criteria.AspectId != "" {
-		aspectNode, err = this.getAspectNodeWithCache(token, aspectCache, criteria.AspectId)
+		aspectNode, err = this.getAspectNodeWithCache(ctx, token, aspectCache, criteria.AspectId)
 		if err != nil {
 			return false, err
 		}
 	}
 	if variable.GetFunctionId() == criteria.FunctionId &&
-		(criteria.AspectId == "" ||
-			variable.GetAspectId() == criteria.AspectId ||
-			listContains(aspectNode.DescendentIds, variable.GetAspectId())) {
+		criteria.AcceptsValue(variable.GetType(), variable.GetIsVoid()) &&
+		(criteria.AspectId == "" || listContains(criteria.AspectMatch.AspectIds(aspectNode), variable.GetAspectId())) {
 		return true, nil
 	}
 	return false, nil
 }
 
-func (this *Controller) getAspectNodeWithCache(token string, aspectCache *map[string]devicemodel.AspectNode, aspectId string) (aspectNode devicemodel.AspectNode, err error) {
+func (this *Controller) getAspectNodeWithCache(ctx context.Context, token string, aspectCache *map[string]devicemodel.AspectNode, aspectId string) (aspectNode devicemodel.AspectNode, err error) {
 	var ok bool
 	aspectNode, ok = (*aspectCache)[aspectId]
 	if !ok {
-		aspectNode, err = this.GetAspectNode(aspectId, token)
+		aspectNode, err = this.GetAspectNode(ctx, aspectId, token)
 		if err != nil {
 			this.config.GetLogger().Warn("unable to load aspect node", "aspectId", aspectId, "error", err)
 			return aspectNode, err
@@ -17,12 +17,21 @@
 package controller
 
 import (
+	"context"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 )
 
-func (this *Controller) GetConcept(id string, token string) (c devicemodel.Concept, err error) {
-	err = this.cache.Use(id, func() (interface{}, error) {
-		result, err, _ := this.devicerepo.GetConceptWithoutCharacteristics(id)
+func (this *Controller) GetConcept(ctx context.Context, id string, token string) (c devicemodel.Concept, err error) {
+	err = this.cache.Use(ctx, id, func(ctx context.Context) (interface{}, error) {
+		result, err, _ := this.devicerepo.GetConceptWithoutCharacteristics(ctx, id)
+		return result, err
+	}, &c)
+	return
+}
+
+func (this *Controller) GetCharacteristic(ctx context.Context, id string, token string) (c devicemodel.Characteristic, err error) {
+	err = this.cache.Use(ctx, "characteristic."+id, func(ctx context.Context) (interface{}, error) {
+		result, err, _ := this.devicerepo.GetCharacteristic(ctx, id)
 		return result, err
 	}, &c)
 	return
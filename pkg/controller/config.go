@@ -0,0 +1,41 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// GetEffectiveConfig returns the config including reloaded fields with redacted secrets; the caller must be admin
+func (this *Controller) GetEffectiveConfig(ctx context.Context, token string) (result configuration.Config, err error, code int) {
+	caller, ok := jwt.GetTokenFromContext(ctx)
+	if !ok {
+		caller, err = jwt.Parse(token)
+		if err != nil {
+			return result, err, http.StatusUnauthorized
+		}
+	}
+	if !caller.IsAdmin() {
+		return result, errors.New("only admins may read the config"), http.StatusForbidden
+	}
+	return configuration.Redacted(this.config.Effective()), nil, http.StatusOK
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+// annotateConfigurables sets PathOption.ResolvedConfigurables for options of criteria with configurables
+// and removes options whose configurables do not match these criteria, as well as selectables that have no option left for one of them.
+func (this *Controller) annotateConfigurables(ctx context.Context, token string, selectables []model.Selectable, criteria model.FilterCriteriaAndSet) (result []model.Selectable, err error) {
+	update, required := this.getConfigurablesUpdate(ctx, token, criteria)
+	if update == nil {
+		return selectables, nil
+	}
+	return updatePathOptions(selectables, required, update)
+}
+
+// getConfigurablesUpdate returns a path option update for updatePathOptions or nil if no criterion has configurables.
+// required are the mandatory criteria whose options may be removed by the update.
+func (this *Controller) getConfigurablesUpdate(ctx context.Context, token string, criteria model.FilterCriteriaAndSet) (update func(option *model.PathOption) (keep bool, err error), required model.FilterCriteriaAndSet) {
+	constrained := model.FilterCriteriaAndSet{}
+	for _, c := range criteria {
+		if c.Configurables != nil {
+			constrained = append(constrained, c)
+			if !c.Exclude && !c.Optional {
+				required = append(required, c)
+			}
+		}
+	}
+	if len(constrained) == 0 {
+		return nil, nil
+	}
+	aspectCache := &map[string]devicemodel.AspectNode{}
+	update = func(option *model.PathOption) (keep bool, err error) {
+		criterion, err := this.findCriterionOfPathOption(ctx, token, *option, constrained, aspectCache)
+		if err != nil || criterion == nil {
+			return true, err
+		}
+		option.ResolvedConfigurables, err = this.resolveConfigurables(ctx, token, option.Configurables)
+		if err != nil {
+			return false, err
+		}
+		return configurablesMatch(*criterion.Configurables, option.ResolvedConfigurables), nil
+	}
+	return update, required
+}
+
+func (this *Controller) resolveConfigurables(ctx context.Context, token string, configurables []devicemodel.Configurable) (result []model.ResolvedConfigurable, err error) {
+	for _, configurable := range configurables {
+		resolved := model.ResolvedConfigurable{Configurable: configurable}
+		if configurable.CharacteristicId != "" {
+			characteristic, err := this.GetCharacteristic(ctx, configurable.CharacteristicId, token)
+			if err != nil {
+				return nil, err
+			}
+			resolved.Characteristic = &characteristic
+		}
+		result = append(result, resolved)
+	}
+	return result, nil
+}
+
+func configurablesMatch(criteria devicemodel.ConfigurableCriteria, configurables []model.ResolvedConfigurable) bool {
+	if criteria.None && len(configurables) > 0 {
+		return false
+	}
+	for _, filter := range criteria.Require {
+		if !anyConfigurableMatches(filter, configurables) {
+			return false
+		}
+	}
+	for _, filter := range criteria.Exclude {
+		if anyConfigurableMatches(filter, configurables) {
+			return false
+		}
+	}
+	return true
+}
+
+func anyConfigurableMatches(filter devicemodel.ConfigurableFilter, configurables []model.ResolvedConfigurable) bool {
+	for _, configurable := range configurables {
+		if configurableMatches(filter, configurable) {
+			return true
+		}
+	}
+	return false
+}
+
+func configurableMatches(filter devicemodel.ConfigurableFilter, configurable model.ResolvedConfigurable) bool {
+	if filter.Path != "" && filter.Path != configurable.Path {
+		return false
+	}
+	if filter.CharacteristicId != "" && filter.CharacteristicId != configurable.CharacteristicId {
+		return false
+	}
+	if filter.Accepts != nil && !configurableAccepts(configurable, model.NormalizeJson(filter.Accepts)) {
+		return false
+	}
+	return true
+}
+
+// configurableAccepts checks value (decoded from json) against the type of configurable and the min/max values of its characteristic
+func configurableAccepts(configurable model.ResolvedConfigurable, value interface{}) bool {
+	number, isNumber := value.(float64)
+	switch configurable.Type {
+	case devicemodel.Float:
+		if !isNumber {
+			return false
+		}
+	case devicemodel.Integer:
+		if !isNumber || number != float64(int64(number)) {
+			return false
+		}
+	case devicemodel.String:
+		if _, ok := value.(string); !ok {
+			return false
+		}
+	case devicemodel.Boolean:
+		if _, ok := value.(bool); !ok {
+			return false
+		}
+	case devicemodel.List:
+		if _, ok := value.([]interface{}); !ok {
+			return false
+		}
+	case devicemodel.Structure:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	if isNumber && configurable.Characteristic != nil {
+		if minValue, ok := model.NormalizeJson(configurable.Characteristic.MinValue).(float64); ok && number < minValue {
+			return false
+		}
+		if maxValue, ok := model.NormalizeJson(configurable.Characteristic.MaxValue).(float64); ok && number > maxValue {
+			return false
+		}
+	}
+	return true
+}
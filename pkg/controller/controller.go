@@ -25,56 +25,217 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller/cache"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller/cacheinvalidator"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/health"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller/idmodifier"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/upstream"
+	"github.com/SENERGY-Platform/device-selection/pkg/limits"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
 	importrepo "github.com/SENERGY-Platform/import-repository/lib/client"
 	"github.com/SENERGY-Platform/models/go/models"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type Controller struct {
-	config     configuration.Config
-	cache      cache.Cache
-	devicerepo client.Interface
-	importrepo importrepo.Interface
+	config       configuration.Config
+	cache        cache.Cache
+	devicerepo   upstream.DeviceRepository
+	importrepo   upstream.ImportRepository
+	importdeploy upstream.ImportDeploy
+
+	health *health.Checker
+
+	tokenExchange *auth.TokenExchange //nil if on behalf of requests are not configured
 }
 
 func New(ctx context.Context, config configuration.Config) (*Controller, error) {
-	c := cache.New(config.MemcachedUrls)
-	if config.KafkaUrl != "" && config.KafkaConsumerGroup != "" && len(config.KafkaTopicsForCacheInvalidation) > 0 {
+	return NewWithOptions(ctx, config)
+}
+
+// dependencies replace the upstream clients, the cache and the cache invalidation that New creates from the config; nil fields are created from config
+type dependencies struct {
+	DeviceRepository   client.Interface
+	ImportRepository   importrepo.Interface
+	ImportDeploy       upstream.ImportDeploy
+	Cache              cache.Cache
+	InvalidationSource cacheinvalidator.Source
+}
+
+// Option replaces a dependency that New would create from the config
+type Option func(deps *dependencies)
+
+func WithDeviceRepository(repo client.Interface) Option {
+	return func(deps *dependencies) {
+		deps.DeviceRepository = repo
+	}
+}
+
+func WithImportRepository(repo importrepo.Interface) Option {
+	return func(deps *dependencies) {
+		deps.ImportRepository = repo
+	}
+}
+
+// WithImportDeploy replaces the import-deploy client (e.g. with mock.ImportDeploy of pkg/tests/environment); retries and import_deploy_cache_expiration are still applied
+func WithImportDeploy(deploy upstream.ImportDeploy) Option {
+	return func(deps *dependencies) {
+		deps.ImportDeploy = deploy
+	}
+}
+
+// WithCache replaces the memcached/local cache; cache_expiration is still applied
+func WithCache(c cache.Cache) Option {
+	return func(deps *dependencies) {
+		deps.Cache = c
+	}
+}
+
+// WithInvalidationSource replaces the kafka consumers that invalidate the cache
+func WithInvalidationSource(source cacheinvalidator.Source) Option {
+	return func(deps *dependencies) {
+		deps.InvalidationSource = source
+	}
+}
+
+// NewWithOptions works like New, but uses the dependencies set by options instead of creating them from config.
+// injected dependencies are not probed by the readiness check.
+func NewWithOptions(ctx context.Context, config configuration.Config, options ...Option) (*Controller, error) {
+	deps := dependencies{}
+	for _, option := range options {
+		option(&deps)
+	}
+	injected := deps
+	if deps.DeviceRepository == nil {
+		deps.DeviceRepository = client.NewClient(config.DeviceRepoUrl, nil)
+	}
+	if deps.ImportRepository == nil {
+		deps.ImportRepository = importrepo.NewClient(config.ImportRepoUrl)
+	}
+	if deps.Cache == nil {
+		deps.Cache = cache.New(config.MemcachedUrls)
+	}
+	c := deps.Cache
+	cacheExpiration, err := parseDurationOrDefault(config.CacheExpiration, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache_expiration: %w", err)
+	}
+	if cacheExpiration > 0 {
+		c.SetExpiration(cacheExpiration)
+	}
+	deviceRepoTimeout, err := parseDurationOrDefault(config.DeviceRepoTimeout, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device_repo_timeout: %w", err)
+	}
+	importDeployTimeout, err := parseDurationOrDefault(config.ImportDeployTimeout, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import_deploy_timeout: %w", err)
+	}
+	importRepoTimeout, err := parseDurationOrDefault(config.ImportRepoTimeout, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import_repo_timeout: %w", err)
+	}
+	importDeployCacheExpiration, err := parseDurationOrDefault(config.ImportDeployCacheExpiration, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import_deploy_cache_expiration: %w", err)
+	}
+	resiliencePolicy, err := newResiliencePolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Transport: tracing.NewTransport(http.DefaultTransport)}
+	if deps.ImportDeploy == nil {
+		deps.ImportDeploy = upstream.NewImportDeploy(httpClient, config.ImportDeployUrl, importDeployTimeout, config.ImportDeployPageSize, config.ImportDeployMaxPages)
+	}
+	healthChecker, err := newHealthChecker(config, c, httpClient, injected)
+	if err != nil {
+		return nil, err
+	}
+	var tokenExchange *auth.TokenExchange
+	if config.AuthEndpoint != "" && config.AuthClientId != "" {
+		tokenExchange = auth.NewTokenExchange(config.AuthEndpoint, config.AuthClientId, config.AuthClientSecret)
+	}
+
+	//everything that may fail is done; started listeners would not be stopped on error
+	if deps.InvalidationSource == nil && useCacheInvalidation(config) {
 		config.GetLogger().Info("start listeners to invalidate cache on kafka message", "topics", config.KafkaTopicsForCacheInvalidation)
-		err := cacheinvalidator.StartCacheInvalidator(ctx, config, c)
+		deps.InvalidationSource = cacheinvalidator.NewKafkaSource(config)
+	}
+	if deps.InvalidationSource != nil {
+		err = deps.InvalidationSource.Start(ctx, c.Invalidate)
 		if err != nil {
 			return nil, err
 		}
 	}
+	config.OnReload(func(config configuration.Config) {
+		if expiration, err := parseDurationOrDefault(config.CacheExpiration, 0); err == nil && expiration > 0 {
+			c.SetExpiration(expiration)
+		}
+	})
 	return &Controller{
-		config:     config,
-		cache:      c,
-		devicerepo: client.NewClient(config.DeviceRepoUrl, nil),
-		importrepo: importrepo.NewClient(config.ImportRepoUrl),
+		config: config,
+		cache:  c,
+		devicerepo: upstream.NewResilientDeviceRepository(
+			upstream.NewDeviceRepository(deps.DeviceRepository, deviceRepoTimeout, upstream.NewAbandonedCalls(config.UpstreamMaxAbandonedCalls)),
+			upstream.NewResilience(upstream.DeviceRepositoryName, resiliencePolicy),
+		),
+		importrepo: upstream.NewResilientImportRepository(
+			upstream.NewImportRepository(deps.ImportRepository, importRepoTimeout, upstream.NewAbandonedCalls(config.UpstreamMaxAbandonedCalls)),
+			upstream.NewResilience(upstream.ImportRepositoryName, resiliencePolicy),
+		),
+		importdeploy: upstream.NewCachedImportDeploy(
+			upstream.NewResilientImportDeploy(
+				deps.ImportDeploy,
+				upstream.NewResilience(upstream.ImportDeployName, resiliencePolicy),
+			),
+			importDeployCacheExpiration,
+		),
+		health:        healthChecker,
+		tokenExchange: tokenExchange,
 	}, nil
 }
 
-func (this *Controller) GetFilteredDevices(token string, descriptions model.FilterCriteriaAndSet, protocolBlockList []string, blockedInteraction devicemodel.Interaction, includeGroups bool, includeImports bool, withLocalDeviceIds []string) (result []model.Selectable, err error, code int) {
-	return this.getFilteredDevices(token, descriptions, protocolBlockList, blockedInteraction, nil, includeGroups, includeImports, withLocalDeviceIds)
+func parseDurationOrDefault(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func useCacheInvalidation(config configuration.Config) bool {
+	return config.KafkaUrl != "" && config.KafkaConsumerGroup != "" && len(config.KafkaTopicsForCacheInvalidation) > 0
+}
+
+func (this *Controller) GetFilteredDevices(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, protocolBlockList []string, blockedInteraction devicemodel.Interaction, includeGroups bool, includeImports bool, withLocalDeviceIds []string) (result []model.Selectable, err error, code int) {
+	return this.getFilteredDevices(ctx, token, descriptions, protocolBlockList, blockedInteraction, nil, includeGroups, includeImports, withLocalDeviceIds)
 }
 
 type GetFilteredDevicesV2Options = model.GetFilteredDevicesV2Options
 
-func (this *Controller) GetFilteredDevicesV2(token string, options GetFilteredDevicesV2Options) (result []model.Selectable, err error, code int) {
-	return this.getFilteredDevicesV2(token, options, nil)
+func (this *Controller) GetFilteredDevicesV2(ctx context.Context, token string, options GetFilteredDevicesV2Options) (result []model.Selectable, err error, code int) {
+	return this.getFilteredDevicesV2(ctx, token, options, nil)
 }
 
-func (this *Controller) BulkGetFilteredDevices(token string, requests model.BulkRequest) (result model.BulkResult, err error, code int) {
+func (this *Controller) BulkGetFilteredDevices(ctx context.Context, token string, requests model.BulkRequest) (result model.BulkResult, err error, code int) {
+	if err = limits.FromContext(ctx).CheckBulkSize(len(requests)); err != nil {
+		return result, err, limits.StatusCode
+	}
 	devicesByDeviceTypeCache := map[string][]model.PermSearchDevice{}
 	for _, request := range requests {
-		resultElement, err, code := this.handleBulkRequestElement(token, request, &devicesByDeviceTypeCache)
+		if err = ctx.Err(); err != nil {
+			return result, err, upstream.ContextErrorCode(err)
+		}
+		elementCtx, span := tracing.Start(ctx, "bulk element", attribute.String("bulk.element.id", request.Id))
+		resultElement, err, code := this.handleBulkRequestElement(elementCtx, token, request, &devicesByDeviceTypeCache)
+		span.SetAttributes(attribute.Int("bulk.element.selectables", len(resultElement.Selectables)))
+		tracing.Finish(span, err)
 		if err != nil {
 			return result, err, code
 		}
@@ -83,10 +244,19 @@ func (this *Controller) BulkGetFilteredDevices(token string, requests model.Bulk
 	return result, nil, http.StatusOK
 }
 
-func (this *Controller) BulkGetFilteredDevicesV2(token string, requests model.BulkRequestV2) (result model.BulkResult, err error, code int) {
+func (this *Controller) BulkGetFilteredDevicesV2(ctx context.Context, token string, requests model.BulkRequestV2) (result model.BulkResult, err error, code int) {
+	if err = limits.FromContext(ctx).CheckBulkSize(len(requests)); err != nil {
+		return result, err, limits.StatusCode
+	}
 	devicesByDeviceTypeCache := map[string][]models.ExtendedDevice{}
 	for _, request := range requests {
-		resultElement, err, code := this.handleBulkRequestElementV2(token, request, &devicesByDeviceTypeCache)
+		if err = ctx.Err(); err != nil {
+			return result, err, upstream.ContextErrorCode(err)
+		}
+		elementCtx, span := tracing.Start(ctx, "bulk element", attribute.String("bulk.element.id", request.Id))
+		resultElement, err, code := this.handleBulkRequestElementV2(elementCtx, token, request, &devicesByDeviceTypeCache)
+		span.SetAttributes(attribute.Int("bulk.element.selectables", len(resultElement.Selectables)))
+		tracing.Finish(span, err)
 		if err != nil {
 			return result, err, code
 		}
@@ -96,6 +266,7 @@ func (this *Controller) BulkGetFilteredDevicesV2(token string, requests model.Bu
 }
 
 func (this *Controller) handleBulkRequestElement(
+	ctx context.Context,
 	token string,
 	request model.BulkRequestElement,
 	devicesByDeviceTypeCache *map[string][]model.PermSearchDevice,
@@ -111,7 +282,7 @@ func (this *Controller) handleBulkRequestElement(
 	}
 
 	protocolBlockList := request.FilterProtocols
-	selectables, err, code := this.getFilteredDevices(token, request.Criteria, protocolBlockList, blockedInteraction, devicesByDeviceTypeCache, request.IncludeGroups, request.IncludeImports, request.LocalDevices)
+	selectables, err, code := this.getFilteredDevices(ctx, token, request.Criteria, protocolBlockList, blockedInteraction, devicesByDeviceTypeCache, request.IncludeGroups, request.IncludeImports, request.LocalDevices)
 	if err != nil {
 		return result, err, code
 	}
@@ -122,6 +293,7 @@ func (this *Controller) handleBulkRequestElement(
 }
 
 func (this *Controller) handleBulkRequestElementV2(
+	ctx context.Context,
 	token string,
 	request model.BulkRequestElementV2,
 	devicesByDeviceTypeCache *map[string][]models.ExtendedDevice,
@@ -130,7 +302,7 @@ func (this *Controller) handleBulkRequestElementV2(
 	err error,
 	code int,
 ) {
-	selectables, err, code := this.getFilteredDevicesV2(
+	selectables, err, code := this.getFilteredDevicesV2(ctx,
 		token,
 		GetFilteredDevicesV2Options{
 			FilterCriteria:              request.Criteria,
@@ -143,6 +315,10 @@ func (this *Controller) handleBulkRequestElementV2(
 			LocalDeviceOwner:            request.LocalDeviceOwner,
 			FilterByDeviceAttributeKeys: request.FilterByDeviceAttributeKeys,
 			ImportPathTrimFirstElement:  request.ImportPathTrimFirstElement,
+			ImportFilter:                request.ImportFilter,
+
+			FilterIncompatibleCharacteristics: request.FilterIncompatibleCharacteristics,
+			PreferredInteraction:              request.PreferredInteraction,
 		},
 		devicesByDeviceTypeCache,
 	)
@@ -156,6 +332,7 @@ func (this *Controller) handleBulkRequestElementV2(
 }
 
 func (this *Controller) getFilteredDevices(
+	ctx context.Context,
 	token string,
 	descriptions model.FilterCriteriaAndSet,
 	protocolBlockList []string,
@@ -169,15 +346,30 @@ func (this *Controller) getFilteredDevices(
 	err error,
 	code int,
 ) {
+	if err = validateCriteria(descriptions); err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	if slices.ContainsFunc(descriptions, func(c devicemodel.FilterCriteria) bool { return c.Optional }) {
+		return result, errors.New("optional criteria are only supported by v2 requests"), http.StatusBadRequest
+	}
+	descriptions, exclusions, err := splitExclusions(descriptions)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	aspectCache := &map[string]devicemodel.AspectNode{}
 	filteredProtocols := map[string]bool{}
 	for _, protocolId := range protocolBlockList {
 		filteredProtocols[protocolId] = true
 	}
 
-	deviceTypeSelectables, err := this.GetDeviceTypeSelectablesCached(token, descriptions)
+	deviceTypeSelectables, err := this.GetDeviceTypeSelectablesCached(ctx, token, descriptions)
 	if err != nil {
 		return result, err, code
 	}
+	deviceTypeSelectables, err = this.removeExcludedDeviceTypes(ctx, token, deviceTypeSelectables, exclusions, aspectCache)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
 	for _, dtSelectable := range deviceTypeSelectables {
 		servicesProtocolBlock := map[string]bool{}
 		servicesBlockedByInteraction := map[string]bool{}
@@ -189,7 +381,7 @@ func (this *Controller) getFilteredDevices(
 				servicesProtocolBlock[service.Id] = true
 			}
 		}
-		pathOptions := getServicePathOptionsFromDeviceRepoResult(dtSelectable.ServicePathOptions, servicesProtocolBlock, servicesBlockedByInteraction)
+		pathOptions := getServicePathOptionsFromDeviceRepoResult(dtSelectable.ServicePathOptions, servicesProtocolBlock, servicesBlockedByInteraction, descriptions)
 		usedServices := []devicemodel.Service{}
 		for serviceId, _ := range pathOptions {
 			for _, service := range dtSelectable.Services {
@@ -199,12 +391,12 @@ func (this *Controller) getFilteredDevices(
 				}
 			}
 		}
-		if len(usedServices) > 0 {
+		if len(usedServices) > 0 && pathOptionsMatchLocalCriteria(descriptions, pathOptions) {
 			var devices []model.PermSearchDevice
 			if len(withLocalDeviceIds) == 0 {
-				devices, err, code = this.getCachedDevicesOfType(token, dtSelectable.DeviceTypeId, devicesByDeviceTypeCache)
+				devices, err, code = this.getCachedDevicesOfType(ctx, token, dtSelectable.DeviceTypeId, devicesByDeviceTypeCache)
 			} else {
-				devices, err, code = this.getCachedDevicesOfTypeFilteredByLocalIdList(token, dtSelectable.DeviceTypeId, devicesByDeviceTypeCache, withLocalDeviceIds)
+				devices, err, code = this.getCachedDevicesOfTypeFilteredByLocalIdList(ctx, token, dtSelectable.DeviceTypeId, devicesByDeviceTypeCache, withLocalDeviceIds)
 			}
 			if err != nil {
 				return result, err, code
@@ -232,15 +424,15 @@ func (this *Controller) getFilteredDevices(
 		expectedInteraction = ""
 	}
 	if includeGroups {
-		groupResult, err, code := this.getFilteredDeviceGroups(token, descriptions, expectedInteraction)
+		groupResult, err, code := this.getFilteredDeviceGroups(ctx, token, descriptions, exclusions, expectedInteraction)
 		if err != nil {
 			return result, err, code
 		}
 		result = append(result, groupResult...)
 	}
-	if includeImports && (expectedInteraction == devicemodel.EVENT || expectedInteraction == "") {
+	if includeImports && (expectedInteraction == devicemodel.EVENT || expectedInteraction == "") && !criteriaNeedDeviceClass(descriptions) {
 		this.config.GetLogger().Debug("GetFilteredDevices() Loading matching imports")
-		importResult, err, code := this.getFilteredImports(token, descriptions)
+		importResult, err, code := this.getFilteredImports(ctx, token, descriptions, exclusions)
 		if err != nil {
 			return result, err, code
 		}
@@ -248,11 +440,20 @@ func (this *Controller) getFilteredDevices(
 	} else {
 		this.config.GetLogger().Debug("GetFilteredDevices() Not loading imports")
 	}
+	result, err = this.annotateCharacteristics(ctx, token, result, descriptions, false)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	result, err = this.annotateConfigurables(ctx, token, result, descriptions)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
 	this.config.GetLogger().Debug("GetFilteredDevices()", "result", result)
 	return result, nil, 200
 }
 
 func (this *Controller) getFilteredDevicesV2(
+	ctx context.Context,
 	token string,
 	options GetFilteredDevicesV2Options,
 	devicesByDeviceTypeCache *map[string][]models.ExtendedDevice,
@@ -262,31 +463,43 @@ func (this *Controller) getFilteredDevicesV2(
 	code int,
 ) {
 	this.config.GetLogger().Debug("getFilteredDevicesV2() inputs", "options", fmt.Sprintf("%+v", options))
+	if rankingRequested(options) {
+		return this.getRankedFilteredDevicesV2(ctx, token, options, devicesByDeviceTypeCache)
+	}
+	if err = validateCriteria(options.FilterCriteria); err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	if options.ImportFilter != nil {
+		if err = options.ImportFilter.Validate(); err != nil {
+			return result, err, http.StatusBadRequest
+		}
+	}
+	criteria, exclusions, err := splitExclusions(options.FilterCriteria)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	options.FilterCriteria = criteria
+	aspectCache := &map[string]devicemodel.AspectNode{}
 	if options.IncludeDevices {
-		deviceTypeSelectables, err := this.GetDeviceTypeSelectablesCachedV2(token, options.FilterCriteria, options.IncludeIdModified)
+		deviceTypeSelectables, err := this.GetDeviceTypeSelectablesCachedV2(ctx, token, options.FilterCriteria, options.IncludeIdModified)
 		if err != nil {
 			return result, err, 500
 		}
+		deviceTypeSelectables, err = this.removeExcludedDeviceTypes(ctx, token, deviceTypeSelectables, exclusions, aspectCache)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
 		this.config.GetLogger().Debug("getFilteredDevicesV2()::GetDeviceTypeSelectablesCachedV2()", "deviceTypeSelectables_count", len(deviceTypeSelectables))
 
-		devicesByDeviceType, err, code := this.getDevicesOfDeviceTypeSelectables(token, devicesByDeviceTypeCache, deviceTypeSelectables, options.WithDeviceIds, options.WithLocalDeviceIds, options.LocalDeviceOwner, options.FilterByDeviceAttributeKeys)
+		devicesByDeviceType, err, code := this.getDevicesOfDeviceTypeSelectables(ctx, token, devicesByDeviceTypeCache, deviceTypeSelectables, options.WithDeviceIds, options.WithLocalDeviceIds, options.LocalDeviceOwner, options.FilterByDeviceAttributeKeys)
 		if err != nil {
 			return result, err, code
 		}
 
 		//collect selectables
 		for _, dtSelectable := range deviceTypeSelectables {
-			pathOptions := getServicePathOptionsFromDeviceRepoResultV2(dtSelectable.ServicePathOptions)
-			usedServices := []devicemodel.Service{}
-			for serviceId, _ := range pathOptions {
-				for _, service := range dtSelectable.Services {
-					if serviceId == service.Id {
-						usedServices = append(usedServices, service)
-						break
-					}
-				}
-			}
-			if len(usedServices) > 0 {
+			usedServices, pathOptions, ok := getDeviceTypeSelectableServices(dtSelectable, options.FilterCriteria)
+			if ok {
 				devices := devicesByDeviceType[dtSelectable.DeviceTypeId]
 				sort.Slice(devices, func(i, j int) bool {
 					nameI := devices[i].DisplayName
@@ -322,19 +535,27 @@ func (this *Controller) getFilteredDevicesV2(
 		}
 	}
 	if options.IncludeGroups {
-		groupResult, err, code := this.getFilteredDeviceGroupsV2(token, options.FilterCriteria)
+		groupResult, err, code := this.getFilteredDeviceGroupsV2(ctx, token, options.FilterCriteria, exclusions)
 		if err != nil {
 			return result, err, code
 		}
 		result = append(result, groupResult...)
 	}
-	if options.IncludeImports && !criteriaContainRequestInteraction(options.FilterCriteria) {
-		importResult, err, code := this.getFilteredImportsV2(token, options.FilterCriteria, options.ImportPathTrimFirstElement)
+	if options.IncludeImports && !criteriaContainRequestInteraction(options.FilterCriteria) && !criteriaNeedDeviceClass(options.FilterCriteria) {
+		importResult, err, code := this.getFilteredImportsV2(ctx, token, options.FilterCriteria, exclusions, options.ImportPathTrimFirstElement, options.ImportFilter)
 		if err != nil {
 			return result, err, code
 		}
 		result = append(result, importResult...)
 	}
+	result, err = this.annotateCharacteristics(ctx, token, result, options.FilterCriteria, options.FilterIncompatibleCharacteristics)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	result, err = this.annotateConfigurables(ctx, token, result, options.FilterCriteria)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
 	this.config.GetLogger().Debug("getFilteredDevicesV2()", "result", result)
 
 	for i, e := range result {
@@ -347,7 +568,7 @@ func (this *Controller) getFilteredDevicesV2(
 	return result, nil, http.StatusOK
 }
 
-func (this *Controller) getDevicesOfDeviceTypeSelectables(token string, devicesByDeviceTypeCache *map[string][]models.ExtendedDevice, deviceTypeSelectables []devicemodel.DeviceTypeSelectable, withDeviceIds []string, withLocalDeviceIds []string, owner string, filterByDeviceAttributeKeys []string) (devicesByDeviceType map[string][]models.ExtendedDevice, err error, code int) {
+func (this *Controller) getDevicesOfDeviceTypeSelectables(ctx context.Context, token string, devicesByDeviceTypeCache *map[string][]models.ExtendedDevice, deviceTypeSelectables []devicemodel.DeviceTypeSelectable, withDeviceIds []string, withLocalDeviceIds []string, owner string, filterByDeviceAttributeKeys []string) (devicesByDeviceType map[string][]models.ExtendedDevice, err error, code int) {
 	if devicesByDeviceTypeCache == nil {
 		devicesByDeviceTypeCache = &map[string][]models.ExtendedDevice{}
 	}
@@ -369,7 +590,7 @@ func (this *Controller) getDevicesOfDeviceTypeSelectables(token string, devicesB
 	//find matching devices
 	matchingDevices := []models.ExtendedDevice{}
 	if len(dtList) > 0 {
-		matchingDevices, _, err, code = this.devicerepo.ListExtendedDevices(token, client.ExtendedDeviceListOptions{
+		matchingDevices, _, err, code = this.devicerepo.ListExtendedDevices(ctx, token, client.ExtendedDeviceListOptions{
 			DeviceTypeIds: dtList,
 			Ids:           withDeviceIds,
 			LocalIds:      withLocalDeviceIds,
@@ -404,7 +625,7 @@ func (this *Controller) getDevicesOfDeviceTypeSelectables(token string, devicesB
 
 		}
 	}
-	modefiedDevices, _, err, code = this.devicerepo.ListExtendedDevices(token, client.ExtendedDeviceListOptions{
+	modefiedDevices, _, err, code = this.devicerepo.ListExtendedDevices(ctx, token, client.ExtendedDeviceListOptions{
 		Ids:        devicesToModefy,
 		Limit:      1000,
 		Offset:     0,
@@ -426,12 +647,15 @@ func criteriaContainRequestInteraction(criteria model.FilterCriteriaAndSet) bool
 	return false
 }
 
-func getServicePathOptionsFromDeviceRepoResult(in map[string][]devicemodel.ServicePathOption, serviceBlocketByProtocolIndex map[string]bool, serviceBlocketByInteractionIndex map[string]bool) (out map[string][]model.PathOption) {
+func getServicePathOptionsFromDeviceRepoResult(in map[string][]devicemodel.ServicePathOption, serviceBlocketByProtocolIndex map[string]bool, serviceBlocketByInteractionIndex map[string]bool, criteria model.FilterCriteriaAndSet) (out map[string][]model.PathOption) {
 	out = map[string][]model.PathOption{}
 	for serviceId, list := range in {
 		if !serviceBlocketByInteractionIndex[serviceId] {
 			temp := []model.PathOption{}
 			for _, element := range list {
+				if !acceptsPathOption(criteria, element) {
+					continue
+				}
 				if !(isMeasuringFunctionId(element.FunctionId) && serviceBlocketByProtocolIndex[serviceId]) { //legacy check; should be covered by interaction check
 					temp = append(temp, model.PathOption{
 						Path:             element.Path,
@@ -453,11 +677,29 @@ func getServicePathOptionsFromDeviceRepoResult(in map[string][]devicemodel.Servi
 	return out
 }
 
-func getServicePathOptionsFromDeviceRepoResultV2(in map[string][]devicemodel.ServicePathOption) (out map[string][]model.PathOption) {
+// getDeviceTypeSelectableServices returns the services and path options of dtSelectable accepted by criteria; ok is false if no service remains
+func getDeviceTypeSelectableServices(dtSelectable devicemodel.DeviceTypeSelectable, criteria model.FilterCriteriaAndSet) (usedServices []devicemodel.Service, pathOptions map[string][]model.PathOption, ok bool) {
+	pathOptions = getServicePathOptionsFromDeviceRepoResultV2(dtSelectable.ServicePathOptions, criteria)
+	usedServices = []devicemodel.Service{}
+	for serviceId, _ := range pathOptions {
+		for _, service := range dtSelectable.Services {
+			if serviceId == service.Id {
+				usedServices = append(usedServices, service)
+				break
+			}
+		}
+	}
+	return usedServices, pathOptions, len(usedServices) > 0 && pathOptionsMatchLocalCriteria(criteria, pathOptions)
+}
+
+func getServicePathOptionsFromDeviceRepoResultV2(in map[string][]devicemodel.ServicePathOption, criteria model.FilterCriteriaAndSet) (out map[string][]model.PathOption) {
 	out = map[string][]model.PathOption{}
 	for serviceId, list := range in {
 		temp := []model.PathOption{}
 		for _, element := range list {
+			if !acceptsPathOption(criteria, element) {
+				continue
+			}
 			temp = append(temp, model.PathOption{
 				Path:             element.Path,
 				CharacteristicId: element.CharacteristicId,
@@ -477,6 +719,64 @@ func getServicePathOptionsFromDeviceRepoResultV2(in map[string][]devicemodel.Ser
 	return out
 }
 
+// acceptsPathOption is true if no criterion has value or aspect constraints or option matches a criterion including its constraints
+func acceptsPathOption(criteria model.FilterCriteriaAndSet, option devicemodel.ServicePathOption) bool {
+	if !slices.ContainsFunc(criteria, hasLocalConstraints) {
+		return true
+	}
+	for _, c := range criteria {
+		if c.MatchesPathOption(option.FunctionId, option.AspectNode, option.Type, option.IsVoid) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLocalConstraints(criteria devicemodel.FilterCriteria) bool {
+	return criteria.HasValueConstraints() || criteria.HasAspectConstraints()
+}
+
+// pathOptionsMatchLocalCriteria checks that every criterion with value or aspect constraints still has a path option;
+// acceptsPathOption removes options of such criteria and the device-repository was queried with a wider aspect (see devicemodel.AspectMatch.UpstreamAspectId)
+func pathOptionsMatchLocalCriteria(criteria model.FilterCriteriaAndSet, pathOptions map[string][]model.PathOption) bool {
+	for _, c := range criteria {
+		if hasLocalConstraints(c) && !pathOptionsMatchCriterion(c, pathOptions) {
+			return false
+		}
+	}
+	return true
+}
+
+// pathOptionsMatchCriteria checks that every criterion still has a path option after options were removed (see updatePathOptions)
+func pathOptionsMatchCriteria(criteria model.FilterCriteriaAndSet, pathOptions map[string][]model.PathOption) bool {
+	for _, c := range criteria {
+		if !pathOptionsMatchCriterion(c, pathOptions) {
+			return false
+		}
+	}
+	return true
+}
+
+func pathOptionsMatchCriterion(criterion devicemodel.FilterCriteria, pathOptions map[string][]model.PathOption) bool {
+	for _, options := range pathOptions {
+		if slices.ContainsFunc(options, func(option model.PathOption) bool {
+			return criterion.MatchesPathOption(option.FunctionId, option.AspectNode, option.Type, option.IsVoid)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateCriteria(criteria model.FilterCriteriaAndSet) error {
+	for _, c := range criteria {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (this *Controller) CombinedDevices(bulk model.BulkResult) (result []model.PermSearchDevice) {
 	seen := map[string]bool{}
 	for _, bulkElement := range bulk {
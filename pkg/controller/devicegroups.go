@@ -17,19 +17,27 @@
 package controller
 
 import (
+	"context"
+	"net/http"
+	"slices"
+
 	"github.com/SENERGY-Platform/device-repository/lib/client"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 	"github.com/SENERGY-Platform/models/go/models"
 )
 
-func (this *Controller) getFilteredDeviceGroups(token string, descriptions model.FilterCriteriaAndSet, expectedInteraction devicemodel.Interaction) (result []model.Selectable, err error, code int) {
+func (this *Controller) getFilteredDeviceGroups(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, exclusions model.FilterCriteriaAndSet, expectedInteraction devicemodel.Interaction) (result []model.Selectable, err error, code int) {
 	criteriaList := []client.FilterCriteria{}
 	for _, c := range descriptions {
+		aspectId, err := this.getUpstreamAspectId(ctx, token, c)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
 		criteria := client.FilterCriteria{
 			FunctionId:    c.FunctionId,
 			DeviceClassId: c.DeviceClassId,
-			AspectId:      c.AspectId,
+			AspectId:      aspectId,
 		}
 		if expectedInteraction != "" {
 			criteria.Interaction = expectedInteraction
@@ -37,7 +45,7 @@ func (this *Controller) getFilteredDeviceGroups(token string, descriptions model
 		criteriaList = append(criteriaList, criteria)
 	}
 
-	groups, _, err, code := this.devicerepo.ListDeviceGroups(token, client.DeviceGroupListOptions{
+	groups, _, err, code := this.devicerepo.ListDeviceGroups(ctx, token, client.DeviceGroupListOptions{
 		Ids:             nil,
 		Limit:           1000,
 		SortBy:          "name.asc",
@@ -48,7 +56,30 @@ func (this *Controller) getFilteredDeviceGroups(token string, descriptions model
 	if err != nil {
 		return result, err, code
 	}
+	aspectCache := &map[string]devicemodel.AspectNode{}
+	deviceTypeCache := &map[string]devicemodel.DeviceType{}
 	for _, group := range groups {
+		ok, err := this.deviceGroupMatchesAspectCriteria(ctx, token, group, descriptions, criteriaList, aspectCache)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		if !ok {
+			continue
+		}
+		ok, err, code = this.deviceGroupMatchesValueCriteria(ctx, token, group, descriptions, deviceTypeCache, aspectCache)
+		if err != nil {
+			return result, err, code
+		}
+		if !ok {
+			continue
+		}
+		excluded, err := this.deviceGroupMatchesAnyExclusion(ctx, token, group, exclusions, aspectCache)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		if excluded {
+			continue
+		}
 		temp := group //prevent that every result element becomes the last element of groups
 		result = append(result, model.Selectable{DeviceGroup: &model.DeviceGroup{
 			Id:   temp.Id,
@@ -58,23 +89,27 @@ func (this *Controller) getFilteredDeviceGroups(token string, descriptions model
 	return result, nil, 200
 }
 
-func (this *Controller) getFilteredDeviceGroupsV2(token string, descriptions model.FilterCriteriaAndSet) (result []model.Selectable, err error, code int) {
+func (this *Controller) getFilteredDeviceGroupsV2(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, exclusions model.FilterCriteriaAndSet) (result []model.Selectable, err error, code int) {
 	criteriaList := []client.FilterCriteria{}
 	for _, c := range descriptions {
 		interaction := models.Interaction(c.Interaction)
 		if interaction == models.EVENT_AND_REQUEST {
 			interaction = ""
 		}
+		aspectId, err := this.getUpstreamAspectId(ctx, token, c)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
 		criteria := client.FilterCriteria{
 			Interaction:   interaction,
 			FunctionId:    c.FunctionId,
 			DeviceClassId: c.DeviceClassId,
-			AspectId:      c.AspectId,
+			AspectId:      aspectId,
 		}
 		criteriaList = append(criteriaList, criteria)
 	}
 
-	groups, _, err, code := this.devicerepo.ListDeviceGroups(token, client.DeviceGroupListOptions{
+	groups, _, err, code := this.devicerepo.ListDeviceGroups(ctx, token, client.DeviceGroupListOptions{
 		Ids:             nil,
 		Limit:           1000,
 		SortBy:          "name.asc",
@@ -85,7 +120,30 @@ func (this *Controller) getFilteredDeviceGroupsV2(token string, descriptions mod
 	if err != nil {
 		return result, err, code
 	}
+	aspectCache := &map[string]devicemodel.AspectNode{}
+	deviceTypeCache := &map[string]devicemodel.DeviceType{}
 	for _, group := range groups {
+		ok, err := this.deviceGroupMatchesAspectCriteria(ctx, token, group, descriptions, criteriaList, aspectCache)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		if !ok {
+			continue
+		}
+		ok, err, code = this.deviceGroupMatchesValueCriteria(ctx, token, group, descriptions, deviceTypeCache, aspectCache)
+		if err != nil {
+			return result, err, code
+		}
+		if !ok {
+			continue
+		}
+		excluded, err := this.deviceGroupMatchesAnyExclusion(ctx, token, group, exclusions, aspectCache)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		if excluded {
+			continue
+		}
 		temp := group //prevent that every result element becomes the last element of groups
 		result = append(result, model.Selectable{DeviceGroup: &model.DeviceGroup{
 			Id:   temp.Id,
@@ -95,3 +153,115 @@ func (this *Controller) getFilteredDeviceGroupsV2(token string, descriptions mod
 	return result, nil, 200
 
 }
+
+// deviceGroupMatchesAspectCriteria checks criteria with aspect constraints, which were queried with a wider aspect (see devicemodel.AspectMatch.UpstreamAspectId).
+// upstreamCriteria are the device-repository criteria of descriptions with the same index.
+func (this *Controller) deviceGroupMatchesAspectCriteria(ctx context.Context, token string, group models.DeviceGroup, descriptions model.FilterCriteriaAndSet, upstreamCriteria []client.FilterCriteria, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	for i, c := range descriptions {
+		if !c.HasAspectConstraints() {
+			continue
+		}
+		aspects, err := this.getDeviceGroupAspects(ctx, token, group, c.FunctionId, upstreamCriteria[i].Interaction, aspectCache)
+		if err != nil {
+			return false, err
+		}
+		if !slices.ContainsFunc(aspects, func(aspect devicemodel.AspectNode) bool { return c.AspectMatch.Matches(c.AspectId, aspect) }) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// deviceGroupMatchesValueCriteria checks criteria with value constraints, which group criteria do not contain:
+// the device-type of every device of group needs a content variable accepted by the criterion (see devicemodel.FilterCriteria.MatchesPathOption).
+// groups without devices can not be checked and do not match.
+func (this *Controller) deviceGroupMatchesValueCriteria(ctx context.Context, token string, group models.DeviceGroup, descriptions model.FilterCriteriaAndSet, deviceTypeCache *map[string]devicemodel.DeviceType, aspectCache *map[string]devicemodel.AspectNode) (bool, error, int) {
+	constrained := slices.DeleteFunc(slices.Clone(descriptions), func(c devicemodel.FilterCriteria) bool { return !c.HasValueConstraints() })
+	if len(constrained) == 0 {
+		return true, nil, http.StatusOK
+	}
+	if len(group.DeviceIds) == 0 {
+		return false, nil, http.StatusOK
+	}
+	devices, _, err, code := this.devicerepo.ListExtendedDevices(ctx, token, client.ExtendedDeviceListOptions{
+		Ids:   group.DeviceIds,
+		Limit: int64(len(group.DeviceIds)),
+	})
+	if err != nil {
+		return false, err, code
+	}
+	if len(devices) == 0 {
+		return false, nil, http.StatusOK
+	}
+	for _, device := range devices {
+		deviceType, err := this.getCachedDeviceType(ctx, token, device.DeviceTypeId, deviceTypeCache)
+		if err != nil {
+			return false, err, http.StatusInternalServerError
+		}
+		for _, c := range constrained {
+			ok, err := this.deviceTypeMatchesValueCriterion(ctx, token, deviceType, c, aspectCache)
+			if err != nil {
+				return false, err, http.StatusInternalServerError
+			}
+			if !ok {
+				return false, nil, http.StatusOK
+			}
+		}
+	}
+	return true, nil, http.StatusOK
+}
+
+// deviceTypeMatchesValueCriterion checks for a content variable of deviceType accepted by criterion, in a service with the interaction of criterion
+func (this *Controller) deviceTypeMatchesValueCriterion(ctx context.Context, token string, deviceType devicemodel.DeviceType, criterion devicemodel.FilterCriteria, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	for _, service := range deviceType.Services {
+		if criterion.Interaction != "" && criterion.Interaction != string(service.Interaction) && service.Interaction != devicemodel.EVENT_AND_REQUEST {
+			continue
+		}
+		found, err := anyContentVariable(append(slices.Clone(service.Inputs), service.Outputs...), func(variable models.ContentVariable) (bool, error) {
+			if variable.FunctionId == "" {
+				return false, nil
+			}
+			aspect := devicemodel.AspectNode{}
+			if variable.AspectId != "" {
+				var err error
+				aspect, err = this.getAspectNodeWithCache(ctx, token, aspectCache, variable.AspectId)
+				if err != nil {
+					return false, err
+				}
+			}
+			return criterion.MatchesPathOption(variable.FunctionId, aspect, variable.Type, variable.IsVoid), nil
+		})
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+// getDeviceGroupAspects returns the aspects of group with functionId and interaction (empty matches any).
+// the criteria of a group contain the aspects of its devices and their ancestors; the deepest of these aspects are used as the aspects of the group.
+func (this *Controller) getDeviceGroupAspects(ctx context.Context, token string, group models.DeviceGroup, functionId string, interaction models.Interaction, aspectCache *map[string]devicemodel.AspectNode) (result []devicemodel.AspectNode, err error) {
+	aspects := []devicemodel.AspectNode{}
+	for _, groupCriterion := range group.Criteria {
+		if groupCriterion.AspectId == "" || (functionId != "" && groupCriterion.FunctionId != functionId) {
+			continue
+		}
+		if interaction != "" && groupCriterion.Interaction != interaction {
+			continue
+		}
+		aspect, err := this.getAspectNodeWithCache(ctx, token, aspectCache, groupCriterion.AspectId)
+		if err != nil {
+			return result, err
+		}
+		aspects = append(aspects, aspect)
+	}
+	for _, aspect := range aspects {
+		deepest := !slices.ContainsFunc(aspects, func(other devicemodel.AspectNode) bool {
+			return slices.Contains(other.AncestorIds, aspect.Id)
+		})
+		if deepest {
+			result = append(result, aspect)
+		}
+	}
+	return result, nil
+}
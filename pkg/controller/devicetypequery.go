@@ -0,0 +1,173 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/idmodifier"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+// QueryDeviceTypes returns the device-types matching all criteria with their matching services and path options and the count of executable devices.
+// exclusions, aspect_match, value constraints, target characteristics and configurables are applied like in GetFilteredDevicesV2; optional criteria are not supported.
+func (this *Controller) QueryDeviceTypes(ctx context.Context, token string, options model.QueryDeviceTypesOptions) (result []model.DeviceTypeSelection, err error, code int) {
+	result = []model.DeviceTypeSelection{}
+	if err = validateCriteria(options.FilterCriteria); err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	if slices.ContainsFunc(options.FilterCriteria, func(c devicemodel.FilterCriteria) bool { return c.Optional }) {
+		return result, errors.New("optional criteria are not supported by device-type queries"), http.StatusBadRequest
+	}
+	criteria, exclusions, err := splitExclusions(options.FilterCriteria)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	if len(criteria) == 0 {
+		return result, errors.New("missing criteria"), http.StatusBadRequest
+	}
+	deviceTypeSelectables, err := this.GetDeviceTypeSelectablesCachedV2(ctx, token, criteria, options.IncludeIdModified)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	deviceTypeSelectables, err = this.removeExcludedDeviceTypes(ctx, token, deviceTypeSelectables, exclusions, &map[string]devicemodel.AspectNode{})
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	updates := []func(option *model.PathOption) (keep bool, err error){}
+	update, required := this.getCharacteristicsUpdate(ctx, token, criteria, options.FilterIncompatibleCharacteristics)
+	if update != nil {
+		updates = append(updates, update)
+	}
+	update, configured := this.getConfigurablesUpdate(ctx, token, criteria)
+	if update != nil {
+		updates = append(updates, update)
+	}
+	required = append(required, configured...)
+	for _, dtSelectable := range deviceTypeSelectables {
+		pathOptions := getServicePathOptionsFromDeviceRepoResultV2(dtSelectable.ServicePathOptions, criteria)
+		for _, update := range updates {
+			pathOptions, err = updatePathOptionMap(pathOptions, update)
+			if err != nil {
+				return result, err, http.StatusInternalServerError
+			}
+		}
+		//checked after the updates, which may remove the last option of a criterion
+		if len(pathOptions) == 0 || !pathOptionsMatchLocalCriteria(criteria, pathOptions) || !pathOptionsMatchCriteria(required, pathOptions) {
+			continue
+		}
+		services := []devicemodel.Service{}
+		for _, service := range dtSelectable.Services {
+			if _, ok := pathOptions[service.Id]; ok {
+				services = append(services, service)
+			}
+		}
+		sort.Slice(services, func(i, j int) bool {
+			return services[i].Id < services[j].Id
+		})
+		result = append(result, model.DeviceTypeSelection{
+			DeviceTypeId:       dtSelectable.DeviceTypeId,
+			Services:           services,
+			ServicePathOptions: pathOptions,
+		})
+	}
+	if len(result) == 0 {
+		return result, nil, http.StatusOK
+	}
+	deviceTypeIds := []string{}
+	for _, selection := range result {
+		deviceTypeIds = append(deviceTypeIds, selection.DeviceTypeId)
+	}
+	deviceTypes, err := this.getDeviceTypesByIds(ctx, token, deviceTypeIds)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	deviceCounts, err, code := this.countDevicesOfDeviceTypes(ctx, token, deviceTypeIds)
+	if err != nil {
+		return result, err, code
+	}
+	for i, selection := range result {
+		pureId, _ := idmodifier.SplitModifier(selection.DeviceTypeId)
+		result[i].Name = deviceTypes[selection.DeviceTypeId].Name
+		result[i].DeviceClassId = deviceTypes[selection.DeviceTypeId].DeviceClassId
+		result[i].DeviceCount = deviceCounts[pureId]
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].DeviceTypeId < result[j].DeviceTypeId
+	})
+	return result, nil, http.StatusOK
+}
+
+// getDeviceTypesByIds reads the device-types with one list request; types missing in the list are read through the controller cache (see GetDeviceType)
+func (this *Controller) getDeviceTypesByIds(ctx context.Context, token string, ids []string) (result map[string]devicemodel.DeviceType, err error) {
+	result = map[string]devicemodel.DeviceType{}
+	list, _, err, _ := this.devicerepo.ListDeviceTypesV3(ctx, token, client.DeviceTypeListOptions{
+		Ids:             ids,
+		Limit:           int64(len(ids)),
+		Offset:          0,
+		SortBy:          "name.asc",
+		IncludeModified: true,
+	})
+	if err != nil {
+		return result, err
+	}
+	for _, deviceType := range list {
+		result[deviceType.Id] = deviceType
+	}
+	for _, id := range ids {
+		if _, ok := result[id]; ok {
+			continue
+		}
+		result[id], err = this.GetDeviceType(ctx, id, token)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// countDevicesOfDeviceTypes counts the executable devices by pure device-type id with the total of the device-repository, so that no devices have to be listed.
+// id-modified device-types have a modified device for every device of their base type, so they share the count of their base type.
+func (this *Controller) countDevicesOfDeviceTypes(ctx context.Context, token string, deviceTypeIds []string) (counts map[string]int, err error, code int) {
+	counts = map[string]int{}
+	for _, id := range deviceTypeIds {
+		pureId, _ := idmodifier.SplitModifier(id)
+		if _, ok := counts[pureId]; ok {
+			continue
+		}
+		_, total, err, code := this.devicerepo.ListExtendedDevices(ctx, token, client.ExtendedDeviceListOptions{
+			DeviceTypeIds: []string{pureId},
+			Limit:         1,
+			Offset:        0,
+			Permission:    client.EXECUTE,
+		})
+		if err != nil {
+			return counts, err, code
+		}
+		counts[pureId] = int(total)
+	}
+	return counts, nil, http.StatusOK
+}
@@ -17,6 +17,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"github.com/SENERGY-Platform/device-repository/lib/client"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
@@ -27,13 +28,22 @@ import (
 	"sort"
 )
 
-func (this *Controller) getCachedDeviceType(token string, id string, cache *map[string]devicemodel.DeviceType) (result devicemodel.DeviceType, err error) {
+// GetDeviceType reads device-types through the controller cache, which is shared by all requests and flushed by cache invalidation
+func (this *Controller) GetDeviceType(ctx context.Context, id string, token string) (result devicemodel.DeviceType, err error) {
+	err = this.cache.Use(ctx, "device-types."+id, func(ctx context.Context) (interface{}, error) {
+		deviceType, err, _ := this.devicerepo.ReadDeviceType(ctx, id, token)
+		return deviceType, err
+	}, &result)
+	return
+}
+
+func (this *Controller) getCachedDeviceType(ctx context.Context, token string, id string, cache *map[string]devicemodel.DeviceType) (result devicemodel.DeviceType, err error) {
 	if cache != nil {
 		if cacheResult, ok := (*cache)[id]; ok {
 			return cacheResult, nil
 		}
 	}
-	result, err, _ = this.devicerepo.ReadDeviceType(id, token)
+	result, err, _ = this.devicerepo.ReadDeviceType(ctx, id, token)
 	if err != nil {
 		debug.PrintStack()
 		return result, err
@@ -46,11 +56,11 @@ func (this *Controller) getCachedDeviceType(token string, id string, cache *map[
 	return result, err
 }
 
-func (this *Controller) GetFilteredDeviceTypes(token string, criteria []client.FilterCriteria) (result []models.DeviceType, err error, code int) {
-	return this.getCachedFilteredDeviceTypes(token, criteria, nil)
+func (this *Controller) GetFilteredDeviceTypes(ctx context.Context, token string, criteria []client.FilterCriteria) (result []models.DeviceType, err error, code int) {
+	return this.getCachedFilteredDeviceTypes(ctx, token, criteria, nil)
 }
 
-func (this *Controller) getCachedFilteredDeviceTypes(token string, criteria []client.FilterCriteria, cache *map[string][]models.DeviceType) (result []models.DeviceType, err error, code int) {
+func (this *Controller) getCachedFilteredDeviceTypes(ctx context.Context, token string, criteria []client.FilterCriteria, cache *map[string][]models.DeviceType) (result []models.DeviceType, err error, code int) {
 	hash := hashClientCriteriaList(criteria)
 	if cache != nil {
 		if cacheResult, ok := (*cache)[hash]; ok {
@@ -68,7 +78,7 @@ func (this *Controller) getCachedFilteredDeviceTypes(token string, criteria []cl
 		IncludeModified: true,
 	}
 
-	result, _, err, code = this.devicerepo.ListDeviceTypesV3(token, query)
+	result, _, err, code = this.devicerepo.ListDeviceTypesV3(ctx, token, query)
 	if err != nil {
 		debug.PrintStack()
 		return result, err, code
@@ -81,8 +91,8 @@ func (this *Controller) getCachedFilteredDeviceTypes(token string, criteria []cl
 	return result, err, code
 }
 
-func (this *Controller) getOnlyDeviceTypesIncludingIdModifier(token string) (result []devicemodel.DeviceType, err error, code int) {
-	result, _, err, code = this.devicerepo.ListDeviceTypesV3(token, client.DeviceTypeListOptions{
+func (this *Controller) getOnlyDeviceTypesIncludingIdModifier(ctx context.Context, token string) (result []devicemodel.DeviceType, err error, code int) {
+	result, _, err, code = this.devicerepo.ListDeviceTypesV3(ctx, token, client.DeviceTypeListOptions{
 		Limit:            9999,
 		Offset:           0,
 		SortBy:           "name.asc",
@@ -92,8 +102,15 @@ func (this *Controller) getOnlyDeviceTypesIncludingIdModifier(token string) (res
 	return
 }
 
+// hashCriteriaAndSet ignores target_characteristic_id, value_types, void and configurables, which do not change upstream results
 func hashCriteriaAndSet(criteria model.FilterCriteriaAndSet) string {
 	arr := append(model.FilterCriteriaAndSet{}, criteria...) //make copy to prevent sorting to effect original
+	for i := range arr {
+		arr[i].TargetCharacteristicId = ""
+		arr[i].ValueTypes = nil
+		arr[i].Void = nil
+		arr[i].Configurables = nil
+	}
 	sort.SliceStable(arr, func(i, j int) bool {
 		return fmt.Sprint(arr[i]) < fmt.Sprint(arr[j])
 	})
@@ -109,14 +126,14 @@ func hashClientCriteriaList(criteria []client.FilterCriteria) string {
 	return fmt.Sprint(arr)
 }
 
-func (this *Controller) getCachedDevice(token string, id string, cache *map[string]devicemodel.Device) (result devicemodel.Device, err error, code int) {
+func (this *Controller) getCachedDevice(ctx context.Context, token string, id string, cache *map[string]devicemodel.Device) (result devicemodel.Device, err error, code int) {
 	if cache != nil {
 		if cacheResult, ok := (*cache)[id]; ok {
 			return cacheResult, nil, http.StatusOK
 		}
 	}
 
-	result, err, code = this.devicerepo.ReadDevice(id, token, client.READ)
+	result, err, code = this.devicerepo.ReadDevice(ctx, id, token, client.READ)
 	if err != nil {
 		debug.PrintStack()
 		return result, err, code
@@ -17,6 +17,7 @@
 package controller
 
 import (
+	"context"
 	"github.com/SENERGY-Platform/device-repository/lib/client"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
@@ -24,47 +25,55 @@ import (
 	"strconv"
 )
 
-func (this *Controller) GetDeviceTypeSelectablesCached(token string, descriptions model.FilterCriteriaAndSet) (result []devicemodel.DeviceTypeSelectable, err error) {
+func (this *Controller) GetDeviceTypeSelectablesCached(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet) (result []devicemodel.DeviceTypeSelectable, err error) {
 	hash := hashCriteriaAndSet(descriptions)
-	err = this.cache.Use("device-type-selectables."+hash, func() (interface{}, error) {
-		return this.GetDeviceTypeSelectables(token, descriptions)
+	err = this.cache.Use(ctx, "device-type-selectables."+hash, func(ctx context.Context) (interface{}, error) {
+		return this.GetDeviceTypeSelectables(ctx, token, descriptions)
 	}, &result)
 	return
 }
 
-func (this *Controller) GetDeviceTypeSelectablesCachedV2(token string, descriptions model.FilterCriteriaAndSet, includeIdModified bool) (result []devicemodel.DeviceTypeSelectable, err error) {
+func (this *Controller) GetDeviceTypeSelectablesCachedV2(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, includeIdModified bool) (result []devicemodel.DeviceTypeSelectable, err error) {
 	hash := hashCriteriaAndSet(descriptions)
 	hash = hash + strconv.FormatBool(includeIdModified)
-	err = this.cache.Use("device-type-selectables.v2."+hash, func() (interface{}, error) {
-		return this.GetDeviceTypeSelectablesV2(token, descriptions, includeIdModified)
+	err = this.cache.Use(ctx, "device-type-selectables.v2."+hash, func(ctx context.Context) (interface{}, error) {
+		return this.GetDeviceTypeSelectablesV2(ctx, token, descriptions, includeIdModified)
 	}, &result)
 	return
 }
 
-func (this *Controller) GetDeviceTypeSelectables(token string, descriptions model.FilterCriteriaAndSet) (result []devicemodel.DeviceTypeSelectable, err error) {
+func (this *Controller) GetDeviceTypeSelectables(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet) (result []devicemodel.DeviceTypeSelectable, err error) {
 	criteria := []client.FilterCriteria{}
 	for _, c := range descriptions {
+		aspectId, err := this.getUpstreamAspectId(ctx, token, c)
+		if err != nil {
+			return result, err
+		}
 		criteria = append(criteria, client.FilterCriteria{
 			Interaction:   models.Interaction(c.Interaction),
 			FunctionId:    c.FunctionId,
 			DeviceClassId: c.DeviceClassId,
-			AspectId:      c.AspectId,
+			AspectId:      aspectId,
 		})
 	}
-	result, err, _ = this.devicerepo.GetDeviceTypeSelectables(criteria, "", nil, false)
+	result, err, _ = this.devicerepo.GetDeviceTypeSelectables(ctx, criteria, "", nil, false)
 	return result, err
 }
 
-func (this *Controller) GetDeviceTypeSelectablesV2(token string, descriptions model.FilterCriteriaAndSet, includeIdModified bool) (result []devicemodel.DeviceTypeSelectable, err error) {
+func (this *Controller) GetDeviceTypeSelectablesV2(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, includeIdModified bool) (result []devicemodel.DeviceTypeSelectable, err error) {
 	criteria := []client.FilterCriteria{}
 	for _, c := range descriptions {
+		aspectId, err := this.getUpstreamAspectId(ctx, token, c)
+		if err != nil {
+			return result, err
+		}
 		criteria = append(criteria, client.FilterCriteria{
 			Interaction:   models.Interaction(c.Interaction),
 			FunctionId:    c.FunctionId,
 			DeviceClassId: c.DeviceClassId,
-			AspectId:      c.AspectId,
+			AspectId:      aspectId,
 		})
 	}
-	result, err, _ = this.devicerepo.GetDeviceTypeSelectablesV2(criteria, "", includeIdModified, false)
+	result, err, _ = this.devicerepo.GetDeviceTypeSelectablesV2(ctx, criteria, "", includeIdModified, false)
 	return result, err
 }
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+// splitExclusions separates criteria with exclude=true from the criteria that selectables have to match
+func splitExclusions(criteria model.FilterCriteriaAndSet) (included model.FilterCriteriaAndSet, excluded model.FilterCriteriaAndSet, err error) {
+	included = model.FilterCriteriaAndSet{}
+	for _, c := range criteria {
+		if c.Exclude {
+			excluded = append(excluded, c)
+		} else {
+			included = append(included, c)
+		}
+	}
+	if len(included) == 0 && len(excluded) > 0 {
+		return included, excluded, errors.New("at least one criterion must not be excluded")
+	}
+	return included, excluded, nil
+}
+
+// removeExcludedDeviceTypes removes device-type selectables whose device-type matches any exclusion
+func (this *Controller) removeExcludedDeviceTypes(ctx context.Context, token string, selectables []devicemodel.DeviceTypeSelectable, exclusions model.FilterCriteriaAndSet, aspectCache *map[string]devicemodel.AspectNode) (result []devicemodel.DeviceTypeSelectable, err error) {
+	if len(exclusions) == 0 {
+		return selectables, nil
+	}
+	deviceTypeIds := []string{}
+	for _, selectable := range selectables {
+		deviceTypeIds = append(deviceTypeIds, selectable.DeviceTypeId)
+	}
+	deviceTypes, err := this.getDeviceTypesByIds(ctx, token, deviceTypeIds)
+	if err != nil {
+		return result, err
+	}
+	for _, selectable := range selectables {
+		excluded, err := this.deviceTypeMatchesAnyExclusion(ctx, token, deviceTypes[selectable.DeviceTypeId], exclusions, aspectCache)
+		if err != nil {
+			return result, err
+		}
+		if !excluded {
+			result = append(result, selectable)
+		}
+	}
+	return result, nil
+}
+
+// deviceTypeMatchesAnyExclusion checks the device class of deviceType, the interaction of its services and the function and aspect of their content variables
+func (this *Controller) deviceTypeMatchesAnyExclusion(ctx context.Context, token string, deviceType devicemodel.DeviceType, exclusions model.FilterCriteriaAndSet, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	for _, exclusion := range exclusions {
+		if exclusion.DeviceClassId != "" && exclusion.DeviceClassId != deviceType.DeviceClassId {
+			continue
+		}
+		aspect, err := this.getExclusionAspect(ctx, token, exclusion, aspectCache)
+		if err != nil {
+			return false, err
+		}
+		for _, service := range deviceType.Services {
+			if !exclusionMatchesInteraction(exclusion, service.Interaction) {
+				continue
+			}
+			if exclusion.FunctionId == "" && exclusion.AspectId == "" {
+				return true, nil
+			}
+			found, _ := anyContentVariable(append(slices.Clone(service.Inputs), service.Outputs...), func(variable models.ContentVariable) (bool, error) {
+				return exclusionMatchesVariable(exclusion, aspect, variable.FunctionId, variable.AspectId), nil
+			})
+			if found {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// deviceGroupMatchesAnyExclusion checks the criteria of group; aspects are compared like in deviceGroupMatchesAspectCriteria.
+// group criteria list event and request separately, so event_and_request exclusions have to match both.
+func (this *Controller) deviceGroupMatchesAnyExclusion(ctx context.Context, token string, group models.DeviceGroup, exclusions model.FilterCriteriaAndSet, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	for _, exclusion := range exclusions {
+		if exclusion.AspectId != "" && exclusion.DeviceClassId != "" {
+			continue //group criteria have either an aspect or a device class
+		}
+		interactions := []models.Interaction{models.Interaction(exclusion.Interaction)}
+		if exclusion.Interaction == string(models.EVENT_AND_REQUEST) {
+			interactions = []models.Interaction{models.EVENT, models.REQUEST}
+		}
+		excluded := true
+		for _, interaction := range interactions {
+			matched, err := this.deviceGroupMatchesExclusion(ctx, token, group, exclusion, interaction, aspectCache)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				excluded = false
+				break
+			}
+		}
+		if excluded {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deviceGroupMatchesExclusion checks the group criteria of one interaction ("" matches any) against exclusion
+func (this *Controller) deviceGroupMatchesExclusion(ctx context.Context, token string, group models.DeviceGroup, exclusion devicemodel.FilterCriteria, interaction models.Interaction, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	if exclusion.AspectId == "" {
+		return slices.ContainsFunc(group.Criteria, func(groupCriterion models.DeviceGroupFilterCriteria) bool {
+			return (exclusion.FunctionId == "" || exclusion.FunctionId == groupCriterion.FunctionId) &&
+				(exclusion.DeviceClassId == "" || exclusion.DeviceClassId == groupCriterion.DeviceClassId) &&
+				(interaction == "" || interaction == groupCriterion.Interaction)
+		}), nil
+	}
+	aspects, err := this.getDeviceGroupAspects(ctx, token, group, exclusion.FunctionId, interaction, aspectCache)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(aspects, func(aspect devicemodel.AspectNode) bool {
+		return exclusion.AspectMatch.Matches(exclusion.AspectId, aspect)
+	}), nil
+}
+
+// importTypeMatchesAnyExclusion checks the output variables of importType; imports have no device class and only events
+func (this *Controller) importTypeMatchesAnyExclusion(ctx context.Context, token string, importType model.ImportType, exclusions model.FilterCriteriaAndSet, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	for _, exclusion := range exclusions {
+		if exclusion.DeviceClassId != "" || !exclusionMatchesInteraction(exclusion, devicemodel.EVENT) {
+			continue
+		}
+		if exclusion.FunctionId == "" && exclusion.AspectId == "" {
+			return true, nil
+		}
+		aspect, err := this.getExclusionAspect(ctx, token, exclusion, aspectCache)
+		if err != nil {
+			return false, err
+		}
+		found, _ := anyContentVariable([]models.Content{{ContentVariable: asContentVariable(importType.Output)}}, func(variable models.ContentVariable) (bool, error) {
+			return exclusionMatchesVariable(exclusion, aspect, variable.FunctionId, variable.AspectId), nil
+		})
+		if found {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (this *Controller) getExclusionAspect(ctx context.Context, token string, exclusion devicemodel.FilterCriteria, aspectCache *map[string]devicemodel.AspectNode) (devicemodel.AspectNode, error) {
+	if exclusion.AspectId == "" {
+		return devicemodel.AspectNode{}, nil
+	}
+	return this.getAspectNodeWithCache(ctx, token, aspectCache, exclusion.AspectId)
+}
+
+// exclusionMatchesInteraction checks the interaction of a service, group criterion or import;
+// interactions match exactly, so a request exclusion does not match event_and_request services
+func exclusionMatchesInteraction(exclusion devicemodel.FilterCriteria, interaction devicemodel.Interaction) bool {
+	return exclusion.Interaction == "" || devicemodel.Interaction(exclusion.Interaction) == interaction
+}
+
+// exclusionMatchesVariable checks function and aspect of a variable; aspect is the aspect node of exclusion.AspectId
+func exclusionMatchesVariable(exclusion devicemodel.FilterCriteria, aspect devicemodel.AspectNode, functionId string, aspectId string) bool {
+	if exclusion.FunctionId != "" && exclusion.FunctionId != functionId {
+		return false
+	}
+	if exclusion.AspectId == "" {
+		return functionId != ""
+	}
+	return aspectId != "" && listContains(exclusion.AspectMatch.AspectIds(aspect), aspectId)
+}
@@ -17,6 +17,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"slices"
@@ -30,21 +31,25 @@ import (
 	"github.com/SENERGY-Platform/models/go/models"
 )
 
-func (this *Controller) DeviceGroupHelper(token string, deviceIds []string, search model.DeviceGroupHelperPagination, maintainGroupUsability bool, functionBlockList []string) (result model.DeviceGroupHelperResult, err error, code int) {
+func (this *Controller) DeviceGroupHelper(ctx context.Context, token string, deviceIds []string, search model.DeviceGroupHelperPagination, maintainGroupUsability bool, functionBlockList []string, aspectMatch devicemodel.AspectMatch) (result model.DeviceGroupHelperResult, err error, code int) {
+	if err = aspectMatch.Validate(); err != nil {
+		return result, err, http.StatusBadRequest
+	}
 	deviceCache := &map[string]devicemodel.Device{}
 	deviceTypeCache := &map[string]devicemodel.DeviceType{}
-	result.Criteria, err, code = this.GetDeviceGroupCriteria(token, deviceTypeCache, deviceCache, deviceIds)
+	result.Criteria, err, code = this.GetDeviceGroupCriteria(ctx, token, deviceTypeCache, deviceCache, deviceIds, aspectMatch)
 	if err != nil {
 		return
 	}
-	result.Options, err, code = this.getDeviceGroupOptions(token, deviceTypeCache, deviceCache, deviceIds, result.Criteria, search, maintainGroupUsability, functionBlockList)
+	result.Options, err, code = this.getDeviceGroupOptions(ctx, token, deviceTypeCache, deviceCache, deviceIds, result.Criteria, search, maintainGroupUsability, functionBlockList, aspectMatch)
 	return result, err, code
 }
 
-func (this *Controller) GetDeviceGroupCriteria(token string, deviceTypeCache *map[string]devicemodel.DeviceType, deviceCache *map[string]devicemodel.Device, deviceIds []string) (result []devicemodel.DeviceGroupFilterCriteria, err error, code int) {
+// GetDeviceGroupCriteria returns the criteria matching all devices; measuring criteria use every aspect that matches the device aspects with aspectMatch
+func (this *Controller) GetDeviceGroupCriteria(ctx context.Context, token string, deviceTypeCache *map[string]devicemodel.DeviceType, deviceCache *map[string]devicemodel.Device, deviceIds []string, aspectMatch devicemodel.AspectMatch) (result []devicemodel.DeviceGroupFilterCriteria, err error, code int) {
 	currentSet := map[string]devicemodel.DeviceGroupFilterCriteria{}
 	for i, deviceId := range deviceIds {
-		deviceCriterias, err, code := this.getDeviceCriteria(token, deviceTypeCache, deviceCache, deviceId)
+		deviceCriterias, err, code := this.getDeviceCriteria(ctx, token, deviceTypeCache, deviceCache, deviceId, aspectMatch)
 		if err != nil {
 			return result, err, code
 		}
@@ -65,12 +70,12 @@ func (this *Controller) GetDeviceGroupCriteria(token string, deviceTypeCache *ma
 	return result, nil, http.StatusOK
 }
 
-func (this *Controller) getDeviceCriteria(token string, deviceTypeCache *map[string]devicemodel.DeviceType, deviceCache *map[string]devicemodel.Device, deviceId string) (result []devicemodel.DeviceGroupFilterCriteria, err error, code int) {
-	device, err, code := this.getCachedDevice(token, deviceId, deviceCache)
+func (this *Controller) getDeviceCriteria(ctx context.Context, token string, deviceTypeCache *map[string]devicemodel.DeviceType, deviceCache *map[string]devicemodel.Device, deviceId string, aspectMatch devicemodel.AspectMatch) (result []devicemodel.DeviceGroupFilterCriteria, err error, code int) {
+	device, err, code := this.getCachedDevice(ctx, token, deviceId, deviceCache)
 	if err != nil {
 		return result, err, code
 	}
-	deviceType, err := this.getCachedDeviceType(token, device.DeviceTypeId, deviceTypeCache)
+	deviceType, err := this.getCachedDeviceType(ctx, token, device.DeviceTypeId, deviceTypeCache)
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
@@ -99,11 +104,11 @@ func (this *Controller) getDeviceCriteria(token string, deviceTypeCache *map[str
 						}
 						resultSet[criteriaHash(criteria)] = criteria
 						if current.AspectId != "" {
-							aspectNode, err := this.GetAspectNode(current.AspectId, token)
+							aspectNode, err := this.GetAspectNode(ctx, current.AspectId, token)
 							if err != nil {
 								return result, err, http.StatusInternalServerError
 							}
-							for _, aspect := range aspectNode.AncestorIds {
+							for _, aspect := range aspectMatch.Reverse().AspectIds(aspectNode) {
 								criteria := devicemodel.DeviceGroupFilterCriteria{
 									FunctionId:  current.FunctionId,
 									AspectId:    aspect,
@@ -138,29 +143,31 @@ func criteriaHash(criteria devicemodel.DeviceGroupFilterCriteria) string {
 }
 
 func (this *Controller) getDeviceGroupOptionsGetDevice(
+	ctx context.Context,
 	token string,
 	currentDeviceIds []string,
 	criteria []devicemodel.DeviceGroupFilterCriteria,
 	search model.DeviceGroupHelperPagination,
 	maintainGroupUsability bool,
-	functionBlockList []string) (devices []model.PermSearchDevice, err error, code int) {
+	functionBlockList []string,
+	aspectMatch devicemodel.AspectMatch) (devices []model.PermSearchDevice, err error, code int) {
 
 	validDeviceTypes := []string{}
 	if maintainGroupUsability && len(criteria) > 0 {
-		validDeviceTypes, err = this.getValidDeviceTypesForDeviceGroup(token, criteria, functionBlockList)
+		validDeviceTypes, err = this.getValidDeviceTypesForDeviceGroup(ctx, token, criteria, functionBlockList, aspectMatch)
 		if err != nil {
 			this.config.GetLogger().Warn("unable to get valid device-types for device-group", "error", err, "criteria", fmt.Sprintf("%#v", criteria), "functionBlockList", functionBlockList)
 			err = nil
 		}
 	}
 
-	unmodifiedDevices, err, code := this.getDeviceGroupOptionsGetDevicesUnmodified(token, currentDeviceIds, search, validDeviceTypes)
+	unmodifiedDevices, err, code := this.getDeviceGroupOptionsGetDevicesUnmodified(ctx, token, currentDeviceIds, search, validDeviceTypes)
 	if err != nil {
 		return devices, err, code
 	}
 	devices = append(devices, unmodifiedDevices...)
 
-	modifiedDevices, err, code := this.getDeviceGroupOptionsGetDevicesModified(token, currentDeviceIds, search, validDeviceTypes)
+	modifiedDevices, err, code := this.getDeviceGroupOptionsGetDevicesModified(ctx, token, currentDeviceIds, search, validDeviceTypes)
 	if err != nil {
 		return devices, err, code
 	}
@@ -197,13 +204,13 @@ func (this *Controller) getDeviceGroupOptionsGetDevice(
 	return filteredDevices, err, code
 }
 
-func (this *Controller) getDeviceGroupOptionsGetDevicesModified(token string, currentDeviceIds []string, search model.DeviceGroupHelperPagination, validDeviceTypes []string) (devices []model.PermSearchDevice, err error, code int) {
+func (this *Controller) getDeviceGroupOptionsGetDevicesModified(ctx context.Context, token string, currentDeviceIds []string, search model.DeviceGroupHelperPagination, validDeviceTypes []string) (devices []model.PermSearchDevice, err error, code int) {
 	if currentDeviceIds == nil {
 		currentDeviceIds = []string{}
 	}
 
 	if len(validDeviceTypes) == 0 {
-		deviceTypes, err, code := this.getOnlyDeviceTypesIncludingIdModifier(token)
+		deviceTypes, err, code := this.getOnlyDeviceTypesIncludingIdModifier(ctx, token)
 		if err != nil {
 			return devices, err, code
 		}
@@ -222,7 +229,7 @@ func (this *Controller) getDeviceGroupOptionsGetDevicesModified(token string, cu
 		}
 	}
 
-	unModDevices, err, code := this.devicerepo.ListDevices(token, client.DeviceListOptions{
+	unModDevices, err, code := this.devicerepo.ListDevices(ctx, token, client.DeviceListOptions{
 		DeviceTypeIds: searchedDeviceTypeIds,
 		Search:        search.Search,
 		Limit:         search.Limit + int64(len(currentDeviceIds)),
@@ -239,7 +246,7 @@ func (this *Controller) getDeviceGroupOptionsGetDevicesModified(token string, cu
 			modefiedDeviceIds = append(modefiedDeviceIds, idmodifier.JoinModifier(device.Id, mod))
 		}
 	}
-	modDevices, _, err, _ := this.devicerepo.ListExtendedDevices(token, client.ExtendedDeviceListOptions{
+	modDevices, _, err, _ := this.devicerepo.ListExtendedDevices(ctx, token, client.ExtendedDeviceListOptions{
 		Ids:    modefiedDeviceIds,
 		SortBy: "name.asc",
 	})
@@ -266,6 +273,7 @@ func (this *Controller) getDeviceGroupOptionsGetDevicesModified(token string, cu
 }
 
 func (this *Controller) getDeviceGroupOptionsGetDevicesUnmodified(
+	ctx context.Context,
 	token string,
 	currentDeviceIds []string,
 	search model.DeviceGroupHelperPagination,
@@ -280,7 +288,7 @@ func (this *Controller) getDeviceGroupOptionsGetDevicesUnmodified(
 		trimmedCurrentDeviceIds[i], _ = idmodifier.SplitModifier(id)
 	}
 
-	temp, _, err, _ := this.devicerepo.ListExtendedDevices(token, client.ExtendedDeviceListOptions{
+	temp, _, err, _ := this.devicerepo.ListExtendedDevices(ctx, token, client.ExtendedDeviceListOptions{
 		DeviceTypeIds: validDeviceTypes,
 		Search:        search.Search,
 		Limit:         search.Limit + int64(len(currentDeviceIds)),
@@ -310,6 +318,7 @@ func (this *Controller) getDeviceGroupOptionsGetDevicesUnmodified(
 }
 
 func (this *Controller) getDeviceGroupOptions(
+	ctx context.Context,
 	token string,
 	deviceTypeCache *map[string]devicemodel.DeviceType,
 	deviceCache *map[string]devicemodel.Device,
@@ -318,13 +327,14 @@ func (this *Controller) getDeviceGroupOptions(
 	search model.DeviceGroupHelperPagination,
 	maintainGroupUsability bool,
 	functionBlockList []string,
+	aspectMatch devicemodel.AspectMatch,
 ) (
 	result []model.DeviceGroupOption,
 	err error,
 	code int,
 ) {
 
-	devices, err, code := this.getDeviceGroupOptionsGetDevice(token, currentDeviceIds, criteria, search, maintainGroupUsability, functionBlockList)
+	devices, err, code := this.getDeviceGroupOptionsGetDevice(ctx, token, currentDeviceIds, criteria, search, maintainGroupUsability, functionBlockList, aspectMatch)
 	if err != nil {
 		return result, err, code
 	}
@@ -341,7 +351,7 @@ func (this *Controller) getDeviceGroupOptions(
 			option.RemovesCriteria = cached
 			deviceCriteria = deviceTypeToCriteriaCache[device.DeviceTypeId]
 		} else {
-			option.RemovesCriteria, deviceCriteria, err, code = this.getDeviceGroupOptionCriteria(token, deviceTypeCache, deviceCache, criteria, option.Device.Id)
+			option.RemovesCriteria, deviceCriteria, err, code = this.getDeviceGroupOptionCriteria(ctx, token, deviceTypeCache, deviceCache, criteria, option.Device.Id, aspectMatch)
 			if err != nil {
 				return result, err, code
 			}
@@ -355,11 +365,13 @@ func (this *Controller) getDeviceGroupOptions(
 }
 
 func (this *Controller) getDeviceGroupOptionCriteria(
+	ctx context.Context,
 	token string,
 	deviceTypeCache *map[string]devicemodel.DeviceType,
 	deviceCache *map[string]devicemodel.Device,
 	currentCriteria []devicemodel.DeviceGroupFilterCriteria,
 	deviceId string,
+	aspectMatch devicemodel.AspectMatch,
 ) (
 	result []devicemodel.DeviceGroupFilterCriteria,
 	deviceCriteria []devicemodel.DeviceGroupFilterCriteria,
@@ -367,7 +379,7 @@ func (this *Controller) getDeviceGroupOptionCriteria(
 	code int,
 ) {
 	result = []devicemodel.DeviceGroupFilterCriteria{}
-	deviceCriteria, err, code = this.getDeviceCriteria(token, deviceTypeCache, deviceCache, deviceId)
+	deviceCriteria, err, code = this.getDeviceCriteria(ctx, token, deviceTypeCache, deviceCache, deviceId, aspectMatch)
 	if err != nil {
 		return result, deviceCriteria, err, code
 	}
@@ -383,7 +395,7 @@ func (this *Controller) getDeviceGroupOptionCriteria(
 	return
 }
 
-func (this *Controller) getValidDeviceTypesForDeviceGroup(token string, criteria []devicemodel.DeviceGroupFilterCriteria, functionBlockList []string) (deviceTypeIds []string, err error) {
+func (this *Controller) getValidDeviceTypesForDeviceGroup(ctx context.Context, token string, criteria []devicemodel.DeviceGroupFilterCriteria, functionBlockList []string, aspectMatch devicemodel.AspectMatch) (deviceTypeIds []string, err error) {
 	functionBlockSet := map[string]bool{}
 	for _, fId := range functionBlockList {
 		functionBlockSet[strings.TrimSpace(fId)] = true
@@ -392,7 +404,7 @@ func (this *Controller) getValidDeviceTypesForDeviceGroup(token string, criteria
 	deviceIdSet := map[string]bool{}
 	for _, c := range criteria {
 		if !functionBlockSet[c.FunctionId] {
-			temp, err := this.cachedGetValidDeviceTypesForDeviceGroupCriteria(token, c)
+			temp, err := this.cachedGetValidDeviceTypesForDeviceGroupCriteria(ctx, token, c, aspectMatch)
 			if err != nil {
 				return deviceTypeIds, err
 			}
@@ -407,29 +419,74 @@ func (this *Controller) getValidDeviceTypesForDeviceGroup(token string, criteria
 	return deviceTypeIds, nil
 }
 
-func (this *Controller) cachedGetValidDeviceTypesForDeviceGroupCriteria(token string, criteria devicemodel.DeviceGroupFilterCriteria) (deviceTypeIds []string, err error) {
-	err = this.cache.Use("dt_by_criteria."+criteria.Short(), func() (interface{}, error) {
-		return this.getValidDeviceTypesForDeviceGroupCriteria(token, criteria)
+// cachedGetValidDeviceTypesForDeviceGroupCriteria caches by criteria and aspectMatch; the group criteria are computed with the same aspectMatch (see getDeviceCriteria)
+func (this *Controller) cachedGetValidDeviceTypesForDeviceGroupCriteria(ctx context.Context, token string, criteria devicemodel.DeviceGroupFilterCriteria, aspectMatch devicemodel.AspectMatch) (deviceTypeIds []string, err error) {
+	err = this.cache.Use(ctx, "dt_by_criteria."+string(aspectMatch.OrDefault())+"."+criteria.Short(), func(ctx context.Context) (interface{}, error) {
+		return this.getValidDeviceTypesForDeviceGroupCriteria(ctx, token, criteria, aspectMatch)
 	}, &deviceTypeIds)
 	return
 }
 
-func (this *Controller) getValidDeviceTypesForDeviceGroupCriteria(token string, criteria devicemodel.DeviceGroupFilterCriteria) (deviceTypeIds []string, err error) {
+// getValidDeviceTypesForDeviceGroupCriteria returns the device-types with a content variable matching criteria.
+// the device-repository matches aspects with descendants; other aspectMatch modes query a wider aspect (see devicemodel.AspectMatch.UpstreamAspectId) and filter the result.
+func (this *Controller) getValidDeviceTypesForDeviceGroupCriteria(ctx context.Context, token string, criteria devicemodel.DeviceGroupFilterCriteria, aspectMatch devicemodel.AspectMatch) (deviceTypeIds []string, err error) {
+	aspectCache := &map[string]devicemodel.AspectNode{}
+	upstreamAspectId := criteria.AspectId
+	filterAspects := criteria.AspectId != "" && aspectMatch.OrDefault() != devicemodel.AspectMatchDescendants
+	if filterAspects {
+		aspect, err := this.getAspectNodeWithCache(ctx, token, aspectCache, criteria.AspectId)
+		if err != nil {
+			return deviceTypeIds, err
+		}
+		upstreamAspectId = aspectMatch.UpstreamAspectId(aspect)
+	}
 	descriptions := []client.FilterCriteria{
 		{
 			Interaction:   models.Interaction(criteria.Interaction),
 			FunctionId:    criteria.FunctionId,
-			AspectId:      criteria.AspectId,
+			AspectId:      upstreamAspectId,
 			DeviceClassId: criteria.DeviceClassId,
 		},
 	}
-	deviceTypes, err, _ := this.getCachedFilteredDeviceTypes(token, descriptions, nil)
+	deviceTypes, err, _ := this.getCachedFilteredDeviceTypes(ctx, token, descriptions, nil)
 	if err != nil {
 		return deviceTypeIds, err
 	}
 	this.config.GetLogger().Debug("GetFilteredDevices()::getCachedFilteredDeviceTypes()", "deviceTypes", deviceTypes)
 	for _, dt := range deviceTypes {
+		if filterAspects {
+			ok, err := this.deviceTypeMatchesDeviceGroupCriteria(ctx, token, dt, criteria, aspectMatch, aspectCache)
+			if err != nil {
+				return deviceTypeIds, err
+			}
+			if !ok {
+				continue
+			}
+		}
 		deviceTypeIds = append(deviceTypeIds, dt.Id)
 	}
 	return deviceTypeIds, nil
 }
+
+// deviceTypeMatchesDeviceGroupCriteria checks for a content variable with the function and an aspect matching criteria with aspectMatch, in a service with the interaction of criteria
+func (this *Controller) deviceTypeMatchesDeviceGroupCriteria(ctx context.Context, token string, deviceType models.DeviceType, criteria devicemodel.DeviceGroupFilterCriteria, aspectMatch devicemodel.AspectMatch, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	for _, service := range deviceType.Services {
+		if criteria.Interaction != "" && service.Interaction != criteria.Interaction && service.Interaction != models.EVENT_AND_REQUEST {
+			continue
+		}
+		found, err := anyContentVariable(append(slices.Clone(service.Inputs), service.Outputs...), func(variable models.ContentVariable) (bool, error) {
+			if variable.FunctionId != criteria.FunctionId || variable.AspectId == "" {
+				return false, nil
+			}
+			aspect, err := this.getAspectNodeWithCache(ctx, token, aspectCache, variable.AspectId)
+			if err != nil {
+				return false, err
+			}
+			return aspectMatch.Matches(criteria.AspectId, aspect), nil
+		})
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
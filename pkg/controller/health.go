@@ -0,0 +1,76 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/cache"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/cacheinvalidator/kafka"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/health"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/upstream"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+const defaultHealthCheckCacheDuration = 10 * time.Second
+
+func (this *Controller) CheckHealth(ctx context.Context) health.Report {
+	return this.health.Check(ctx)
+}
+
+// newHealthChecker probes only dependencies that are configured; injected clients are not probed
+func newHealthChecker(config configuration.Config, c cache.Cache, httpClient *http.Client, injected dependencies) (*health.Checker, error) {
+	timeout, err := parseDurationOrDefault(config.HealthCheckTimeout, defaultHealthCheckTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health_check_timeout: %w", err)
+	}
+	cacheDuration, err := parseDurationOrDefault(config.HealthCheckCacheDuration, defaultHealthCheckCacheDuration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health_check_cache_duration: %w", err)
+	}
+	probes := []health.Probe{}
+	if injected.DeviceRepository == nil {
+		probes = append(probes, health.HttpProbe(httpClient, upstream.DeviceRepositoryName, config.DeviceRepoUrl))
+	}
+	if config.ImportDeployUrl != "" && injected.ImportDeploy == nil {
+		probes = append(probes, health.HttpProbe(httpClient, upstream.ImportDeployName, config.ImportDeployUrl))
+	}
+	if config.ImportRepoUrl != "" && injected.ImportRepository == nil {
+		probes = append(probes, health.HttpProbe(httpClient, upstream.ImportRepositoryName, config.ImportRepoUrl))
+	}
+	if len(config.MemcachedUrls) > 0 && injected.Cache == nil {
+		probes = append(probes, health.Probe{
+			Name: "memcached",
+			Check: func(ctx context.Context) error {
+				return c.Ping()
+			},
+		})
+	}
+	if useCacheInvalidation(config) && injected.InvalidationSource == nil {
+		probes = append(probes, health.Probe{
+			Name: "kafka",
+			Check: func(ctx context.Context) error {
+				return kafka.Ping(ctx, config.KafkaUrl)
+			},
+		})
+	}
+	return health.NewChecker(timeout, cacheDuration, probes...), nil
+}
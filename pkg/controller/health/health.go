@@ -0,0 +1,145 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Probe struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type DependencyStatus struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type Report struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
+
+// Checker runs all probes concurrently and caches the report for cacheDuration,
+// so that frequent readiness requests do not result in a request to every dependency
+type Checker struct {
+	probes        []Probe
+	timeout       time.Duration
+	cacheDuration time.Duration
+	mux           sync.Mutex
+	last          Report
+	lastCheck     time.Time
+}
+
+func NewChecker(timeout time.Duration, cacheDuration time.Duration, probes ...Probe) *Checker {
+	sort.SliceStable(probes, func(i, j int) bool {
+		return probes[i].Name < probes[j].Name
+	})
+	return &Checker{probes: probes, timeout: timeout, cacheDuration: cacheDuration}
+}
+
+// Check returns the cached report if it is younger than cacheDuration; otherwise all probes are executed.
+// concurrent callers wait for a running check instead of starting their own.
+func (this *Checker) Check(ctx context.Context) Report {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if !this.lastCheck.IsZero() && time.Since(this.lastCheck) < this.cacheDuration {
+		return this.last
+	}
+	this.last = this.run(ctx)
+	this.lastCheck = time.Now()
+	return this.last
+}
+
+func (this *Checker) run(ctx context.Context) (result Report) {
+	result = Report{Status: StatusUp, Dependencies: map[string]DependencyStatus{}}
+	statuses := make([]DependencyStatus, len(this.probes))
+	wg := sync.WaitGroup{}
+	for i, probe := range this.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = this.probe(ctx, probe)
+		}()
+	}
+	wg.Wait()
+	for i, probe := range this.probes {
+		result.Dependencies[probe.Name] = statuses[i]
+		if statuses[i].Status != StatusUp {
+			result.Status = StatusDown
+		}
+	}
+	return result
+}
+
+func (this *Checker) probe(ctx context.Context, probe Probe) (result DependencyStatus) {
+	// the probe must not be canceled by the request that happened to trigger it, because the result is shared with other callers
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), this.timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- probe.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result = DependencyStatus{Status: StatusUp, LatencyMs: time.Since(start).Milliseconds(), CheckedAt: start}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// HttpProbe expects any response with a status code below 500 from url; the service has no common health endpoint, so reachability is all we can check
+func HttpProbe(client *http.Client, name string, url string) Probe {
+	return Probe{
+		Name: name,
+		Check: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("unexpected status code %v", resp.StatusCode)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChecker(t *testing.T) {
+	calls := atomic.Int64{}
+	checker := NewChecker(100*time.Millisecond, time.Hour,
+		Probe{Name: "up", Check: func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}},
+		Probe{Name: "down", Check: func(ctx context.Context) error {
+			return errors.New("unreachable")
+		}},
+		Probe{Name: "slow", Check: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+	)
+	report := checker.Check(context.Background())
+	if report.Status != StatusDown {
+		t.Error(report.Status)
+	}
+	if report.Dependencies["up"].Status != StatusUp {
+		t.Error(report.Dependencies["up"])
+	}
+	if report.Dependencies["down"].Status != StatusDown || report.Dependencies["down"].Error != "unreachable" {
+		t.Error(report.Dependencies["down"])
+	}
+	if report.Dependencies["slow"].Status != StatusDown || report.Dependencies["slow"].Error != context.DeadlineExceeded.Error() {
+		t.Error(report.Dependencies["slow"])
+	}
+
+	checker.Check(context.Background())
+	if calls.Load() != 1 {
+		t.Error("expected cached result", calls.Load())
+	}
+}
@@ -16,6 +16,13 @@
 
 package controller
 
+import (
+	"slices"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
 func RemoveDuplicates[T comparable](slice []T) []T {
 	keys := make(map[T]bool)
 	result := []T{}
@@ -40,3 +47,55 @@ func RemoveDuplicatesF[T any, C comparable](slice []T, f func(T) C) []T {
 	}
 	return result
 }
+
+// updatePathOptions calls update for every path option of selectables and removes the option if keep is false.
+// services and selectables whose options are all removed are removed too; selectables without options (v1 selectables and device-groups) are kept,
+// their value constraints are checked when they are selected (see deviceGroupMatchesValueCriteria and importVariableMatchesCriteria).
+// selectables are also removed if a criterion of required has no option left (see pathOptionsMatchCriteria).
+// path option maps may be shared between selectables of the same device-type, so they are copied.
+func updatePathOptions(selectables []model.Selectable, required model.FilterCriteriaAndSet, update func(option *model.PathOption) (keep bool, err error)) (result []model.Selectable, err error) {
+	result = []model.Selectable{}
+	for _, selectable := range selectables {
+		if len(selectable.ServicePathOptions) == 0 {
+			result = append(result, selectable)
+			continue
+		}
+		pathOptions, err := updatePathOptionMap(selectable.ServicePathOptions, update)
+		if err != nil {
+			return result, err
+		}
+		if len(pathOptions) == 0 || !pathOptionsMatchCriteria(required, pathOptions) {
+			continue
+		}
+		selectable.ServicePathOptions = pathOptions
+		if selectable.Services != nil {
+			selectable.Services = slices.DeleteFunc(slices.Clone(selectable.Services), func(service devicemodel.Service) bool {
+				_, ok := pathOptions[service.Id]
+				return !ok
+			})
+		}
+		result = append(result, selectable)
+	}
+	return result, nil
+}
+
+// updatePathOptionMap returns a copy of pathOptions with the updated options; services without kept options are removed
+func updatePathOptionMap(pathOptions map[string][]model.PathOption, update func(option *model.PathOption) (keep bool, err error)) (result map[string][]model.PathOption, err error) {
+	result = map[string][]model.PathOption{}
+	for serviceId, options := range pathOptions {
+		updated := []model.PathOption{}
+		for _, option := range options {
+			keep, err := update(&option)
+			if err != nil {
+				return result, err
+			}
+			if keep {
+				updated = append(updated, option)
+			}
+		}
+		if len(updated) > 0 {
+			result[serviceId] = updated
+		}
+	}
+	return result, nil
+}
@@ -17,11 +17,9 @@
 package controller
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"net/http"
-	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -29,255 +27,216 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 	importrepo "github.com/SENERGY-Platform/import-repository/lib/client"
 	importrepomodel "github.com/SENERGY-Platform/import-repository/lib/model"
+	"github.com/SENERGY-Platform/models/go/models"
 	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
 )
 
-func (this *Controller) getFilteredImports(token string, descriptions model.FilterCriteriaAndSet) (result []model.Selectable, err error, code int) {
-	jwtToken, err := jwt.Parse(token)
-	if err != nil {
-		return result, err, http.StatusInternalServerError
-	}
-
-	criteria := []importrepo.ImportTypeFilterCriteria{}
-
-	for _, c := range descriptions {
-		importTypeCriteria := importrepo.ImportTypeFilterCriteria{
-			FunctionId: c.FunctionId,
-			AspectIds:  []string{c.AspectId},
-		}
-		aspect, err := this.GetAspectNode(c.AspectId, token)
-		if err != nil {
-			return result, err, http.StatusInternalServerError
-		}
-		for _, aid := range aspect.DescendentIds {
-			importTypeCriteria.AspectIds = append(importTypeCriteria.AspectIds, aid)
-		}
-		criteria = append(criteria, importTypeCriteria)
-	}
-
-	importTypes, _, err, code := this.importrepo.ListImportTypes(jwtToken, importrepo.ImportTypeListOptions{
-		Limit:    1000,
-		Offset:   0,
-		SortBy:   "name.asc",
-		Criteria: criteria,
-	})
+// getFilteredImports returns the instances of the import types matching descriptions (see findImports);
+// like in the baseline v1 api, selectables have no path options (see completeServices) and import types are not read again
+func (this *Controller) getFilteredImports(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, exclusions model.FilterCriteriaAndSet) (result []model.Selectable, err error, code int) {
+	aspectCache := &map[string]devicemodel.AspectNode{}
+	importTypes, instances, err, code := this.findImports(ctx, token, descriptions, aspectCache)
 	if err != nil {
 		return result, err, code
 	}
-	importTypeIds := []string{}
-	for _, importType := range importTypes {
-		importTypeIds = append(importTypeIds, importType.Id)
+	for _, instance := range instances {
+		temp := instance //prevent that every result element becomes the last element of groups
+		for _, importType := range importTypes {
+			if importType.Id != temp.ImportTypeId {
+				continue
+			}
+			tempType := castImportType(importType)
+			local := slices.DeleteFunc(slices.Clone(descriptions), func(c devicemodel.FilterCriteria) bool { return !hasLocalConstraints(c) })
+			if !importVariablesMatchCriteria([]model.ImportContentVariable{tempType.Output}, local, *aspectCache) {
+				continue
+			}
+			excluded, err := this.importTypeMatchesAnyExclusion(ctx, token, tempType, exclusions, aspectCache)
+			if err != nil {
+				return result, err, http.StatusInternalServerError
+			}
+			if !excluded {
+				result = append(result, model.Selectable{Import: &temp, ImportType: &tempType})
+			}
+		}
 	}
+	return result, nil, http.StatusOK
+}
 
-	this.config.GetLogger().Debug("getFilteredImports()::Found "+strconv.Itoa(len(importTypeIds))+" matching import types", "importTypeIds", importTypeIds)
-
-	instances, err, code := this.getImportsByTypes(token, importTypeIds)
+// getFilteredImportsV2 returns imports matching descriptions and, if set, filter; imports of types matching exclusions are removed
+func (this *Controller) getFilteredImportsV2(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, exclusions model.FilterCriteriaAndSet, importPathTrimFirstElement bool, filter *model.ImportFilter) (result []model.Selectable, err error, code int) {
+	aspectCache := &map[string]devicemodel.AspectNode{}
+	_, instances, err, code := this.findImports(ctx, token, descriptions, aspectCache)
 	if err != nil {
 		return result, err, code
 	}
-	this.config.GetLogger().Debug("getFilteredImports()::Found " + strconv.Itoa(len(instances)) + " matching import instances")
-
 	for _, instance := range instances {
 		temp := instance //prevent that every result element becomes the last element of groups
-		for _, importType := range importTypes {
-			if importType.Id == temp.ImportTypeId {
-				tempType := castImportType(importType)
-				result = append(result, model.Selectable{Import: &temp, ImportType: &tempType})
+		var matchedConfigs []model.ImportConfig
+		if filter != nil {
+			var ok bool
+			matchedConfigs, ok = filter.Match(temp)
+			if !ok {
+				continue
+			}
+		}
+		if temp.ImportTypeId != "" {
+			fullType, err := this.getFullImportType(ctx, token, temp.ImportTypeId)
+			if err != nil {
+				return result, err, http.StatusInternalServerError
+			}
+			excluded, err := this.importTypeMatchesAnyExclusion(ctx, token, fullType, exclusions, aspectCache)
+			if err != nil {
+				return result, err, http.StatusInternalServerError
+			}
+			if excluded {
+				continue
+			}
+			variables := []model.ImportContentVariable{fullType.Output}
+			if importPathTrimFirstElement {
+				variables = fullType.Output.SubContentVariables
+			}
+			if !importVariablesMatchCriteria(variables, descriptions, *aspectCache) {
+				continue
+			}
+			var pathOptions []model.PathOption
+			for _, variable := range variables {
+				pathOptions = append(pathOptions, getImportPathOptions(variable, descriptions, nil, *aspectCache)...)
+			}
+			if len(pathOptions) > 0 {
+				pathOptionsMap := map[string][]model.PathOption{fullType.Id: pathOptions}
+				result = append(result, model.Selectable{Import: &temp, ImportType: &fullType, ServicePathOptions: pathOptionsMap, MatchedImportConfigs: matchedConfigs})
 			}
 		}
 	}
-	return result, err, code
+	return result, nil, http.StatusOK
 }
 
-func (this *Controller) getFilteredImportsV2(token string, descriptions model.FilterCriteriaAndSet, importPathTrimFirstElement bool) (result []model.Selectable, err error, code int) {
-	jwtToken, err := jwt.Parse(token)
-	if err != nil {
-		return result, err, http.StatusInternalServerError
-	}
+// findImports returns the import types with an output variable for every criterion and the instances of these types.
+// import types have no device class; requests with device_class_id criteria do not query imports (see criteriaNeedDeviceClass).
+// the aspect nodes of the criteria are stored in aspectCache for importVariableMatchesCriteria.
+func (this *Controller) findImports(ctx context.Context, token string, descriptions model.FilterCriteriaAndSet, aspectCache *map[string]devicemodel.AspectNode) (importTypes []importrepomodel.ImportType, instances []model.Import, err error, code int) {
 	criteria := []importrepo.ImportTypeFilterCriteria{}
 	for _, c := range descriptions {
-		criteriaFilter := importrepo.ImportTypeFilterCriteria{
-			FunctionId: c.FunctionId,
-		}
+		importTypeCriteria := importrepo.ImportTypeFilterCriteria{FunctionId: c.FunctionId}
 		if c.AspectId != "" {
-			criteriaFilter.AspectIds = []string{c.AspectId}
-			aspect, err := this.GetAspectNode(c.AspectId, token)
+			aspect, err := this.getAspectNodeWithCache(ctx, token, aspectCache, c.AspectId)
 			if err != nil {
-				return result, err, http.StatusInternalServerError
-			}
-			for _, aid := range aspect.DescendentIds {
-				criteriaFilter.AspectIds = append(criteriaFilter.AspectIds, aid)
+				return nil, nil, err, http.StatusInternalServerError
 			}
+			importTypeCriteria.AspectIds = c.AspectMatch.AspectIds(aspect)
 		}
-		criteria = append(criteria, criteriaFilter)
+		criteria = append(criteria, importTypeCriteria)
 	}
-	importTypes, _, err, code := this.importrepo.ListImportTypes(jwtToken, importrepo.ImportTypeListOptions{
+	jwtToken, err := jwt.Parse(token)
+	if err != nil {
+		return nil, nil, err, http.StatusInternalServerError
+	}
+	importTypes, _, err, code = this.importrepo.ListImportTypes(ctx, jwtToken, importrepo.ImportTypeListOptions{
 		Limit:    1000,
 		Offset:   0,
 		SortBy:   "name.asc",
 		Criteria: criteria,
 	})
 	if err != nil {
-		return result, err, code
+		return nil, nil, err, code
 	}
 	importTypeIds := []string{}
 	for _, importType := range importTypes {
 		importTypeIds = append(importTypeIds, importType.Id)
 	}
+	this.config.GetLogger().Debug("findImports()::Found "+strconv.Itoa(len(importTypeIds))+" matching import types", "importTypeIds", importTypeIds)
 
-	this.config.GetLogger().Debug("getFilteredImports()::Found "+strconv.Itoa(len(importTypeIds))+" matching import types", "importTypeIds", importTypeIds)
-
-	instances, err, code := this.getImportsByTypes(token, importTypeIds)
+	instances, err, code = this.getImportsByTypes(ctx, token, importTypeIds)
 	if err != nil {
-		return result, err, code
-	}
-	this.config.GetLogger().Debug("getFilteredImports()::Found " + strconv.Itoa(len(instances)) + " matching import instances")
-
-	aspectCache := &map[string]devicemodel.AspectNode{}
-
-	for _, instance := range instances {
-		temp := instance //prevent that every result element becomes the last element of groups
-		if temp.ImportTypeId != "" {
-			fullType, err := this.getFullImportType(token, temp.ImportTypeId)
-			if err != nil {
-				return result, err, code
-			}
-			var pathOptions []model.PathOption
-			if importPathTrimFirstElement {
-				for _, sub := range fullType.Output.SubContentVariables {
-					subOptions, err := this.getImportPathOptions(token, sub, descriptions, nil, aspectCache)
-					if err != nil {
-						return result, err, code
-					}
-					pathOptions = append(pathOptions, subOptions...)
-				}
-			} else {
-				pathOptions, err = this.getImportPathOptions(token, fullType.Output, descriptions, nil, aspectCache)
-				if err != nil {
-					return result, err, code
-				}
-			}
-
-			var pathOptionsMap map[string][]model.PathOption
-			if pathOptions != nil && len(pathOptions) > 0 {
-				pathOptionsMap = map[string][]model.PathOption{fullType.Id: pathOptions}
-				result = append(result, model.Selectable{Import: &temp, ImportType: &fullType, ServicePathOptions: pathOptionsMap})
-			}
-		}
+		return nil, nil, err, code
 	}
-	return result, nil, http.StatusOK
+	this.config.GetLogger().Debug("findImports()::Found " + strconv.Itoa(len(instances)) + " matching import instances")
+	return importTypes, instances, nil, http.StatusOK
 }
 
-func (this *Controller) getImportPathOptions(token string, variable model.ImportContentVariable, criteria model.FilterCriteriaAndSet, currentPath []string, aspectCache *map[string]devicemodel.AspectNode) (result []model.PathOption, err error) {
+// getImportPathOptions returns a path option for every variable that matches any criterion; aspectCache must contain the aspects of criteria (see findImports)
+func getImportPathOptions(variable model.ImportContentVariable, criteria model.FilterCriteriaAndSet, currentPath []string, aspectCache map[string]devicemodel.AspectNode) (result []model.PathOption) {
 	currentPath = append(currentPath, variable.Name)
-	match, err := this.contentVariableContainsAnyCriteria(&variable, criteria, token, aspectCache)
-	//match, err := this.importVariableMatchesAllCriteria(token, variable, criteria, aspectCache)
-	if err != nil {
-		return result, err
-	}
-	if match {
-		result = append(result, model.PathOption{
-			Path:             strings.Join(currentPath, "."),
-			CharacteristicId: variable.CharacteristicId,
-			AspectNode: devicemodel.AspectNode{
-				Id: variable.AspectId,
-			},
-			FunctionId:  variable.FunctionId,
-			IsVoid:      false,
-			Type:        variable.Type,
-			Interaction: devicemodel.EVENT,
-		})
+	for _, c := range criteria {
+		if importVariableMatchesCriteria(variable, c, aspectCache) {
+			result = append(result, model.PathOption{
+				Path:             strings.Join(currentPath, "."),
+				CharacteristicId: variable.CharacteristicId,
+				AspectNode: devicemodel.AspectNode{
+					Id: variable.AspectId,
+				},
+				FunctionId:  variable.FunctionId,
+				IsVoid:      false,
+				Type:        variable.Type,
+				Interaction: devicemodel.EVENT,
+			})
+			break
+		}
 	}
 	for _, sub := range variable.SubContentVariables {
-		temp, err := this.getImportPathOptions(token, sub, criteria, currentPath, aspectCache)
-		if err != nil {
-			return result, err
-		}
-		result = append(result, temp...)
+		result = append(result, getImportPathOptions(sub, criteria, currentPath, aspectCache)...)
 	}
-	return result, nil
+	return result
 }
 
-func (this *Controller) importVariableMatchesAllCriteria(token string, variable model.ImportContentVariable, criteria []devicemodel.FilterCriteria, cache *map[string]devicemodel.AspectNode) (match bool, err error) {
+// importVariablesMatchCriteria checks that every criterion matches one of variables or their sub variables,
+// like pathOptionsMatchLocalCriteria for devices; aspectCache must contain the aspects of criteria (see findImports)
+func importVariablesMatchCriteria(variables []model.ImportContentVariable, criteria model.FilterCriteriaAndSet, aspectCache map[string]devicemodel.AspectNode) bool {
 	for _, c := range criteria {
-		match, err = this.contentVariableContainsCriteria(&variable, c, token, cache)
-		if err != nil {
-			return false, err
-		}
-		if match {
-			return true, nil
+		if !slices.ContainsFunc(variables, func(variable model.ImportContentVariable) bool {
+			return importVariableTreeMatchesCriteria(variable, c, aspectCache)
+		}) {
+			return false
 		}
 	}
-	return false, nil
+	return true
 }
 
-func (this *Controller) getImportsByTypes(token string, typeIds []string) (result []model.Import, err error, code int) {
-	req, err := http.NewRequest("GET", this.config.ImportDeployUrl+"/instances?&limit=10000&offset=0&sort=name.asc", nil)
-	if err != nil {
-		debug.PrintStack()
-		return result, err, http.StatusInternalServerError
+func importVariableTreeMatchesCriteria(variable model.ImportContentVariable, criteria devicemodel.FilterCriteria, aspectCache map[string]devicemodel.AspectNode) bool {
+	if importVariableMatchesCriteria(variable, criteria, aspectCache) {
+		return true
 	}
-	req.Header.Set("Authorization", token)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		debug.PrintStack()
-		return result, err, http.StatusInternalServerError
+	return slices.ContainsFunc(variable.SubContentVariables, func(sub model.ImportContentVariable) bool {
+		return importVariableTreeMatchesCriteria(sub, criteria, aspectCache)
+	})
+}
+
+// importVariableMatchesCriteria matches function-only criteria by function and function+aspect criteria by function and an aspect of criteria.AspectMatch.
+// import variables are never void and have no device class (see criteriaNeedDeviceClass).
+func importVariableMatchesCriteria(variable model.ImportContentVariable, criteria devicemodel.FilterCriteria, aspectCache map[string]devicemodel.AspectNode) bool {
+	if variable.FunctionId != criteria.FunctionId || !criteria.AcceptsValue(variable.Type, false) {
+		return false
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		debug.PrintStack()
-		return result, errors.New(buf.String()), resp.StatusCode
-	}
-	all := []model.Import{}
-	err = json.NewDecoder(resp.Body).Decode(&all)
-	if err != nil {
-		debug.PrintStack()
-		return result, err, http.StatusInternalServerError
+	if criteria.AspectId == "" || variable.AspectId == criteria.AspectId {
+		return true
 	}
+	return listContains(criteria.AspectMatch.AspectIds(aspectCache[criteria.AspectId]), variable.AspectId)
+}
 
-	for _, instance := range all {
-		for _, typeId := range typeIds {
-			if typeId == instance.ImportTypeId {
-				result = append(result, instance)
-				break
-			}
-		}
-	}
+// criteriaNeedDeviceClass checks for mandatory criteria with device_class_id.
+// import types and their content variables carry no device class, so no import matches such criteria and imports are skipped;
+// devices and device-groups are still selected. optional criteria and exclusions with a device class never match imports.
+func criteriaNeedDeviceClass(criteria model.FilterCriteriaAndSet) bool {
+	return slices.ContainsFunc(criteria, func(c devicemodel.FilterCriteria) bool {
+		return c.DeviceClassId != "" && !c.Exclude && !c.Optional
+	})
+}
 
-	return result, nil, http.StatusOK
+func (this *Controller) getImportsByTypes(ctx context.Context, token string, typeIds []string) (result []model.Import, err error, code int) {
+	return this.importdeploy.ListInstances(ctx, token, typeIds)
 }
 
-func (this *Controller) getFullImportType(token string, id string) (fullType model.ImportType, err error) {
-	err = this.cache.Use(id, func() (interface{}, error) {
-		var result model.ImportType
-		req, err := http.NewRequest("GET", this.config.ImportRepoUrl+"/import-types/"+id, nil)
+func (this *Controller) getFullImportType(ctx context.Context, token string, id string) (fullType model.ImportType, err error) {
+	err = this.cache.Use(ctx, id, func(ctx context.Context) (interface{}, error) {
+		jwtToken, err := jwt.Parse(token)
 		if err != nil {
-			debug.PrintStack()
-			return result, err
+			return nil, err
 		}
-		req.Header.Set("Authorization", token)
-		resp, err := http.DefaultClient.Do(req)
+		result, err, _ := this.importrepo.ReadImportType(ctx, jwtToken, id)
 		if err != nil {
-			debug.PrintStack()
-			return result, err
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode >= 300 {
-			buf := new(bytes.Buffer)
-			buf.ReadFrom(resp.Body)
-			debug.PrintStack()
-			return result, errors.New(buf.String())
+			return nil, err
 		}
-
-		err = json.NewDecoder(resp.Body).Decode(&result)
-		if err != nil {
-			debug.PrintStack()
-			return result, err
-		}
-
-		return result, nil
+		return castImportType(result), nil
 	}, &fullType)
 
 	return
@@ -312,6 +271,22 @@ func castImportTypeContentVariable(cv importrepomodel.ContentVariable) model.Imp
 	}
 }
 
+// asContentVariable converts variable for forEachContentVariable; only name, type, characteristic, function and aspect are kept
+func asContentVariable(variable model.ImportContentVariable) models.ContentVariable {
+	sub := []models.ContentVariable{}
+	for _, s := range variable.SubContentVariables {
+		sub = append(sub, asContentVariable(s))
+	}
+	return models.ContentVariable{
+		Name:                variable.Name,
+		Type:                variable.Type,
+		CharacteristicId:    variable.CharacteristicId,
+		SubContentVariables: sub,
+		FunctionId:          variable.FunctionId,
+		AspectId:            variable.AspectId,
+	}
+}
+
 func castImportTypeConfigs(configs []importrepomodel.ImportConfig) (result []model.ImportTypeConfig) {
 	if configs != nil {
 		result = []model.ImportTypeConfig{}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// GetTokenOnBehalfOf returns a token of userId, that lets the device-repository evaluate permissions and local device ids as for this user.
+// the caller must be admin and its token must be verified (auth_verification=jwks); unverified tokens are only parsed for the audit log.
+func (this *Controller) GetTokenOnBehalfOf(ctx context.Context, callerToken string, userId string) (token string, caller jwt.Token, err error, code int) {
+	if this.tokenExchange == nil {
+		return "", caller, errors.New("on behalf of requests are not configured (auth_endpoint, auth_client_id, auth_client_secret)"), http.StatusNotImplemented
+	}
+	caller, ok := auth.VerifiedTokenFromContext(ctx)
+	if !ok {
+		caller, _ = jwt.Parse(callerToken)
+		return "", caller, errors.New("on behalf of requests need a verified token (auth_verification=jwks)"), http.StatusForbidden
+	}
+	if !caller.IsAdmin() {
+		return "", caller, errors.New("only admins may send requests on behalf of other users"), http.StatusForbidden
+	}
+	userToken, err := this.tokenExchange.GetUserToken(ctx, userId)
+	if err != nil {
+		return "", caller, fmt.Errorf("unable to get token of user %v: %w", userId, err), http.StatusBadGateway
+	}
+	return userToken.Jwt(), caller, nil, http.StatusOK
+}
@@ -0,0 +1,368 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func rankingRequested(options GetFilteredDevicesV2Options) bool {
+	return options.PreferredInteraction != "" || slices.ContainsFunc(options.FilterCriteria, func(c devicemodel.FilterCriteria) bool { return c.Optional })
+}
+
+// getRankedFilteredDevicesV2 returns the selectables of the mandatory criteria, extended by the path options of matched optional criteria and sorted by model.Ranking.Score.
+// the selectables are queried once; optional criteria are matched against the device-types, device-groups and import-types of the result (see matchOptionalCriterion).
+func (this *Controller) getRankedFilteredDevicesV2(ctx context.Context, token string, options GetFilteredDevicesV2Options, devicesByDeviceTypeCache *map[string][]models.ExtendedDevice) (result []model.Selectable, err error, code int) {
+	if err = validateCriteria(options.FilterCriteria); err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	switch options.PreferredInteraction {
+	case "", devicemodel.EVENT, devicemodel.REQUEST:
+	default:
+		return result, fmt.Errorf("invalid preferred_interaction %v (expected event or request)", options.PreferredInteraction), http.StatusBadRequest
+	}
+	mandatory := model.FilterCriteriaAndSet{}
+	for _, c := range options.FilterCriteria {
+		if !c.Optional {
+			mandatory = append(mandatory, c)
+		}
+	}
+	if len(mandatory) == 0 {
+		return result, errors.New("at least one criterion must not be optional"), http.StatusBadRequest
+	}
+	//owned devices are those of the token subject, like the permissions of the device-repository; on behalf of requests use the token of the other user
+	jwtToken, err := jwt.Parse(token)
+	if err != nil {
+		return result, err, http.StatusUnauthorized
+	}
+	userId := jwtToken.GetUserId()
+
+	baseOptions := options
+	baseOptions.FilterCriteria = mandatory
+	baseOptions.PreferredInteraction = ""
+	result, err, code = this.getFilteredDevicesV2(ctx, token, baseOptions, devicesByDeviceTypeCache)
+	if err != nil {
+		return result, err, code
+	}
+	ranking := &rankingContext{
+		deviceTypes:                map[string]devicemodel.DeviceType{},
+		aspects:                    map[string]devicemodel.AspectNode{},
+		importPathTrimFirstElement: options.ImportPathTrimFirstElement,
+		filterIncompatible:         options.FilterIncompatibleCharacteristics,
+	}
+	ranking.groups, err, code = this.getDeviceGroupsOfSelectables(ctx, token, result)
+	if err != nil {
+		return result, err, code
+	}
+	matches := make([][]model.CriterionMatch, len(result))
+	for index, c := range options.FilterCriteria {
+		if c.Exclude {
+			continue //exclusions only remove selectables and are not part of the ranking
+		}
+		optionalCriterion := c
+		optionalCriterion.Optional = false
+		optionalCriterion.Weight = 0
+		if c.Optional && c.FunctionId != "" && slices.ContainsFunc(result, func(selectable model.Selectable) bool { return selectable.Device != nil }) {
+			ranking.deviceTypeMatches, err = this.getDeviceTypeMatches(ctx, token, optionalCriterion, options.IncludeIdModified)
+			if err != nil {
+				return result, err, http.StatusInternalServerError
+			}
+		}
+		for i, selectable := range result {
+			match := model.CriterionMatch{Index: index, Criterion: c, Matched: !c.Optional}
+			if c.Optional {
+				matched, other, err := this.matchOptionalCriterion(ctx, token, selectable, optionalCriterion, ranking)
+				if err != nil {
+					return result, err, http.StatusInternalServerError
+				}
+				if matched {
+					match.Matched = true
+					match.Score = c.GetWeight()
+					result[i] = mergeSelectables(result[i], other)
+				}
+			}
+			matches[i] = append(matches[i], match)
+		}
+	}
+	for i, selectable := range result {
+		ranking := &model.Ranking{Criteria: matches[i]}
+		for _, match := range ranking.Criteria {
+			ranking.Score = ranking.Score + match.Score
+		}
+		if options.PreferredInteraction != "" && selectableHasInteraction(selectable, options.PreferredInteraction) {
+			ranking.PreferredInteraction = true
+			ranking.Score = ranking.Score + model.PreferredInteractionScore
+		}
+		if selectable.Device != nil && userId != "" && selectable.Device.OwnerId == userId {
+			ranking.Owned = true
+			ranking.Score = ranking.Score + model.OwnedDeviceScore
+		}
+		result[i].Ranking = ranking
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Ranking.Score > result[j].Ranking.Score
+	})
+	return result, nil, http.StatusOK
+}
+
+// rankingContext caches what matchOptionalCriterion needs for the selectables of one request
+type rankingContext struct {
+	deviceTypes                map[string]devicemodel.DeviceType
+	groups                     map[string]models.DeviceGroup
+	aspects                    map[string]devicemodel.AspectNode
+	deviceTypeMatches          map[string]model.Selectable //services and path options of the device-types matching the current optional criterion
+	importPathTrimFirstElement bool
+	filterIncompatible         bool
+}
+
+// getDeviceGroupsOfSelectables reads the criteria of all device-groups in selectables with one request
+func (this *Controller) getDeviceGroupsOfSelectables(ctx context.Context, token string, selectables []model.Selectable) (result map[string]models.DeviceGroup, err error, code int) {
+	result = map[string]models.DeviceGroup{}
+	ids := []string{}
+	for _, selectable := range selectables {
+		if selectable.DeviceGroup != nil {
+			ids = append(ids, selectable.DeviceGroup.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return result, nil, http.StatusOK
+	}
+	groups, _, err, code := this.devicerepo.ListDeviceGroups(ctx, token, client.DeviceGroupListOptions{
+		Ids:        ids,
+		Limit:      int64(len(ids)),
+		Permission: client.EXECUTE,
+	})
+	if err != nil {
+		return result, err, code
+	}
+	for _, group := range groups {
+		result[group.Id] = group
+	}
+	return result, nil, http.StatusOK
+}
+
+// matchOptionalCriterion checks if selectable matches criterion (with Optional unset).
+// devices match with the path options the device-repository lists for their device-type and criterion (see getDeviceTypeMatches),
+// imports with a path option of their type accepted by criterion.MatchesPathOption; these path options are returned in other
+// and annotated like the path options of mandatory criteria. device-groups match with their criteria.
+func (this *Controller) matchOptionalCriterion(ctx context.Context, token string, selectable model.Selectable, criterion devicemodel.FilterCriteria, ranking *rankingContext) (matched bool, other model.Selectable, err error) {
+	switch {
+	case selectable.Device != nil:
+		deviceType, err := this.getCachedDeviceType(ctx, token, selectable.Device.DeviceTypeId, &ranking.deviceTypes)
+		if err != nil {
+			return false, other, err
+		}
+		if criterion.DeviceClassId != "" && criterion.DeviceClassId != deviceType.DeviceClassId {
+			return false, other, nil
+		}
+		if criterion.FunctionId == "" {
+			return true, other, nil
+		}
+		other = ranking.deviceTypeMatches[selectable.Device.DeviceTypeId]
+	case selectable.Import != nil && selectable.ImportType != nil:
+		//like for mandatory criteria, imports have no device-class
+		if criterion.FunctionId == "" || criterion.DeviceClassId != "" || criterion.Interaction == string(devicemodel.REQUEST) {
+			return false, other, nil
+		}
+		other, err = this.getImportTypePathOptions(ctx, token, *selectable.ImportType, criterion, ranking)
+		if err != nil {
+			return false, other, err
+		}
+	case selectable.DeviceGroup != nil:
+		group, ok := ranking.groups[selectable.DeviceGroup.Id]
+		if !ok {
+			return false, other, nil
+		}
+		matched, err = this.deviceGroupMatchesCriterion(ctx, token, group, criterion, &ranking.aspects)
+		return matched, other, err
+	default:
+		return false, other, nil
+	}
+	if len(other.ServicePathOptions) == 0 {
+		return false, other, nil
+	}
+	annotated, err := this.annotateCharacteristics(ctx, token, []model.Selectable{other}, model.FilterCriteriaAndSet{criterion}, ranking.filterIncompatible)
+	if err != nil || len(annotated) == 0 {
+		return false, other, err
+	}
+	annotated, err = this.annotateConfigurables(ctx, token, annotated, model.FilterCriteriaAndSet{criterion})
+	if err != nil || len(annotated) == 0 {
+		return false, other, err
+	}
+	return true, annotated[0], nil
+}
+
+// getDeviceTypeMatches returns the services and path options of the device-types matching criterion by device-type id, as listed by the device-repository
+func (this *Controller) getDeviceTypeMatches(ctx context.Context, token string, criterion devicemodel.FilterCriteria, includeIdModified bool) (result map[string]model.Selectable, err error) {
+	deviceTypeSelectables, err := this.GetDeviceTypeSelectablesCachedV2(ctx, token, model.FilterCriteriaAndSet{criterion}, includeIdModified)
+	if err != nil {
+		return result, err
+	}
+	result = map[string]model.Selectable{}
+	for _, dtSelectable := range deviceTypeSelectables {
+		services, pathOptions, ok := getDeviceTypeSelectableServices(dtSelectable, model.FilterCriteriaAndSet{criterion})
+		if ok {
+			result[dtSelectable.DeviceTypeId] = model.Selectable{Services: services, ServicePathOptions: pathOptions}
+		}
+	}
+	return result, nil
+}
+
+// getImportTypePathOptions returns the path options of importType matching criterion like getFilteredImportsV2
+func (this *Controller) getImportTypePathOptions(ctx context.Context, token string, importType model.ImportType, criterion devicemodel.FilterCriteria, ranking *rankingContext) (result model.Selectable, err error) {
+	if criterion.AspectId != "" {
+		_, err = this.getAspectNodeWithCache(ctx, token, &ranking.aspects, criterion.AspectId)
+		if err != nil {
+			return result, err
+		}
+	}
+	var pathOptions []model.PathOption
+	if ranking.importPathTrimFirstElement {
+		for _, sub := range importType.Output.SubContentVariables {
+			pathOptions = append(pathOptions, getImportPathOptions(sub, model.FilterCriteriaAndSet{criterion}, nil, ranking.aspects)...)
+		}
+	} else {
+		pathOptions = getImportPathOptions(importType.Output, model.FilterCriteriaAndSet{criterion}, nil, ranking.aspects)
+	}
+	if len(pathOptions) > 0 {
+		result.ServicePathOptions = map[string][]model.PathOption{importType.Id: pathOptions}
+	}
+	return result, nil
+}
+
+// deviceGroupMatchesCriterion checks the criteria of group like the device-repository and deviceGroupMatchesAspectCriteria
+func (this *Controller) deviceGroupMatchesCriterion(ctx context.Context, token string, group models.DeviceGroup, criterion devicemodel.FilterCriteria, aspectCache *map[string]devicemodel.AspectNode) (bool, error) {
+	interaction := models.Interaction(criterion.Interaction)
+	if interaction == models.EVENT_AND_REQUEST {
+		interaction = ""
+	}
+	if !slices.ContainsFunc(group.Criteria, func(groupCriterion models.DeviceGroupFilterCriteria) bool {
+		return (criterion.FunctionId == "" || groupCriterion.FunctionId == criterion.FunctionId) &&
+			(criterion.DeviceClassId == "" || groupCriterion.DeviceClassId == criterion.DeviceClassId) &&
+			(interaction == "" || groupCriterion.Interaction == interaction)
+	}) {
+		return false, nil
+	}
+	if criterion.AspectId == "" {
+		return true, nil
+	}
+	aspects, err := this.getDeviceGroupAspects(ctx, token, group, criterion.FunctionId, interaction, aspectCache)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(aspects, func(aspect devicemodel.AspectNode) bool {
+		return criterion.AspectMatch.Matches(criterion.AspectId, aspect)
+	}), nil
+}
+
+// forEachContentVariable calls f for variable and all sub variables with the path of names joined by "."
+func forEachContentVariable(variable models.ContentVariable, parentPath string, f func(variable models.ContentVariable, path string) error) error {
+	path := variable.Name
+	if parentPath != "" {
+		path = parentPath + "." + variable.Name
+	}
+	if err := f(variable, path); err != nil {
+		return err
+	}
+	for _, sub := range variable.SubContentVariables {
+		if err := forEachContentVariable(sub, path, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errStopWalk ends forEachContentVariable early without an error (see anyContentVariable)
+var errStopWalk = errors.New("stop content variable walk")
+
+// anyContentVariable checks if match returns true for a content variable of contents or one of its sub variables
+func anyContentVariable(contents []models.Content, match func(variable models.ContentVariable) (bool, error)) (bool, error) {
+	for _, content := range contents {
+		err := forEachContentVariable(content.ContentVariable, "", func(variable models.ContentVariable, path string) error {
+			matched, err := match(variable)
+			if err == nil && matched {
+				return errStopWalk
+			}
+			return err
+		})
+		if errors.Is(err, errStopWalk) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// mergeSelectables adds the services and path options of other to selectable
+func mergeSelectables(selectable model.Selectable, other model.Selectable) model.Selectable {
+	services := slices.Clone(selectable.Services)
+	for _, service := range other.Services {
+		if !slices.ContainsFunc(services, func(existing devicemodel.Service) bool { return existing.Id == service.Id }) {
+			services = append(services, service)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Id < services[j].Id
+	})
+	selectable.Services = services
+	if len(other.ServicePathOptions) > 0 {
+		pathOptions := map[string][]model.PathOption{}
+		for serviceId, list := range selectable.ServicePathOptions {
+			pathOptions[serviceId] = slices.Clone(list)
+		}
+		for serviceId, list := range other.ServicePathOptions {
+			for _, option := range list {
+				if !slices.ContainsFunc(pathOptions[serviceId], func(existing model.PathOption) bool {
+					return existing.Path == option.Path && existing.FunctionId == option.FunctionId && existing.AspectNode.Id == option.AspectNode.Id
+				}) {
+					pathOptions[serviceId] = append(pathOptions[serviceId], option)
+				}
+			}
+		}
+		selectable.ServicePathOptions = pathOptions
+	}
+	return selectable
+}
+
+func selectableHasInteraction(selectable model.Selectable, interaction devicemodel.Interaction) bool {
+	for _, service := range selectable.Services {
+		if service.Interaction == interaction || service.Interaction == devicemodel.EVENT_AND_REQUEST {
+			return true
+		}
+	}
+	for _, list := range selectable.ServicePathOptions {
+		for _, option := range list {
+			if option.Interaction == interaction || option.Interaction == devicemodel.EVENT_AND_REQUEST {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/upstream"
+)
+
+func newResiliencePolicy(config configuration.Config) (result upstream.ResiliencePolicy, err error) {
+	result = upstream.ResiliencePolicy{
+		MaxRetries:              int(config.UpstreamMaxRetries),
+		BreakerFailureThreshold: int(config.UpstreamBreakerFailureThreshold),
+		StaleFallback:           config.UpstreamStaleFallback,
+	}
+	result.InitialBackoff, err = parseDurationOrDefault(config.UpstreamRetryBackoff, 0)
+	if err != nil {
+		return result, fmt.Errorf("invalid upstream_retry_backoff: %w", err)
+	}
+	result.MaxBackoff, err = parseDurationOrDefault(config.UpstreamRetryMaxBackoff, 0)
+	if err != nil {
+		return result, fmt.Errorf("invalid upstream_retry_max_backoff: %w", err)
+	}
+	result.BreakerOpenDuration, err = parseDurationOrDefault(config.UpstreamBreakerOpenDuration, 0)
+	if err != nil {
+		return result, fmt.Errorf("invalid upstream_breaker_open_duration: %w", err)
+	}
+	result.StaleFallbackTtl, err = parseDurationOrDefault(config.UpstreamStaleFallbackTtl, 0)
+	if err != nil {
+		return result, fmt.Errorf("invalid upstream_stale_fallback_ttl: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/limits"
+)
+
+// StatusClientClosedRequest is used when the caller canceled the request before an upstream call finished (nginx convention)
+const StatusClientClosedRequest = 499
+
+// ContextErrorCode returns the http status code matching a context error
+func ContextErrorCode(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return StatusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// WithTimeout derives a context limited by timeout; a timeout <= 0 only inherits the deadline of ctx
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// ErrCallAbandoned marks calls that were still running when the caller stopped waiting for them
+var ErrCallAbandoned = errors.New("upstream call abandoned")
+
+var ErrTooManyAbandonedCalls = errors.New("too many abandoned upstream calls")
+
+// AbandonedCalls counts the calls of one upstream service that are still running after await returned.
+// the device-repository and import-repository clients accept neither a context nor an http.Client, so a call can not be canceled.
+// if limit > 0 and limit calls are running unobserved, new calls are rejected until some of them finished.
+// a nil *AbandonedCalls does not limit calls.
+type AbandonedCalls struct {
+	limit int64
+	count atomic.Int64
+}
+
+func NewAbandonedCalls(limit int64) *AbandonedCalls {
+	return &AbandonedCalls{limit: limit}
+}
+
+// Count returns the number of abandoned calls that are still running
+func (this *AbandonedCalls) Count() int64 {
+	if this == nil {
+		return 0
+	}
+	return this.count.Load()
+}
+
+func (this *AbandonedCalls) exhausted() bool {
+	return this != nil && this.limit > 0 && this.count.Load() >= this.limit
+}
+
+func (this *AbandonedCalls) add(delta int64) {
+	if this != nil {
+		this.count.Add(delta)
+	}
+}
+
+type result[T any] struct {
+	value T
+	total int64
+	err   error
+	code  int
+}
+
+const (
+	callRunning int32 = iota
+	callFinished
+	callAbandoned
+)
+
+// await runs call in its own goroutine and returns as soon as the call finished or ctx is done.
+// the call itself is not canceled (see AbandonedCalls); the request that started it is released immediately
+// with an error wrapping ErrCallAbandoned and the context error.
+// every started call is charged to the upstream call budget of the request.
+func await[T any](ctx context.Context, service string, timeout time.Duration, abandoned *AbandonedCalls, call func() result[T]) result[T] {
+	ctx, cancel := WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return result[T]{err: fmt.Errorf("%v call not started: %w", service, err), code: ContextErrorCode(err)}
+	}
+	if abandoned.exhausted() {
+		return result[T]{err: fmt.Errorf("%v call not started: %w", service, ErrTooManyAbandonedCalls), code: http.StatusServiceUnavailable}
+	}
+	if err := limits.Spend(ctx); err != nil {
+		return result[T]{err: err, code: limits.StatusCode}
+	}
+	state := atomic.Int32{}
+	done := make(chan result[T], 1)
+	go func() {
+		r := call()
+		if !state.CompareAndSwap(callRunning, callFinished) {
+			abandoned.add(-1)
+		}
+		done <- r
+	}()
+	select {
+	case r := <-done:
+		return r
+	case <-ctx.Done():
+		abandoned.add(1)
+		if !state.CompareAndSwap(callRunning, callAbandoned) {
+			//finished while ctx ended
+			abandoned.add(-1)
+			return <-done
+		}
+		return result[T]{err: fmt.Errorf("%v call aborted: %w: %w", service, ErrCallAbandoned, ctx.Err()), code: ContextErrorCode(ctx.Err())}
+	}
+}
+
+func awaitCall[T any](ctx context.Context, service string, timeout time.Duration, abandoned *AbandonedCalls, call func() (T, error, int)) (T, error, int) {
+	r := await(ctx, service, timeout, abandoned, func() result[T] {
+		value, err, code := call()
+		return result[T]{value: value, err: err, code: code}
+	})
+	return r.value, r.err, r.code
+}
+
+func awaitListCall[T any](ctx context.Context, service string, timeout time.Duration, abandoned *AbandonedCalls, call func() (T, int64, error, int)) (T, int64, error, int) {
+	r := await(ctx, service, timeout, abandoned, func() result[T] {
+		value, total, err, code := call()
+		return result[T]{value: value, total: total, err: err, code: code}
+	})
+	return r.value, r.total, r.err, r.code
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAwaitCall(t *testing.T) {
+	t.Run("finished", func(t *testing.T) {
+		value, err, code := awaitCall(context.Background(), "test", time.Second, nil, func() (string, error, int) {
+			return "foo", nil, http.StatusOK
+		})
+		if value != "foo" || err != nil || code != http.StatusOK {
+			t.Error(value, err, code)
+		}
+	})
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err, code := awaitCall(context.Background(), "test", 50*time.Millisecond, nil, func() (string, error, int) {
+			time.Sleep(time.Second)
+			return "foo", nil, http.StatusOK
+		})
+		if !errors.Is(err, context.DeadlineExceeded) || code != http.StatusGatewayTimeout {
+			t.Error(err, code)
+		}
+		if time.Since(start) > 500*time.Millisecond {
+			t.Error("call was not abandoned", time.Since(start))
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		called := false
+		_, _, err, code := awaitListCall(ctx, "test", 0, nil, func() ([]string, int64, error, int) {
+			called = true
+			return nil, 0, nil, http.StatusOK
+		})
+		if !errors.Is(err, context.Canceled) || code != StatusClientClosedRequest {
+			t.Error(err, code)
+		}
+		if called {
+			t.Error("call should not be started with canceled context")
+		}
+	})
+}
+
+func TestAwaitAbandonedCalls(t *testing.T) {
+	abandoned := NewAbandonedCalls(1)
+	release := make(chan struct{})
+	_, err, code := awaitCall(context.Background(), "test", 20*time.Millisecond, abandoned, func() (string, error, int) {
+		<-release
+		return "foo", nil, http.StatusOK
+	})
+	if !errors.Is(err, ErrCallAbandoned) || !errors.Is(err, context.DeadlineExceeded) || code != http.StatusGatewayTimeout {
+		t.Error(err, code)
+	}
+	if abandoned.Count() != 1 {
+		t.Error(abandoned.Count())
+	}
+
+	called := false
+	_, err, code = awaitCall(context.Background(), "test", time.Second, abandoned, func() (string, error, int) {
+		called = true
+		return "foo", nil, http.StatusOK
+	})
+	if !errors.Is(err, ErrTooManyAbandonedCalls) || code != http.StatusServiceUnavailable || called {
+		t.Error("call should be rejected while the limit of abandoned calls is reached", err, code, called)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for abandoned.Count() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if abandoned.Count() != 0 {
+		t.Fatal("finished call is still counted", abandoned.Count())
+	}
+	value, err, code := awaitCall(context.Background(), "test", time.Second, abandoned, func() (string, error, int) {
+		return "foo", nil, http.StatusOK
+	})
+	if value != "foo" || err != nil || code != http.StatusOK {
+		t.Error(value, err, code)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+	"time"
+
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
+	"github.com/SENERGY-Platform/models/go/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const DeviceRepositoryName = "device-repository"
+
+// DeviceRepository is the part of the device-repository api used by the controller.
+// in contrast to client.Interface every call receives the context of the request that caused it.
+type DeviceRepository interface {
+	ReadDevice(ctx context.Context, id string, token string, action client.AuthAction) (result models.Device, err error, code int)
+	ListDevices(ctx context.Context, token string, options client.DeviceListOptions) (result []models.Device, err error, code int)
+	ListExtendedDevices(ctx context.Context, token string, options client.ExtendedDeviceListOptions) (result []models.ExtendedDevice, total int64, err error, code int)
+	ReadDeviceType(ctx context.Context, id string, token string) (result models.DeviceType, err error, code int)
+	ListDeviceTypesV3(ctx context.Context, token string, options client.DeviceTypeListOptions) (result []models.DeviceType, total int64, err error, code int)
+	GetDeviceTypeSelectables(ctx context.Context, query []client.FilterCriteria, pathPrefix string, interactionsFilter []models.Interaction, includeModified bool) (result []devicemodel.DeviceTypeSelectable, err error, code int)
+	GetDeviceTypeSelectablesV2(ctx context.Context, query []client.FilterCriteria, pathPrefix string, includeModified bool, servicesMustMatchAllCriteria bool) (result []devicemodel.DeviceTypeSelectable, err error, code int)
+	ListDeviceGroups(ctx context.Context, token string, options client.DeviceGroupListOptions) (result []models.DeviceGroup, total int64, err error, code int)
+	GetFunctionsByType(ctx context.Context, rdfType string) (result []models.Function, err error, code int)
+	GetAspectNode(ctx context.Context, id string) (result models.AspectNode, err error, code int)
+	GetConceptWithoutCharacteristics(ctx context.Context, id string) (result models.Concept, err error, code int)
+	GetCharacteristic(ctx context.Context, id string) (result models.Characteristic, err error, code int)
+	ListProtocols(ctx context.Context, token string, limit int64, offset int64, sort string) (result []models.Protocol, err error, code int)
+}
+
+// NewDeviceRepository wraps a device-repository client and records a client span for every call.
+// calls return as soon as ctx is done or timeout (if > 0) elapsed, but keep running in the background (see AbandonedCalls).
+// forwarding the trace context to the device-repository is out of scope: the client accepts neither a context nor an http.Client
+// (see the Tracing section of the README).
+func NewDeviceRepository(repo client.Interface, timeout time.Duration, abandoned *AbandonedCalls) DeviceRepository {
+	return &DeviceRepositoryClient{repo: repo, timeout: timeout, abandoned: abandoned}
+}
+
+type DeviceRepositoryClient struct {
+	repo      client.Interface
+	timeout   time.Duration
+	abandoned *AbandonedCalls
+}
+
+func (this *DeviceRepositoryClient) ReadDevice(ctx context.Context, id string, token string, action client.AuthAction) (result models.Device, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "ReadDevice", attribute.String("device.id", id))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() (models.Device, error, int) {
+		return this.repo.ReadDevice(id, token, action)
+	})
+}
+
+func (this *DeviceRepositoryClient) ListDevices(ctx context.Context, token string, options client.DeviceListOptions) (result []models.Device, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "ListDevices")
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]models.Device, error, int) {
+		return this.repo.ListDevices(token, options)
+	})
+}
+
+func (this *DeviceRepositoryClient) ListExtendedDevices(ctx context.Context, token string, options client.ExtendedDeviceListOptions) (result []models.ExtendedDevice, total int64, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "ListExtendedDevices", attribute.StringSlice("device_type.ids", options.DeviceTypeIds), attribute.Int("device.ids.count", len(options.Ids)))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitListCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]models.ExtendedDevice, int64, error, int) {
+		return this.repo.ListExtendedDevices(token, options)
+	})
+}
+
+func (this *DeviceRepositoryClient) ReadDeviceType(ctx context.Context, id string, token string) (result models.DeviceType, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "ReadDeviceType", attribute.String("device_type.id", id))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() (models.DeviceType, error, int) {
+		return this.repo.ReadDeviceType(id, token)
+	})
+}
+
+func (this *DeviceRepositoryClient) ListDeviceTypesV3(ctx context.Context, token string, options client.DeviceTypeListOptions) (result []models.DeviceType, total int64, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "ListDeviceTypesV3", attribute.Int("criteria.count", len(options.Criteria)))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitListCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]models.DeviceType, int64, error, int) {
+		return this.repo.ListDeviceTypesV3(token, options)
+	})
+}
+
+func (this *DeviceRepositoryClient) GetDeviceTypeSelectables(ctx context.Context, query []client.FilterCriteria, pathPrefix string, interactionsFilter []models.Interaction, includeModified bool) (result []devicemodel.DeviceTypeSelectable, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "GetDeviceTypeSelectables", attribute.Int("criteria.count", len(query)))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]devicemodel.DeviceTypeSelectable, error, int) {
+		return this.repo.GetDeviceTypeSelectables(query, pathPrefix, interactionsFilter, includeModified)
+	})
+}
+
+func (this *DeviceRepositoryClient) GetDeviceTypeSelectablesV2(ctx context.Context, query []client.FilterCriteria, pathPrefix string, includeModified bool, servicesMustMatchAllCriteria bool) (result []devicemodel.DeviceTypeSelectable, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "GetDeviceTypeSelectablesV2", attribute.Int("criteria.count", len(query)))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]devicemodel.DeviceTypeSelectable, error, int) {
+		return this.repo.GetDeviceTypeSelectablesV2(query, pathPrefix, includeModified, servicesMustMatchAllCriteria)
+	})
+}
+
+func (this *DeviceRepositoryClient) ListDeviceGroups(ctx context.Context, token string, options client.DeviceGroupListOptions) (result []models.DeviceGroup, total int64, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "ListDeviceGroups", attribute.Int("criteria.count", len(options.Criteria)))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitListCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]models.DeviceGroup, int64, error, int) {
+		return this.repo.ListDeviceGroups(token, options)
+	})
+}
+
+func (this *DeviceRepositoryClient) GetFunctionsByType(ctx context.Context, rdfType string) (result []models.Function, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "GetFunctionsByType", attribute.String("function.rdf_type", rdfType))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]models.Function, error, int) {
+		return this.repo.GetFunctionsByType(rdfType)
+	})
+}
+
+func (this *DeviceRepositoryClient) GetAspectNode(ctx context.Context, id string) (result models.AspectNode, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "GetAspectNode", attribute.String("aspect.id", id))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() (models.AspectNode, error, int) {
+		return this.repo.GetAspectNode(id)
+	})
+}
+
+func (this *DeviceRepositoryClient) GetConceptWithoutCharacteristics(ctx context.Context, id string) (result models.Concept, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "GetConceptWithoutCharacteristics", attribute.String("concept.id", id))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() (models.Concept, error, int) {
+		return this.repo.GetConceptWithoutCharacteristics(id)
+	})
+}
+
+func (this *DeviceRepositoryClient) GetCharacteristic(ctx context.Context, id string) (result models.Characteristic, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "GetCharacteristic", attribute.String("characteristic.id", id))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() (models.Characteristic, error, int) {
+		return this.repo.GetCharacteristic(id)
+	})
+}
+
+func (this *DeviceRepositoryClient) ListProtocols(ctx context.Context, token string, limit int64, offset int64, sort string) (result []models.Protocol, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, DeviceRepositoryName, "ListProtocols")
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, DeviceRepositoryName, this.timeout, this.abandoned, func() ([]models.Protocol, error, int) {
+		return this.repo.ListProtocols(token, limit, offset, sort)
+	})
+}
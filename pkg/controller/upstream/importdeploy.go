@@ -0,0 +1,187 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
+	"github.com/SENERGY-Platform/device-selection/pkg/limits"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
+	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ImportDeployName identifies import-deploy in spans, logs and health reports.
+// import-deploy has no client library; NewImportDeploy calls its http api directly.
+const ImportDeployName = "import-deploy"
+
+// ImportDeployDefaultPageSize is used if NewImportDeploy receives a page size <= 0
+const ImportDeployDefaultPageSize = 500
+
+// ImportDeployDefaultMaxPages is used if NewImportDeploy receives a max page count <= 0
+const ImportDeployDefaultMaxPages = 20
+
+// ErrImportDeployMaxPages is returned if a listing needs more than the max page count; the request fails instead of returning incomplete imports.
+var ErrImportDeployMaxPages = errors.New("import-deploy listing exceeds import_deploy_max_pages")
+
+// ImportDeploy is the part of the import-deploy api used by the controller
+type ImportDeploy interface {
+	// ListInstances returns the instances of the given import types, sorted by name
+	ListInstances(ctx context.Context, token string, importTypeIds []string) (result []model.Import, err error, code int)
+}
+
+// NewImportDeploy calls the import-deploy api at url; every page request is canceled after timeout (if > 0)
+func NewImportDeploy(httpClient *http.Client, url string, timeout time.Duration, pageSize int64, maxPages int64) ImportDeploy {
+	if pageSize <= 0 {
+		pageSize = ImportDeployDefaultPageSize
+	}
+	if maxPages <= 0 {
+		maxPages = ImportDeployDefaultMaxPages
+	}
+	return &ImportDeployClient{httpClient: httpClient, url: url, timeout: timeout, pageSize: pageSize, maxPages: maxPages}
+}
+
+type ImportDeployClient struct {
+	httpClient *http.Client
+	url        string
+	timeout    time.Duration
+	pageSize   int64
+	maxPages   int64
+}
+
+// ListInstances requests pages of pageSize until a page is incomplete or maxPages is reached.
+// import_type_ids is only a hint: import-deploy versions without this filter ignore it and list every instance of the user,
+// so the filter is repeated locally and maxPages bounds the requests.
+func (this *ImportDeployClient) ListInstances(ctx context.Context, token string, importTypeIds []string) (result []model.Import, err error, code int) {
+	result = []model.Import{}
+	if len(importTypeIds) == 0 {
+		return result, nil, http.StatusOK
+	}
+	ctx, span := tracing.StartUpstream(ctx, ImportDeployName, "ListInstances", attribute.Int("import_type.count", len(importTypeIds)))
+	defer func() { tracing.Finish(span, err) }()
+	isRequested := map[string]bool{}
+	for _, id := range importTypeIds {
+		isRequested[id] = true
+	}
+	query := url.Values{}
+	query.Set("limit", strconv.FormatInt(this.pageSize, 10))
+	query.Set("sort", "name.asc")
+	query.Set("import_type_ids", strings.Join(importTypeIds, ","))
+	for pages := int64(0); ; pages++ {
+		if pages >= this.maxPages {
+			return result, ErrImportDeployMaxPages, http.StatusBadGateway
+		}
+		query.Set("offset", strconv.FormatInt(pages*this.pageSize, 10))
+		var page []model.Import
+		page, err, code = this.listPage(ctx, token, query)
+		if err != nil {
+			return result, err, code
+		}
+		for _, instance := range page {
+			if isRequested[instance.ImportTypeId] {
+				result = append(result, instance)
+			}
+		}
+		if int64(len(page)) < this.pageSize {
+			return result, nil, http.StatusOK
+		}
+	}
+}
+
+func (this *ImportDeployClient) listPage(ctx context.Context, token string, query url.Values) (result []model.Import, err error, code int) {
+	if err = limits.Spend(ctx); err != nil {
+		return result, err, limits.StatusCode
+	}
+	ctx, cancel := WithTimeout(ctx, this.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", this.url+"/instances?"+query.Encode(), nil)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	req.Header.Set("Authorization", token)
+	resp, err := this.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, err, ContextErrorCode(ctx.Err())
+		}
+		return result, err, http.StatusInternalServerError
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		return result, errors.New(buf.String()), resp.StatusCode
+	}
+	result = []model.Import{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// NewCachedImportDeploy caches results of deploy per user and import types for expiration; expiration <= 0 disables the cache.
+// calls with the verified token of the request (auth.VerifiedTokenFromContext) are cached per subject;
+// other tokens (unverified or on behalf of another user) are cached per sha256 hash of the token, which import-deploy checks on every uncached call.
+// import instances are not covered by the cache invalidation, so expiration should be short.
+func NewCachedImportDeploy(deploy ImportDeploy, expiration time.Duration) ImportDeploy {
+	if expiration <= 0 {
+		return deploy
+	}
+	return &CachedImportDeploy{deploy: deploy, expiration: expiration, cache: cache.New(expiration, expiration)}
+}
+
+type CachedImportDeploy struct {
+	deploy     ImportDeploy
+	expiration time.Duration
+	cache      *cache.Cache
+}
+
+func (this *CachedImportDeploy) ListInstances(ctx context.Context, token string, importTypeIds []string) (result []model.Import, err error, code int) {
+	user := ""
+	if verified, ok := auth.VerifiedTokenFromContext(ctx); ok && verified.Sub != "" && verified.Jwt() == token {
+		user = verified.Sub
+	} else {
+		hash := sha256.Sum256([]byte(token))
+		user = "token:" + hex.EncodeToString(hash[:])
+	}
+	ids := slices.Clone(importTypeIds)
+	slices.Sort(ids)
+	key := user + "/" + strings.Join(slices.Compact(ids), ",")
+	if cached, ok := this.cache.Get(key); ok {
+		return slices.Clone(cached.([]model.Import)), nil, http.StatusOK
+	}
+	result, err, code = this.deploy.ListInstances(ctx, token, importTypeIds)
+	if err != nil {
+		return result, err, code
+	}
+	this.cache.Set(key, result, this.expiration)
+	return result, nil, http.StatusOK
+}
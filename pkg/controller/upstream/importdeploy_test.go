@@ -0,0 +1,129 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/auth"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func testToken(user string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return "Bearer " + encode([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + encode([]byte(`{"sub":"`+user+`"}`)) + "."
+}
+
+// verified returns a context like pkg/api/util.AuthMiddleware after a successful verification of token
+func verified(token string) context.Context {
+	parsed, _ := jwt.Parse(token)
+	return auth.WithVerifiedToken(context.Background(), parsed)
+}
+
+func TestImportDeployClient(t *testing.T) {
+	instances := []model.Import{}
+	for i := 0; i < 7; i++ {
+		instances = append(instances, model.Import{Id: "i" + strconv.Itoa(i), ImportTypeId: "t" + strconv.Itoa(i%3)})
+	}
+	requests := atomic.Int64{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		if request.URL.Query().Get("import_type_ids") != "t0,t1" {
+			t.Error("unexpected import_type_ids", request.URL.Query().Get("import_type_ids"))
+		}
+		//ignores import_type_ids like older import-deploy versions
+		offset, _ := strconv.Atoi(request.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(request.URL.Query().Get("limit"))
+		page := instances[min(offset, len(instances)):min(offset+limit, len(instances))]
+		json.NewEncoder(writer).Encode(page)
+	}))
+	defer server.Close()
+
+	deploy := NewCachedImportDeploy(NewImportDeploy(server.Client(), server.URL, time.Second, 2, 10), time.Minute)
+
+	ids := func(result []model.Import) string {
+		temp := []string{}
+		for _, instance := range result {
+			temp = append(temp, instance.Id)
+		}
+		return strings.Join(temp, ",")
+	}
+
+	result, err, _ := deploy.ListInstances(verified(testToken("user1")), testToken("user1"), []string{"t0", "t1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ids(result) != "i0,i1,i3,i4,i6" {
+		t.Error(ids(result))
+	}
+	if requests.Load() != 4 {
+		t.Error("expected 4 pages", requests.Load())
+	}
+
+	result, err, _ = deploy.ListInstances(verified(testToken("user1")), testToken("user1"), []string{"t0", "t1"})
+	if err != nil || ids(result) != "i0,i1,i3,i4,i6" || requests.Load() != 4 {
+		t.Error("expected cached result", err, ids(result), requests.Load())
+	}
+
+	_, err, _ = deploy.ListInstances(verified(testToken("user2")), testToken("user2"), []string{"t0", "t1"})
+	if err != nil || requests.Load() != 8 {
+		t.Error("cache should be per user", err, requests.Load())
+	}
+
+	result, err, _ = deploy.ListInstances(context.Background(), testToken("user1"), []string{"t0", "t1"})
+	if err != nil || ids(result) != "i0,i1,i3,i4,i6" || requests.Load() != 12 {
+		t.Error("unverified tokens should not share the cache of verified tokens", err, ids(result), requests.Load())
+	}
+	result, err, _ = deploy.ListInstances(context.Background(), testToken("user1"), []string{"t0", "t1"})
+	if err != nil || ids(result) != "i0,i1,i3,i4,i6" || requests.Load() != 12 {
+		t.Error("expected cached result of the unverified token", err, ids(result), requests.Load())
+	}
+
+	//e.g. a token of another user, received by an on behalf of request
+	_, err, _ = deploy.ListInstances(verified(testToken("user1")), testToken("user3"), []string{"t0", "t1"})
+	if err != nil || requests.Load() != 16 {
+		t.Error("tokens other than the verified token should not use the cache of the verified user", err, requests.Load())
+	}
+	_, err, _ = deploy.ListInstances(context.Background(), testToken("user3"), []string{"t0", "t1"})
+	if err != nil || requests.Load() != 16 {
+		t.Error("expected cached result of the same token", err, requests.Load())
+	}
+
+	result, err, _ = deploy.ListInstances(verified(testToken("user1")), testToken("user1"), nil)
+	if err != nil || len(result) != 0 || requests.Load() != 16 {
+		t.Error("expected no request without import types", err, result, requests.Load())
+	}
+
+	requests.Store(0)
+	limited := NewImportDeploy(server.Client(), server.URL, time.Second, 2, 3)
+	_, err, code := limited.ListInstances(context.Background(), testToken("user1"), []string{"t0", "t1"})
+	if !errors.Is(err, ErrImportDeployMaxPages) || code != http.StatusBadGateway || requests.Load() != 3 {
+		t.Error("expected max pages error after 3 requests", err, code, requests.Load())
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+	"time"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
+	importrepo "github.com/SENERGY-Platform/import-repository/lib/client"
+	importrepomodel "github.com/SENERGY-Platform/import-repository/lib/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const ImportRepositoryName = "import-repository"
+
+// ImportRepository is the part of the import-repository api used by the controller
+type ImportRepository interface {
+	ListImportTypes(ctx context.Context, token jwt.Token, options importrepo.ImportTypeListOptions) (result []importrepomodel.ImportType, total int64, err error, code int)
+	ReadImportType(ctx context.Context, token jwt.Token, id string) (result importrepomodel.ImportType, err error, code int)
+}
+
+// NewImportRepository wraps an import-repository client and records a client span for every call.
+// calls return as soon as ctx is done or timeout (if > 0) elapsed, but keep running in the background (see AbandonedCalls).
+// like for the device-repository, forwarding the trace context is out of scope (see NewDeviceRepository).
+func NewImportRepository(repo importrepo.Interface, timeout time.Duration, abandoned *AbandonedCalls) ImportRepository {
+	return &ImportRepositoryClient{repo: repo, timeout: timeout, abandoned: abandoned}
+}
+
+type ImportRepositoryClient struct {
+	repo      importrepo.Interface
+	timeout   time.Duration
+	abandoned *AbandonedCalls
+}
+
+func (this *ImportRepositoryClient) ListImportTypes(ctx context.Context, token jwt.Token, options importrepo.ImportTypeListOptions) (result []importrepomodel.ImportType, total int64, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, ImportRepositoryName, "ListImportTypes", attribute.Int("criteria.count", len(options.Criteria)))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitListCall(ctx, ImportRepositoryName, this.timeout, this.abandoned, func() ([]importrepomodel.ImportType, int64, error, int) {
+		return this.repo.ListImportTypes(token, options)
+	})
+}
+
+func (this *ImportRepositoryClient) ReadImportType(ctx context.Context, token jwt.Token, id string) (result importrepomodel.ImportType, err error, code int) {
+	ctx, span := tracing.StartUpstream(ctx, ImportRepositoryName, "ReadImportType", attribute.String("import_type.id", id))
+	defer func() { tracing.Finish(span, err) }()
+	return awaitCall(ctx, ImportRepositoryName, this.timeout, this.abandoned, func() (importrepomodel.ImportType, error, int) {
+		return this.repo.ReadImportType(id, token)
+	})
+}
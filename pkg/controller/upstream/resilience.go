@@ -0,0 +1,308 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// DefaultStaleFallbackTtl is used if StaleFallbackTtl is not positive; stale results are stored per token and have to expire
+const DefaultStaleFallbackTtl = time.Hour
+
+type ResiliencePolicy struct {
+	MaxRetries              int           //additional attempts after a failed call; only used for reads
+	InitialBackoff          time.Duration //upper limit of the first random backoff; doubled for every further retry
+	MaxBackoff              time.Duration
+	BreakerFailureThreshold int           //consecutive failures that open the breaker; <= 0 disables the breaker
+	BreakerOpenDuration     time.Duration //time until a single trial call is allowed
+	StaleFallback           bool          //return the last successful result of the same call while the breaker is open
+	StaleFallbackTtl        time.Duration //<= 0 uses DefaultStaleFallbackTtl
+}
+
+// Resilience retries failed reads of one upstream service and protects it with a circuit breaker.
+// a nil *Resilience calls the upstream directly.
+type Resilience struct {
+	service string
+	policy  ResiliencePolicy
+	breaker *CircuitBreaker
+	stale   *cache.Cache
+}
+
+func NewResilience(service string, policy ResiliencePolicy) *Resilience {
+	result := &Resilience{
+		service: service,
+		policy:  policy,
+		breaker: NewCircuitBreaker(service, policy.BreakerFailureThreshold, policy.BreakerOpenDuration),
+	}
+	if policy.StaleFallback {
+		if policy.StaleFallbackTtl <= 0 {
+			policy.StaleFallbackTtl = DefaultStaleFallbackTtl
+			result.policy = policy
+		}
+		result.stale = cache.New(policy.StaleFallbackTtl, policy.StaleFallbackTtl)
+	}
+	return result
+}
+
+// Call executes call with retries and circuit breaking.
+// timed out repository calls are not retried, because they can not be canceled.
+// fallbackKeyArgs identify the call for the stale fallback and must contain everything that influences the result (including the token).
+func Call[T any](ctx context.Context, r *Resilience, operation string, fallbackKeyArgs []interface{}, call func(ctx context.Context) (T, error, int)) (result T, err error, code int) {
+	if r == nil {
+		return call(ctx)
+	}
+	key := ""
+	if r.stale != nil {
+		key, err = fallbackKey(operation, fallbackKeyArgs)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		if !r.breaker.Allow() {
+			if cached, ok := getStale[T](r, key); ok {
+				slog.Debug("circuit breaker open, use stale result", "service", r.service, "operation", operation)
+				return cached, nil, http.StatusOK
+			}
+			return result, fmt.Errorf("%v %v: %w", r.service, operation, ErrCircuitOpen), http.StatusServiceUnavailable
+		}
+		result, err, code = call(ctx)
+		if err == nil {
+			r.breaker.Success()
+			r.setStale(key, result)
+			return result, err, code
+		}
+		if ctx.Err() != nil {
+			//the caller gave up, which says nothing about the health of the upstream
+			r.breaker.Release()
+			return result, err, code
+		}
+		if !isRetryable(code) {
+			//the upstream is able to answer, e.g. with 404
+			r.breaker.Success()
+			return result, err, code
+		}
+		r.breaker.Failure()
+		if attempt >= r.policy.MaxRetries || stillRunning(err) {
+			if r.breaker.IsOpen() {
+				if cached, ok := getStale[T](r, key); ok {
+					slog.Debug("circuit breaker opened, use stale result", "service", r.service, "operation", operation)
+					return cached, nil, http.StatusOK
+				}
+			}
+			return result, err, code
+		}
+		slog.Debug("retry upstream call", "service", r.service, "operation", operation, "attempt", attempt+1, "error", err, "code", code)
+		select {
+		case <-ctx.Done():
+			return result, err, code
+		case <-time.After(r.backoff(attempt)):
+		}
+	}
+}
+
+// CallWithTotal is Call for list operations that additionally return the total count
+func CallWithTotal[T any](ctx context.Context, r *Resilience, operation string, fallbackKeyArgs []interface{}, call func(ctx context.Context) (T, int64, error, int)) (result T, total int64, err error, code int) {
+	type withTotal struct {
+		Result T
+		Total  int64
+	}
+	temp, err, code := Call(ctx, r, operation, fallbackKeyArgs, func(ctx context.Context) (withTotal, error, int) {
+		result, total, err, code := call(ctx)
+		return withTotal{Result: result, Total: total}, err, code
+	})
+	return temp.Result, temp.Total, err, code
+}
+
+// isRetryable is true for network errors, timeouts and server errors that may be transient
+func isRetryable(code int) bool {
+	switch {
+	case code == 0:
+		return true
+	case code == http.StatusTooManyRequests:
+		return true
+	case code == http.StatusNotImplemented:
+		return false
+	case code >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+// stillRunning is true if the failed call may still be running (see AbandonedCalls);
+// a retry would send a second identical request to an upstream that is already too slow
+func stillRunning(err error) bool {
+	return errors.Is(err, ErrCallAbandoned) || errors.Is(err, ErrTooManyAbandonedCalls)
+}
+
+// backoff uses full jitter: a random duration between 0 and min(MaxBackoff, InitialBackoff*2^attempt)
+func (this *Resilience) backoff(attempt int) time.Duration {
+	limit := this.policy.InitialBackoff << attempt
+	if this.policy.MaxBackoff > 0 && (limit > this.policy.MaxBackoff || limit <= 0) {
+		limit = this.policy.MaxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
+// stale results are stored json encoded, so that callers are not able to modify them
+func getStale[T any](r *Resilience, key string) (result T, ok bool) {
+	if r.stale == nil {
+		return result, false
+	}
+	temp, ok := r.stale.Get(key)
+	if !ok {
+		return result, false
+	}
+	err := json.Unmarshal(temp.([]byte), &result)
+	if err != nil {
+		slog.Warn("unable to decode stale result", "service", r.service, "error", err)
+		return result, false
+	}
+	return result, true
+}
+
+func (this *Resilience) setStale(key string, value interface{}) {
+	if this.stale == nil {
+		return
+	}
+	temp, err := json.Marshal(value)
+	if err != nil {
+		slog.Warn("unable to encode stale result", "service", this.service, "error", err)
+		return
+	}
+	this.stale.SetDefault(key, temp)
+}
+
+func fallbackKey(operation string, args []interface{}) (string, error) {
+	temp, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(append([]byte(operation+":"), temp...))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// CircuitBreaker opens after failureThreshold consecutive failures.
+// after openDuration one trial call is allowed; its success closes the breaker, its failure opens it again.
+type CircuitBreaker struct {
+	service          string
+	failureThreshold int
+	openDuration     time.Duration
+	mux              sync.Mutex
+	state            breakerState
+	failures         int
+	openedAt         time.Time
+}
+
+func NewCircuitBreaker(service string, failureThreshold int, openDuration time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{service: service, failureThreshold: failureThreshold, openDuration: openDuration}
+}
+
+// Allow reports if a call may be executed; every allowed call must be followed by Success, Failure or Release
+func (this *CircuitBreaker) Allow() bool {
+	if this.failureThreshold <= 0 {
+		return true
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	switch this.state {
+	case breakerOpen:
+		if time.Since(this.openedAt) < this.openDuration {
+			return false
+		}
+		this.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false //trial call is running
+	default:
+		return true
+	}
+}
+
+func (this *CircuitBreaker) Success() {
+	if this.failureThreshold <= 0 {
+		return
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.state != breakerClosed {
+		slog.Info("circuit breaker closed", "service", this.service)
+	}
+	this.state = breakerClosed
+	this.failures = 0
+}
+
+func (this *CircuitBreaker) Failure() {
+	if this.failureThreshold <= 0 {
+		return
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.failures++
+	if this.state == breakerHalfOpen || (this.state == breakerClosed && this.failures >= this.failureThreshold) {
+		slog.Warn("circuit breaker opened", "service", this.service, "failures", this.failures)
+		this.state = breakerOpen
+		this.openedAt = time.Now()
+	}
+}
+
+func (this *CircuitBreaker) IsOpen() bool {
+	if this.failureThreshold <= 0 {
+		return false
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	return this.state == breakerOpen
+}
+
+// Release ends a call without judging the health of the upstream, e.g. because the caller canceled the request
+func (this *CircuitBreaker) Release() {
+	if this.failureThreshold <= 0 {
+		return
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.state == breakerHalfOpen {
+		this.state = breakerOpen
+	}
+}
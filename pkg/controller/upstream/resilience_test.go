@@ -0,0 +1,132 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestResilienceRetry(t *testing.T) {
+	r := NewResilience("test", ResiliencePolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+	calls := 0
+	result, err, code := Call(context.Background(), r, "op", nil, func(ctx context.Context) (string, error, int) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("bad gateway"), http.StatusBadGateway
+		}
+		return "foo", nil, http.StatusOK
+	})
+	if err != nil || code != http.StatusOK || result != "foo" || calls != 3 {
+		t.Error(result, err, code, calls)
+	}
+
+	calls = 0
+	_, err, code = Call(context.Background(), r, "op", nil, func(ctx context.Context) (string, error, int) {
+		calls++
+		return "", errors.New("not found"), http.StatusNotFound
+	})
+	if err == nil || code != http.StatusNotFound || calls != 1 {
+		t.Error("client errors should not be retried", err, code, calls)
+	}
+}
+
+func TestResilienceNoRetryOfAbandonedCalls(t *testing.T) {
+	r := NewResilience("test", ResiliencePolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+	abandoned := NewAbandonedCalls(0)
+	release := make(chan struct{})
+	defer close(release)
+	calls := 0
+	_, err, code := Call(context.Background(), r, "op", nil, func(ctx context.Context) (string, error, int) {
+		calls++
+		return awaitCall(ctx, "test", 10*time.Millisecond, abandoned, func() (string, error, int) {
+			<-release
+			return "foo", nil, http.StatusOK
+		})
+	})
+	if !errors.Is(err, ErrCallAbandoned) || code != http.StatusGatewayTimeout || calls != 1 {
+		t.Error("timed out calls should not be retried", err, code, calls)
+	}
+	if abandoned.Count() != 1 {
+		t.Error(abandoned.Count())
+	}
+}
+
+func TestResilienceCircuitBreaker(t *testing.T) {
+	r := NewResilience("test", ResiliencePolicy{
+		MaxRetries:              0,
+		BreakerFailureThreshold: 2,
+		BreakerOpenDuration:     50 * time.Millisecond,
+		StaleFallback:           true,
+		StaleFallbackTtl:        time.Minute,
+	})
+	available := true
+	calls := 0
+	call := func(ctx context.Context) ([]string, error, int) {
+		calls++
+		if !available {
+			return nil, errors.New("unavailable"), http.StatusServiceUnavailable
+		}
+		return []string{"foo"}, nil, http.StatusOK
+	}
+
+	result, err, _ := Call(context.Background(), r, "op", []interface{}{"a"}, call)
+	if err != nil || len(result) != 1 {
+		t.Fatal(result, err)
+	}
+	result[0] = "modified by caller"
+
+	available = false
+	for i := 0; i < 2; i++ {
+		_, err, _ = Call(context.Background(), r, "op", []interface{}{"b"}, call)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if !r.breaker.IsOpen() {
+		t.Fatal("expected open breaker")
+	}
+
+	calls = 0
+	_, err, code := Call(context.Background(), r, "op", []interface{}{"b"}, call)
+	if !errors.Is(err, ErrCircuitOpen) || code != http.StatusServiceUnavailable || calls != 0 {
+		t.Error(err, code, calls)
+	}
+	result, err, code = Call(context.Background(), r, "op", []interface{}{"a"}, call)
+	if err != nil || code != http.StatusOK || len(result) != 1 || result[0] != "foo" || calls != 0 {
+		t.Error("expected unmodified stale result", result, err, code, calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	available = true
+	_, err, _ = Call(context.Background(), r, "op", []interface{}{"b"}, call)
+	if err != nil || calls != 1 || r.breaker.IsOpen() {
+		t.Error("trial call should close the breaker", err, calls)
+	}
+}
+
+func TestResilienceStaleFallbackExpires(t *testing.T) {
+	r := NewResilience("test", ResiliencePolicy{StaleFallback: true})
+	r.setStale("key", "foo")
+	_, expiration, ok := r.stale.GetWithExpiration("key")
+	if !ok || expiration.IsZero() || time.Until(expiration) > DefaultStaleFallbackTtl {
+		t.Error("stale results without ttl should expire after the default ttl", ok, expiration)
+	}
+}
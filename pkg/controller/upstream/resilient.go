@@ -0,0 +1,155 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upstream
+
+import (
+	"context"
+
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	importrepo "github.com/SENERGY-Platform/import-repository/lib/client"
+	importrepomodel "github.com/SENERGY-Platform/import-repository/lib/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// NewResilientDeviceRepository retries failed calls to repo; all used device-repository calls are reads
+func NewResilientDeviceRepository(repo DeviceRepository, resilience *Resilience) DeviceRepository {
+	return &ResilientDeviceRepository{repo: repo, resilience: resilience}
+}
+
+type ResilientDeviceRepository struct {
+	repo       DeviceRepository
+	resilience *Resilience
+}
+
+func (this *ResilientDeviceRepository) ReadDevice(ctx context.Context, id string, token string, action client.AuthAction) (result models.Device, err error, code int) {
+	return Call(ctx, this.resilience, "ReadDevice", []interface{}{id, token, action}, func(ctx context.Context) (models.Device, error, int) {
+		return this.repo.ReadDevice(ctx, id, token, action)
+	})
+}
+
+func (this *ResilientDeviceRepository) ListDevices(ctx context.Context, token string, options client.DeviceListOptions) (result []models.Device, err error, code int) {
+	return Call(ctx, this.resilience, "ListDevices", []interface{}{token, options}, func(ctx context.Context) ([]models.Device, error, int) {
+		return this.repo.ListDevices(ctx, token, options)
+	})
+}
+
+func (this *ResilientDeviceRepository) ListExtendedDevices(ctx context.Context, token string, options client.ExtendedDeviceListOptions) (result []models.ExtendedDevice, total int64, err error, code int) {
+	return CallWithTotal(ctx, this.resilience, "ListExtendedDevices", []interface{}{token, options}, func(ctx context.Context) ([]models.ExtendedDevice, int64, error, int) {
+		return this.repo.ListExtendedDevices(ctx, token, options)
+	})
+}
+
+func (this *ResilientDeviceRepository) ReadDeviceType(ctx context.Context, id string, token string) (result models.DeviceType, err error, code int) {
+	return Call(ctx, this.resilience, "ReadDeviceType", []interface{}{id, token}, func(ctx context.Context) (models.DeviceType, error, int) {
+		return this.repo.ReadDeviceType(ctx, id, token)
+	})
+}
+
+func (this *ResilientDeviceRepository) ListDeviceTypesV3(ctx context.Context, token string, options client.DeviceTypeListOptions) (result []models.DeviceType, total int64, err error, code int) {
+	return CallWithTotal(ctx, this.resilience, "ListDeviceTypesV3", []interface{}{token, options}, func(ctx context.Context) ([]models.DeviceType, int64, error, int) {
+		return this.repo.ListDeviceTypesV3(ctx, token, options)
+	})
+}
+
+func (this *ResilientDeviceRepository) GetDeviceTypeSelectables(ctx context.Context, query []client.FilterCriteria, pathPrefix string, interactionsFilter []models.Interaction, includeModified bool) (result []devicemodel.DeviceTypeSelectable, err error, code int) {
+	return Call(ctx, this.resilience, "GetDeviceTypeSelectables", []interface{}{query, pathPrefix, interactionsFilter, includeModified}, func(ctx context.Context) ([]devicemodel.DeviceTypeSelectable, error, int) {
+		return this.repo.GetDeviceTypeSelectables(ctx, query, pathPrefix, interactionsFilter, includeModified)
+	})
+}
+
+func (this *ResilientDeviceRepository) GetDeviceTypeSelectablesV2(ctx context.Context, query []client.FilterCriteria, pathPrefix string, includeModified bool, servicesMustMatchAllCriteria bool) (result []devicemodel.DeviceTypeSelectable, err error, code int) {
+	return Call(ctx, this.resilience, "GetDeviceTypeSelectablesV2", []interface{}{query, pathPrefix, includeModified, servicesMustMatchAllCriteria}, func(ctx context.Context) ([]devicemodel.DeviceTypeSelectable, error, int) {
+		return this.repo.GetDeviceTypeSelectablesV2(ctx, query, pathPrefix, includeModified, servicesMustMatchAllCriteria)
+	})
+}
+
+func (this *ResilientDeviceRepository) ListDeviceGroups(ctx context.Context, token string, options client.DeviceGroupListOptions) (result []models.DeviceGroup, total int64, err error, code int) {
+	return CallWithTotal(ctx, this.resilience, "ListDeviceGroups", []interface{}{token, options}, func(ctx context.Context) ([]models.DeviceGroup, int64, error, int) {
+		return this.repo.ListDeviceGroups(ctx, token, options)
+	})
+}
+
+func (this *ResilientDeviceRepository) GetFunctionsByType(ctx context.Context, rdfType string) (result []models.Function, err error, code int) {
+	return Call(ctx, this.resilience, "GetFunctionsByType", []interface{}{rdfType}, func(ctx context.Context) ([]models.Function, error, int) {
+		return this.repo.GetFunctionsByType(ctx, rdfType)
+	})
+}
+
+func (this *ResilientDeviceRepository) GetAspectNode(ctx context.Context, id string) (result models.AspectNode, err error, code int) {
+	return Call(ctx, this.resilience, "GetAspectNode", []interface{}{id}, func(ctx context.Context) (models.AspectNode, error, int) {
+		return this.repo.GetAspectNode(ctx, id)
+	})
+}
+
+func (this *ResilientDeviceRepository) GetConceptWithoutCharacteristics(ctx context.Context, id string) (result models.Concept, err error, code int) {
+	return Call(ctx, this.resilience, "GetConceptWithoutCharacteristics", []interface{}{id}, func(ctx context.Context) (models.Concept, error, int) {
+		return this.repo.GetConceptWithoutCharacteristics(ctx, id)
+	})
+}
+
+func (this *ResilientDeviceRepository) GetCharacteristic(ctx context.Context, id string) (result models.Characteristic, err error, code int) {
+	return Call(ctx, this.resilience, "GetCharacteristic", []interface{}{id}, func(ctx context.Context) (models.Characteristic, error, int) {
+		return this.repo.GetCharacteristic(ctx, id)
+	})
+}
+
+func (this *ResilientDeviceRepository) ListProtocols(ctx context.Context, token string, limit int64, offset int64, sort string) (result []models.Protocol, err error, code int) {
+	return Call(ctx, this.resilience, "ListProtocols", []interface{}{token, limit, offset, sort}, func(ctx context.Context) ([]models.Protocol, error, int) {
+		return this.repo.ListProtocols(ctx, token, limit, offset, sort)
+	})
+}
+
+// NewResilientImportRepository retries failed calls to repo
+func NewResilientImportRepository(repo ImportRepository, resilience *Resilience) ImportRepository {
+	return &ResilientImportRepository{repo: repo, resilience: resilience}
+}
+
+type ResilientImportRepository struct {
+	repo       ImportRepository
+	resilience *Resilience
+}
+
+func (this *ResilientImportRepository) ListImportTypes(ctx context.Context, token jwt.Token, options importrepo.ImportTypeListOptions) (result []importrepomodel.ImportType, total int64, err error, code int) {
+	return CallWithTotal(ctx, this.resilience, "ListImportTypes", []interface{}{token.Token, options}, func(ctx context.Context) ([]importrepomodel.ImportType, int64, error, int) {
+		return this.repo.ListImportTypes(ctx, token, options)
+	})
+}
+
+func (this *ResilientImportRepository) ReadImportType(ctx context.Context, token jwt.Token, id string) (result importrepomodel.ImportType, err error, code int) {
+	return Call(ctx, this.resilience, "ReadImportType", []interface{}{token.Token, id}, func(ctx context.Context) (importrepomodel.ImportType, error, int) {
+		return this.repo.ReadImportType(ctx, token, id)
+	})
+}
+
+// NewResilientImportDeploy retries failed calls to deploy
+func NewResilientImportDeploy(deploy ImportDeploy, resilience *Resilience) ImportDeploy {
+	return &ResilientImportDeploy{deploy: deploy, resilience: resilience}
+}
+
+type ResilientImportDeploy struct {
+	deploy     ImportDeploy
+	resilience *Resilience
+}
+
+func (this *ResilientImportDeploy) ListInstances(ctx context.Context, token string, importTypeIds []string) (result []model.Import, err error, code int) {
+	return Call(ctx, this.resilience, "ListInstances", []interface{}{token, importTypeIds}, func(ctx context.Context) ([]model.Import, error, int) {
+		return this.deploy.ListInstances(ctx, token, importTypeIds)
+	})
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+)
+
+// DefaultRole selects the limits of users without a configured role
+const DefaultRole = "default"
+
+// StatusCode is used for requests that exceed their budget
+const StatusCode = http.StatusRequestEntityTooLarge
+
+var ErrTooManyBulkElements = errors.New("too many bulk elements")
+var ErrUpstreamBudgetExceeded = errors.New("request exceeds its upstream call budget")
+
+// Limits of a single user; 0 means unlimited
+type Limits struct {
+	RequestsPerMinute float64
+	Burst             int64
+	MaxBulkElements   int64
+	MaxUpstreamCalls  int64
+}
+
+// Policy maps the roles of a user to Limits
+type Policy struct {
+	mux               sync.RWMutex
+	requestsPerMinute map[string]float64
+	burst             map[string]int64
+	maxBulkElements   map[string]int64
+	maxUpstreamCalls  map[string]int64
+}
+
+// New returns a policy without limits if none is configured; Update may add them later
+func New(config configuration.Config) (*Policy, error) {
+	result := &Policy{}
+	err := result.Update(config)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Update replaces the limits; used on config reload
+func (this *Policy) Update(config configuration.Config) error {
+	for role, value := range config.RateLimitPerMinute {
+		if value < 0 {
+			return fmt.Errorf("invalid rate_limit_per_minute for role %q: %v", role, value)
+		}
+	}
+	for name, values := range map[string]map[string]int64{"rate_limit_burst": config.RateLimitBurst, "max_bulk_elements": config.MaxBulkElements, "max_upstream_calls": config.MaxUpstreamCalls} {
+		for role, value := range values {
+			if value < 0 {
+				return fmt.Errorf("invalid %v for role %q: %v", name, role, value)
+			}
+		}
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.requestsPerMinute = maps.Clone(config.RateLimitPerMinute)
+	this.burst = maps.Clone(config.RateLimitBurst)
+	this.maxBulkElements = maps.Clone(config.MaxBulkElements)
+	this.maxUpstreamCalls = maps.Clone(config.MaxUpstreamCalls)
+	return nil
+}
+
+// Enabled is false if no limit is configured
+func (this *Policy) Enabled() bool {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	return len(this.requestsPerMinute) > 0 || len(this.maxBulkElements) > 0 || len(this.maxUpstreamCalls) > 0
+}
+
+// ForRoles resolves every limit independently: the most generous value of the configured roles wins,
+// users without a configured role get the DefaultRole value
+func (this *Policy) ForRoles(roles []string) Limits {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	return Limits{
+		RequestsPerMinute: resolve(this.requestsPerMinute, roles),
+		Burst:             resolve(this.burst, roles),
+		MaxBulkElements:   resolve(this.maxBulkElements, roles),
+		MaxUpstreamCalls:  resolve(this.maxUpstreamCalls, roles),
+	}
+}
+
+func resolve[T int64 | float64](values map[string]T, roles []string) T {
+	var result T
+	found := false
+	for _, role := range roles {
+		value, ok := values[role]
+		if !ok {
+			continue
+		}
+		switch {
+		case !found:
+			result, found = value, true
+		case result == 0 || value == 0:
+			result = 0
+		case value > result:
+			result = value
+		}
+	}
+	if !found {
+		return values[DefaultRole]
+	}
+	return result
+}
+
+// Budget counts the upstream calls of a single request
+type Budget struct {
+	limits        Limits
+	upstreamCalls atomic.Int64
+	exceeded      atomic.Bool
+}
+
+func NewBudget(limits Limits) *Budget {
+	return &Budget{limits: limits}
+}
+
+type budgetKey struct{}
+
+func WithBudget(ctx context.Context, budget *Budget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+// FromContext returns nil if the request has no budget; all Budget methods accept nil as unlimited
+func FromContext(ctx context.Context) *Budget {
+	budget, _ := ctx.Value(budgetKey{}).(*Budget)
+	return budget
+}
+
+func (this *Budget) CheckBulkSize(size int) error {
+	if this == nil || this.limits.MaxBulkElements == 0 || int64(size) <= this.limits.MaxBulkElements {
+		return nil
+	}
+	this.exceeded.Store(true)
+	return fmt.Errorf("%w: %v elements, the maximum is %v", ErrTooManyBulkElements, size, this.limits.MaxBulkElements)
+}
+
+// Spend registers an upstream call and returns an error if the budget is used up
+func (this *Budget) Spend() error {
+	if this == nil || this.limits.MaxUpstreamCalls == 0 {
+		return nil
+	}
+	if this.upstreamCalls.Add(1) > this.limits.MaxUpstreamCalls {
+		this.exceeded.Store(true)
+		return fmt.Errorf("%w of %v calls; narrow the criteria or split the request", ErrUpstreamBudgetExceeded, this.limits.MaxUpstreamCalls)
+	}
+	return nil
+}
+
+// Exceeded is true if CheckBulkSize or Spend failed
+func (this *Budget) Exceeded() bool {
+	return this != nil && this.exceeded.Load()
+}
+
+// Spend calls Budget.Spend on the budget of ctx
+func Spend(ctx context.Context) error {
+	return FromContext(ctx).Spend()
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package limits
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+)
+
+func TestPolicy(t *testing.T) {
+	policy, err := New(&configuration.ConfigStruct{
+		RateLimitPerMinute: map[string]float64{DefaultRole: 60, "user": 120, "admin": 0},
+		MaxBulkElements:    map[string]int64{DefaultRole: 10, "user": 20, "developer": 50},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		roles    []string
+		expected Limits
+	}{
+		{roles: nil, expected: Limits{RequestsPerMinute: 60, MaxBulkElements: 10}},
+		{roles: []string{"unknown"}, expected: Limits{RequestsPerMinute: 60, MaxBulkElements: 10}},
+		{roles: []string{"user"}, expected: Limits{RequestsPerMinute: 120, MaxBulkElements: 20}},
+		{roles: []string{"user", "developer"}, expected: Limits{RequestsPerMinute: 120, MaxBulkElements: 50}},
+		{roles: []string{"user", "admin"}, expected: Limits{RequestsPerMinute: 0, MaxBulkElements: 20}},
+	}
+	for _, test := range tests {
+		if actual := policy.ForRoles(test.roles); actual != test.expected {
+			t.Errorf("%v: %#v != %#v", test.roles, actual, test.expected)
+		}
+	}
+
+	policy, err = New(&configuration.ConfigStruct{})
+	if err != nil || policy.Enabled() {
+		t.Error(policy, err)
+	}
+	err = policy.Update(&configuration.ConfigStruct{MaxBulkElements: map[string]int64{DefaultRole: 5}})
+	if err != nil || !policy.Enabled() || policy.ForRoles(nil).MaxBulkElements != 5 {
+		t.Error(policy.ForRoles(nil), err)
+	}
+	_, err = New(&configuration.ConfigStruct{MaxUpstreamCalls: map[string]int64{DefaultRole: -1}})
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter()
+	limits := Limits{RequestsPerMinute: 60, Burst: 2}
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow("user1", limits); !ok {
+			t.Fatal(i)
+		}
+	}
+	ok, retryAfter := limiter.Allow("user1", limits)
+	if ok || retryAfter <= 0 {
+		t.Fatal(ok, retryAfter)
+	}
+	if ok, _ = limiter.Allow("user2", limits); !ok {
+		t.Error("users must not share a bucket")
+	}
+	if ok, _ = limiter.Allow("user1", Limits{}); !ok {
+		t.Error("0 must be unlimited")
+	}
+}
+
+func TestBudget(t *testing.T) {
+	err := Spend(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+	budget := NewBudget(Limits{MaxBulkElements: 2, MaxUpstreamCalls: 2})
+	ctx := WithBudget(context.Background(), budget)
+	if err = FromContext(ctx).CheckBulkSize(2); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = Spend(ctx); err != nil {
+			t.Fatal(i, err)
+		}
+	}
+	if budget.Exceeded() {
+		t.Error("unexpected exceeded budget")
+	}
+	if err = Spend(ctx); !errors.Is(err, ErrUpstreamBudgetExceeded) {
+		t.Error(err)
+	}
+	if err = budget.CheckBulkSize(3); !errors.Is(err, ErrTooManyBulkElements) {
+		t.Error(err)
+	}
+	if !budget.Exceeded() {
+		t.Error("expected exceeded budget")
+	}
+}
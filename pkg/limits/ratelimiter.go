@@ -0,0 +1,91 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package limits
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// limiters of users that did not send requests for this duration are removed; their buckets are full again anyway
+const idleTimeout = 10 * time.Minute
+
+// RateLimiter is a token bucket per user
+type RateLimiter struct {
+	mux         sync.Mutex
+	users       map[string]*userLimiter
+	lastCleanup time.Time
+}
+
+type userLimiter struct {
+	limiter  *rate.Limiter
+	limits   Limits
+	lastSeen time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{users: map[string]*userLimiter{}, lastCleanup: time.Now()}
+}
+
+// Allow consumes a token of the users bucket; if none is available, retryAfter is the duration until the next one
+func (this *RateLimiter) Allow(user string, limits Limits) (ok bool, retryAfter time.Duration) {
+	if limits.RequestsPerMinute <= 0 {
+		return true, 0
+	}
+	now := time.Now()
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.cleanup(now)
+	entry, found := this.users[user]
+	if !found || entry.limits.RequestsPerMinute != limits.RequestsPerMinute || entry.limits.Burst != limits.Burst {
+		entry = &userLimiter{limiter: rate.NewLimiter(rate.Limit(limits.RequestsPerMinute/60), burst(limits)), limits: limits}
+		this.users[user] = entry
+	}
+	entry.lastSeen = now
+	reservation := entry.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Minute
+	}
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// burst defaults to one second worth of requests
+func burst(limits Limits) int {
+	if limits.Burst > 0 {
+		return int(limits.Burst)
+	}
+	return max(1, int(limits.RequestsPerMinute/60))
+}
+
+func (this *RateLimiter) cleanup(now time.Time) {
+	if now.Sub(this.lastCleanup) < time.Minute {
+		return
+	}
+	this.lastCleanup = now
+	for user, entry := range this.users {
+		if now.Sub(entry.lastSeen) > idleTimeout {
+			delete(this.users, user)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package basecontentvariable
 
+import "github.com/SENERGY-Platform/models/go/models"
+
 type Descriptor interface {
 	GetName() string
 	GetCharacteristicId() string
@@ -7,4 +9,5 @@ type Descriptor interface {
 	GetFunctionId() string
 	GetAspectId() string
 	GetIsVoid() bool
+	GetType() models.Type
 }
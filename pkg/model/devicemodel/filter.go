@@ -16,13 +16,231 @@
 
 package devicemodel
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
 type FilterCriteria struct {
 	Interaction   string `json:"interaction,omitempty"`
 	FunctionId    string `json:"function_id"`
 	AspectId      string `json:"aspect_id"`
 	DeviceClassId string `json:"device_class_id"`
+
+	AspectMatch AspectMatch `json:"aspect_match,omitempty"` //how aspect_id matches the aspects of path options; empty is AspectMatchDescendants
+
+	TargetCharacteristicId string `json:"target_characteristic_id,omitempty"` //path options of this criterion get a model.CharacteristicMatch
+
+	ValueTypes []Type `json:"value_types,omitempty"` //path options must have one of these types; short names like Float or Structure may be used
+	Void       *bool  `json:"void,omitempty"`        //true: only void path options, false: no void path options
+
+	Configurables *ConfigurableCriteria `json:"configurables,omitempty"` //path options of this criterion are filtered by their configurables and get model.PathOption.ResolvedConfigurables
+
+	Optional bool    `json:"optional,omitempty"` //v2 only: selectables do not need to match this criterion but are ranked higher if they do
+	Weight   float64 `json:"weight,omitempty"`   //score of a matched optional criterion; default 1
+
+	Exclude bool `json:"exclude,omitempty"` //removes devices, groups and imports with a service, criterion or output matching interaction (exactly), function, aspect (see AspectMatch) and device class
+}
+
+// AspectMatch selects which aspects of the aspect hierarchy match the aspect_id of a criterion
+type AspectMatch string
+
+const (
+	AspectMatchExact       AspectMatch = "exact"       //only aspect_id
+	AspectMatchDescendants AspectMatch = "descendants" //aspect_id and its sub aspects
+	AspectMatchAncestors   AspectMatch = "ancestors"   //aspect_id and its parent aspects
+	AspectMatchBoth        AspectMatch = "both"        //aspect_id, its sub aspects and its parent aspects
+)
+
+func (this AspectMatch) Validate() error {
+	switch this {
+	case "", AspectMatchExact, AspectMatchDescendants, AspectMatchAncestors, AspectMatchBoth:
+		return nil
+	default:
+		return fmt.Errorf("unknown aspect_match %v (expected exact, descendants, ancestors or both)", this)
+	}
+}
+
+// OrDefault returns AspectMatchDescendants for an empty AspectMatch
+func (this AspectMatch) OrDefault() AspectMatch {
+	if this == "" {
+		return AspectMatchDescendants
+	}
+	return this
+}
+
+// Reverse swaps descendants and ancestors: criteria with aspect a match aspect b, if criteria with aspect b and the reversed AspectMatch match aspect a
+func (this AspectMatch) Reverse() AspectMatch {
+	switch this.OrDefault() {
+	case AspectMatchDescendants:
+		return AspectMatchAncestors
+	case AspectMatchAncestors:
+		return AspectMatchDescendants
+	default:
+		return this
+	}
+}
+
+// AspectIds returns the ids of all aspects matching aspect (the aspect node of the criterion)
+func (this AspectMatch) AspectIds(aspect AspectNode) (result []string) {
+	result = []string{aspect.Id}
+	mode := this.OrDefault()
+	if mode == AspectMatchDescendants || mode == AspectMatchBoth {
+		result = append(result, aspect.DescendentIds...)
+	}
+	if mode == AspectMatchAncestors || mode == AspectMatchBoth {
+		result = append(result, aspect.AncestorIds...)
+	}
+	return result
+}
+
+// Matches checks if the aspect of a path option (actual) matches the aspect id of a criterion (expected)
+func (this AspectMatch) Matches(expected string, actual AspectNode) bool {
+	if expected == "" || expected == actual.Id {
+		return true
+	}
+	switch this.OrDefault() {
+	case AspectMatchDescendants:
+		return slices.Contains(actual.AncestorIds, expected)
+	case AspectMatchAncestors:
+		return slices.Contains(actual.DescendentIds, expected)
+	case AspectMatchBoth:
+		return slices.Contains(actual.AncestorIds, expected) || slices.Contains(actual.DescendentIds, expected)
+	default:
+		return false
+	}
+}
+
+// UpstreamAspectId is the aspect id for device-repository queries, which match an aspect and its descendants.
+// ancestors are not descendants of aspect; the root aspect is used instead, and the result must be filtered with Matches.
+func (this AspectMatch) UpstreamAspectId(aspect AspectNode) string {
+	mode := this.OrDefault()
+	if (mode == AspectMatchAncestors || mode == AspectMatchBoth) && aspect.RootId != "" {
+		return aspect.RootId
+	}
+	return aspect.Id
+}
+
+// ConfigurableCriteria restricts the configurables of path options; an empty ConfigurableCriteria only resolves them
+type ConfigurableCriteria struct {
+	None    bool                 `json:"none,omitempty"`    //only path options without configurables
+	Require []ConfigurableFilter `json:"require,omitempty"` //every filter must match a configurable
+	Exclude []ConfigurableFilter `json:"exclude,omitempty"` //no configurable may match one of these filters
+}
+
+// ConfigurableFilter matches configurables by all set fields
+type ConfigurableFilter struct {
+	Path             string      `json:"path,omitempty"`
+	CharacteristicId string      `json:"characteristic_id,omitempty"`
+	Accepts          interface{} `json:"accepts,omitempty"` //the configurable type and the min/max values of its characteristic allow this value
+}
+
+func (this ConfigurableCriteria) Validate() error {
+	if this.None && len(this.Require) > 0 {
+		return errors.New("invalid configurables criteria: none and require are exclusive")
+	}
+	for _, filter := range append(slices.Clone(this.Require), this.Exclude...) {
+		if filter.Path == "" && filter.CharacteristicId == "" && filter.Accepts == nil {
+			return errors.New("invalid configurables criteria: empty filter")
+		}
+	}
+	return nil
 }
 
 func (this FilterCriteria) Short() string {
 	return this.FunctionId + "_" + this.AspectId + "_" + this.DeviceClassId
 }
+
+var shortTypeNames = map[string]Type{
+	"string":    String,
+	"text":      String,
+	"integer":   Integer,
+	"float":     Float,
+	"boolean":   Boolean,
+	"list":      List,
+	"structure": Structure,
+}
+
+// ParseType accepts short names (Float, Integer, String, Boolean, Structure, List) and schema.org urls
+func ParseType(name string) (Type, error) {
+	if t, ok := shortTypeNames[strings.ToLower(name)]; ok {
+		return t, nil
+	}
+	for _, t := range shortTypeNames {
+		if Type(name) == t {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown value type %v", name)
+}
+
+func (this FilterCriteria) Validate() error {
+	if err := this.AspectMatch.Validate(); err != nil {
+		return err
+	}
+	if this.Weight < 0 {
+		return fmt.Errorf("invalid weight %v: must not be negative", this.Weight)
+	}
+	if this.Exclude && this.Optional {
+		return errors.New("invalid criteria: exclude and optional are exclusive")
+	}
+	if this.Exclude && this.Interaction == "" && this.FunctionId == "" && this.AspectId == "" && this.DeviceClassId == "" {
+		return errors.New("invalid criteria: exclusion without interaction, function, aspect or device class")
+	}
+	for _, t := range this.ValueTypes {
+		if _, err := ParseType(string(t)); err != nil {
+			return err
+		}
+	}
+	if this.Configurables != nil {
+		return this.Configurables.Validate()
+	}
+	return nil
+}
+
+// GetWeight returns Weight or the default weight 1
+func (this FilterCriteria) GetWeight() float64 {
+	if this.Weight == 0 {
+		return 1
+	}
+	return this.Weight
+}
+
+// HasValueConstraints is true if value_types or void are set
+func (this FilterCriteria) HasValueConstraints() bool {
+	return len(this.ValueTypes) > 0 || this.Void != nil
+}
+
+// HasAspectConstraints is true if aspect_id is set and aspect_match is not the default (descendants), which the device-repository handles
+func (this FilterCriteria) HasAspectConstraints() bool {
+	return this.AspectId != "" && this.AspectMatch.OrDefault() != AspectMatchDescendants
+}
+
+// AcceptsValue checks value_types and void; unknown value types match nothing
+func (this FilterCriteria) AcceptsValue(valueType Type, isVoid bool) bool {
+	if this.Void != nil && *this.Void != isVoid {
+		return false
+	}
+	if len(this.ValueTypes) == 0 {
+		return true
+	}
+	for _, t := range this.ValueTypes {
+		if parsed, err := ParseType(string(t)); err == nil && parsed == valueType {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesPathOption checks function, aspect (see AspectMatch.Matches) and the value constraints
+func (this FilterCriteria) MatchesPathOption(functionId string, aspectNode AspectNode, valueType Type, isVoid bool) bool {
+	if this.FunctionId != "" && this.FunctionId != functionId {
+		return false
+	}
+	if !this.AspectMatch.Matches(this.AspectId, aspectNode) {
+		return false
+	}
+	return this.AcceptsValue(valueType, isVoid)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"reflect"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+)
+
+// GroupedSelectables is the compact form of a selectable list: devices reference the services and path options of their device-type in DeviceTypes.
+// devices whose services differ from the first device of their type keep them inline.
+type GroupedSelectables struct {
+	DeviceTypes map[string]DeviceTypeServices `json:"device_types"` //key is the device_type_id of the devices (may contain an id modifier)
+	Selectables []GroupedSelectable           `json:"selectables"`
+}
+
+// GroupedSelectable is a Selectable of GroupedSelectables; only devices with DeviceTypeRef use the services and path options of their device-type
+type GroupedSelectable struct {
+	Selectable
+	DeviceTypeRef bool `json:"device_type_ref,omitempty"`
+}
+
+type DeviceTypeServices struct {
+	Services           []devicemodel.Service   `json:"services,omitempty"`
+	ServicePathOptions map[string][]PathOption `json:"servicePathOptions,omitempty"`
+}
+
+// GroupSelectables moves the services and path options of devices to GroupedSelectables.DeviceTypes; the order of selectables is kept
+func GroupSelectables(selectables []Selectable) (result GroupedSelectables) {
+	result = GroupedSelectables{
+		DeviceTypes: map[string]DeviceTypeServices{},
+		Selectables: make([]GroupedSelectable, 0, len(selectables)),
+	}
+	for _, selectable := range selectables {
+		if selectable.Device == nil || (selectable.Services == nil && selectable.ServicePathOptions == nil) {
+			result.Selectables = append(result.Selectables, GroupedSelectable{Selectable: selectable})
+			continue
+		}
+		services := DeviceTypeServices{Services: selectable.Services, ServicePathOptions: selectable.ServicePathOptions}
+		existing, ok := result.DeviceTypes[selectable.Device.DeviceTypeId]
+		if !ok {
+			result.DeviceTypes[selectable.Device.DeviceTypeId] = services
+		}
+		grouped := GroupedSelectable{Selectable: selectable}
+		if !ok || reflect.DeepEqual(existing, services) {
+			grouped.Services = nil
+			grouped.ServicePathOptions = nil
+			grouped.DeviceTypeRef = true
+		}
+		result.Selectables = append(result.Selectables, grouped)
+	}
+	return result
+}
+
+// GroupedBulkResult is the compact form of BulkResult; every element is grouped separately
+type GroupedBulkResult []GroupedBulkResultElement
+
+type GroupedBulkResultElement struct {
+	Id string `json:"id"`
+	GroupedSelectables
+}
+
+// GroupBulkResult applies GroupSelectables to every element of bulk
+func GroupBulkResult(bulk BulkResult) (result GroupedBulkResult) {
+	result = make(GroupedBulkResult, 0, len(bulk))
+	for _, element := range bulk {
+		result = append(result, GroupedBulkResultElement{Id: element.Id, GroupedSelectables: GroupSelectables(element.Selectables)})
+	}
+	return result
+}
+
+// Expand returns the BulkResult with the selectables of every element expanded
+func (this GroupedBulkResult) Expand() (result BulkResult) {
+	result = make(BulkResult, 0, len(this))
+	for _, element := range this {
+		result = append(result, BulkResultElement{Id: element.Id, Selectables: element.GroupedSelectables.Expand()})
+	}
+	return result
+}
+
+// Expand returns the selectables with the services and path options of their device-type for devices with DeviceTypeRef; devices of the same type share them
+func (this GroupedSelectables) Expand() (result []Selectable) {
+	result = make([]Selectable, 0, len(this.Selectables))
+	for _, grouped := range this.Selectables {
+		selectable := grouped.Selectable
+		if grouped.DeviceTypeRef && selectable.Device != nil {
+			if services, ok := this.DeviceTypes[selectable.Device.DeviceTypeId]; ok {
+				selectable.Services = services.Services
+				selectable.ServicePathOptions = services.ServicePathOptions
+			}
+		}
+		result = append(result, selectable)
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ImportFilter restricts imports to instances whose properties match all set fields
+type ImportFilter struct {
+	Configs    []ImportConfigFilter `json:"configs,omitempty"`
+	Restart    *bool                `json:"restart,omitempty"`
+	KafkaTopic string               `json:"kafka_topic,omitempty"`
+}
+
+// ImportConfigFilter matches the value of the import config with Name by exactly one of: Equals, In or the range Min/Max (inclusive, one side may be open).
+// values are compared as json; ranges accept numbers and numeric strings.
+type ImportConfigFilter struct {
+	Name   string        `json:"name"`
+	Equals interface{}   `json:"equals,omitempty"`
+	In     []interface{} `json:"in,omitempty"`
+	Min    *float64      `json:"min,omitempty"`
+	Max    *float64      `json:"max,omitempty"`
+}
+
+func (this ImportFilter) Validate() error {
+	for _, config := range this.Configs {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this ImportConfigFilter) Validate() error {
+	if this.Name == "" {
+		return errors.New("invalid import config filter: missing name")
+	}
+	operators := 0
+	if this.Equals != nil {
+		operators++
+	}
+	if this.In != nil {
+		operators++
+	}
+	if this.Min != nil || this.Max != nil {
+		operators++
+	}
+	if operators != 1 {
+		return fmt.Errorf("invalid import config filter %v: expect exactly one of equals, in or min/max", this.Name)
+	}
+	if this.Min != nil && this.Max != nil && *this.Min > *this.Max {
+		return fmt.Errorf("invalid import config filter %v: min > max", this.Name)
+	}
+	return nil
+}
+
+// Match returns the configs of instance that matched a config filter; ok is false if instance does not match the filter
+func (this ImportFilter) Match(instance Import) (matched []ImportConfig, ok bool) {
+	if this.Restart != nil && (instance.Restart == nil || *instance.Restart != *this.Restart) {
+		return nil, false
+	}
+	if this.KafkaTopic != "" && instance.KafkaTopic != this.KafkaTopic {
+		return nil, false
+	}
+	for _, filter := range this.Configs {
+		config, found := findImportConfig(instance.Configs, filter.Name)
+		if !found || !filter.Matches(config.Value) {
+			return nil, false
+		}
+		matched = append(matched, config)
+	}
+	return matched, true
+}
+
+func (this ImportConfigFilter) Matches(value interface{}) bool {
+	switch {
+	case this.Equals != nil:
+		return jsonEqual(value, this.Equals)
+	case this.In != nil:
+		for _, element := range this.In {
+			if jsonEqual(value, element) {
+				return true
+			}
+		}
+		return false
+	default:
+		number, ok := toFloat(value)
+		if !ok {
+			return false
+		}
+		return (this.Min == nil || number >= *this.Min) && (this.Max == nil || number <= *this.Max)
+	}
+}
+
+func findImportConfig(configs []ImportConfig, name string) (ImportConfig, bool) {
+	for _, config := range configs {
+		if config.Name == name {
+			return config, true
+		}
+	}
+	return ImportConfig{}, false
+}
+
+// jsonEqual compares a and b after a json round trip, so that e.g. int(1) and float64(1) are equal
+func jsonEqual(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(NormalizeJson(a), NormalizeJson(b))
+}
+
+// NormalizeJson returns value as it would be decoded from json (e.g. numbers as float64, structs as map[string]interface{})
+func NormalizeJson(value interface{}) (result interface{}) {
+	temp, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	err = json.Unmarshal(temp, &result)
+	if err != nil {
+		return value
+	}
+	return result
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := NormalizeJson(value).(type) {
+	case float64:
+		return v, true
+	case string:
+		result, err := strconv.ParseFloat(v, 64)
+		return result, err == nil
+	default:
+		return 0, false
+	}
+}
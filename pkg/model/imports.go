@@ -102,3 +102,7 @@ func (this *ImportContentVariable) GetAspectId() string {
 func (this *ImportContentVariable) GetIsVoid() bool {
 	return false
 }
+
+func (this *ImportContentVariable) GetType() Type {
+	return this.Type
+}
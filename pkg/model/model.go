@@ -40,6 +40,29 @@ type Selectable struct {
 	Import             *Import                 `json:"import,omitempty"`
 	ImportType         *ImportType             `json:"importType,omitempty"`
 	ServicePathOptions map[string][]PathOption `json:"servicePathOptions,omitempty"`
+
+	MatchedImportConfigs []ImportConfig `json:"matchedImportConfigs,omitempty"` //configs of Import that matched the import_filter
+
+	Ranking *Ranking `json:"ranking,omitempty"` //set if the request has optional criteria or a preferred interaction
+}
+
+const PreferredInteractionScore = 0.5 //score of selectables with a service or path option of the preferred interaction
+const OwnedDeviceScore = 0.25         //score of devices owned by the requesting user
+
+// Ranking explains the score of a selectable; selectables are sorted by descending score
+type Ranking struct {
+	Score                float64          `json:"score"`
+	Criteria             []CriterionMatch `json:"criteria"`
+	PreferredInteraction bool             `json:"preferredInteraction"`
+	Owned                bool             `json:"owned"`
+}
+
+// CriterionMatch tells if the criterion with Index in the request criteria matched the selectable; only matched optional criteria add to the score
+type CriterionMatch struct {
+	Index     int                        `json:"index"`
+	Criterion devicemodel.FilterCriteria `json:"criterion"`
+	Matched   bool                       `json:"matched"`
+	Score     float64                    `json:"score"`
 }
 
 type DeviceGroup struct {
@@ -75,6 +98,10 @@ type BulkRequestElementV2 struct {
 	LocalDevices                []string             `json:"local_devices"`
 	LocalDeviceOwner            string               `json:"local_device_owner"`
 	FilterByDeviceAttributeKeys []string             `json:"filter_by_device_attribute_keys"`
+	ImportFilter                *ImportFilter        `json:"import_filter,omitempty"`
+
+	FilterIncompatibleCharacteristics bool                    `json:"filter_incompatible_characteristics,omitempty"`
+	PreferredInteraction              devicemodel.Interaction `json:"preferred_interaction,omitempty"`
 }
 
 type BulkRequestV2 []BulkRequestElementV2
@@ -107,6 +134,48 @@ type PathOption struct {
 	Type             Type                       `json:"type,omitempty"`
 	Configurables    []devicemodel.Configurable `json:"configurables,omitempty"`
 	Interaction      devicemodel.Interaction    `json:"interaction,omitempty"`
+
+	CharacteristicMatch   *CharacteristicMatch   `json:"characteristicMatch,omitempty"`   //set if the criterion of the option has a target_characteristic_id
+	ResolvedConfigurables []ResolvedConfigurable `json:"resolvedConfigurables,omitempty"` //set if the criterion of the option has configurables
+}
+
+// ResolvedConfigurable is a configurable with its default value (Value) and its characteristic
+type ResolvedConfigurable struct {
+	devicemodel.Configurable
+	Characteristic *devicemodel.Characteristic `json:"characteristic,omitempty"`
+}
+
+type CharacteristicUsability string
+
+const (
+	CharacteristicDirect       CharacteristicUsability = "direct"       //CharacteristicId is the target characteristic
+	CharacteristicConversion   CharacteristicUsability = "conversion"   //CharacteristicId and the target characteristic belong to the concept of the function
+	CharacteristicIncompatible CharacteristicUsability = "incompatible" //no conversion to the target characteristic is known
+)
+
+// CharacteristicMatch describes how the value of a path option relates to the target_characteristic_id of its criterion
+type CharacteristicMatch struct {
+	TargetCharacteristicId string                  `json:"targetCharacteristicId"`
+	ConceptId              string                  `json:"conceptId,omitempty"`
+	BaseCharacteristicId   string                  `json:"baseCharacteristicId,omitempty"`
+	DisplayUnit            string                  `json:"displayUnit,omitempty"` //display unit of CharacteristicId
+	Usability              CharacteristicUsability `json:"usability"`
+}
+
+// DeviceTypeSelection is a device-type that matches all criteria of a device-type query
+type DeviceTypeSelection struct {
+	DeviceTypeId       string                  `json:"deviceTypeId"` //may contain an id modifier
+	Name               string                  `json:"name"`
+	DeviceClassId      string                  `json:"deviceClassId"`
+	Services           []devicemodel.Service   `json:"services"`
+	ServicePathOptions map[string][]PathOption `json:"servicePathOptions"`
+	DeviceCount        int                     `json:"deviceCount"` //devices of this type the requesting user may execute
+}
+
+type QueryDeviceTypesOptions struct {
+	FilterCriteria                    FilterCriteriaAndSet
+	IncludeIdModified                 bool
+	FilterIncompatibleCharacteristics bool
 }
 
 type GetFilteredDevicesV2Options struct {
@@ -120,4 +189,8 @@ type GetFilteredDevicesV2Options struct {
 	LocalDeviceOwner            string
 	FilterByDeviceAttributeKeys []string
 	ImportPathTrimFirstElement  bool
+	ImportFilter                *ImportFilter
+
+	FilterIncompatibleCharacteristics bool                    //removes path options with CharacteristicIncompatible
+	PreferredInteraction              devicemodel.Interaction //event or request; ranks selectables with this interaction higher
 }
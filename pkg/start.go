@@ -21,12 +21,23 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/api"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/device-selection/pkg/tracing"
 	"sync"
 )
 
 // starts services and goroutines; returns a waiting group which is done as soon as all go routines are stopped
 func Start(ctx context.Context, config configuration.Config) (wg *sync.WaitGroup, err error) {
 	wg = &sync.WaitGroup{}
+	shutdownTracing, err := tracing.Init(ctx, config)
+	if err != nil {
+		return wg, err
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		config.GetLogger().Info("tracing shutdown", "result", shutdownTracing(context.Background()))
+	}()
 	d, err := controller.New(ctx, config)
 	if err != nil {
 		return wg, err
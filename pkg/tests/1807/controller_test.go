@@ -24,6 +24,7 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/legacy"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
 	"net/http"
@@ -62,7 +63,7 @@ func TestGetFilteredDeviceTypes(t *testing.T) {
 		return
 	}
 
-	_, err, _ = repo.GetFilteredDeviceTypes(helper.AdminJwt, []client.FilterCriteria{{
+	_, err, _ = repo.GetFilteredDeviceTypes(ctx, helper.AdminJwt, []client.FilterCriteria{{
 		FunctionId: "fid",
 	}})
 
@@ -71,7 +72,7 @@ func TestGetFilteredDeviceTypes(t *testing.T) {
 		return
 	}
 
-	dt, err, _ := repo.GetFilteredDeviceTypes(helper.AdminJwt, []client.FilterCriteria{{
+	dt, err, _ := repo.GetFilteredDeviceTypes(ctx, helper.AdminJwt, []client.FilterCriteria{{
 		FunctionId:    "fid",
 		DeviceClassId: "dc1",
 		AspectId:      "a1",
@@ -175,7 +176,7 @@ func TestGetFilteredDevices(t *testing.T) {
 		DeviceRepoUrl: repourl,
 	}
 
-	repo, err := controller.New(ctx, c)
+	repo, err := environment.NewController(ctx, c)
 	if err != nil {
 		t.Error(err)
 		return
@@ -183,7 +184,7 @@ func TestGetFilteredDevices(t *testing.T) {
 
 	time.Sleep(2 * time.Second)
 
-	d, err, _ := repo.GetFilteredDevices(helper.AdminJwt, DeviceDescriptions{{
+	d, err, _ := repo.GetFilteredDevices(ctx, helper.AdminJwt, DeviceDescriptions{{
 		CharacteristicId: "chid1",
 		Function:         devicemodel.Function{Id: devicemodel.MEASURING_FUNCTION_PREFIX + "_1"},
 		DeviceClass:      &devicemodel.DeviceClass{Id: "dc1"},
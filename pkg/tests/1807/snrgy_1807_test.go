@@ -22,7 +22,6 @@ import (
 	"errors"
 	"github.com/SENERGY-Platform/device-selection/pkg/api"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
-	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
@@ -48,7 +47,7 @@ func createTestEnv(ctx context.Context, wg *sync.WaitGroup, t *testing.T) (devic
 		return
 	}
 
-	ctrl, err := controller.New(ctx, config)
+	ctrl, err := environment.NewController(ctx, config)
 	if err != nil {
 		return
 	}
@@ -26,6 +26,10 @@ import (
 )
 
 func NewWithImport(ctx context.Context, wg *sync.WaitGroup) (kafkaBroker string, deviceManagerUrl string, deviceRepoUrl string, permv2Url string, importRepoUrl string, importDeployUrl string, err error) {
+	if IsHermetic() {
+		env := NewHermetic(ctx, wg)
+		return "", env.DeviceManager.Url(), env.DeviceRepoUrl, "", env.ImportRepoUrl, env.ImportDeploy.Url(), nil
+	}
 	kafkaBroker, deviceManagerUrl, deviceRepoUrl, permv2Url, err = docker.DeviceManagerWithDependenciesAndKafka(ctx, wg)
 	if err != nil {
 		log.Println("ERROR:", err)
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	importmodel "github.com/SENERGY-Platform/import-repository/lib/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures describe the content of a hermetic environment.
+// devices without owner belong to Owner; device-groups without criteria get the criteria of their devices.
+type Fixtures struct {
+	Owner           string                   `json:"owner"`
+	Aspects         []models.Aspect          `json:"aspects"`
+	Functions       []models.Function        `json:"functions"`
+	Concepts        []models.Concept         `json:"concepts"`
+	Characteristics []models.Characteristic  `json:"characteristics"`
+	Protocols       []models.Protocol        `json:"protocols"`
+	DeviceTypes     []models.DeviceType      `json:"device_types"`
+	Devices         []models.Device          `json:"devices"`
+	DeviceGroups    []models.DeviceGroup     `json:"device_groups"`
+	ImportTypes     []importmodel.ImportType `json:"import_types"`
+	Imports         []model.Import           `json:"imports"`
+}
+
+//go:embed testdata/fixtures.yaml
+var defaultFixtures []byte
+
+// DefaultFixtures returns the fixtures of testdata/fixtures.yaml; tests may extend them before StartHermetic
+func DefaultFixtures() (fixtures Fixtures, err error) {
+	return decodeFixtures("testdata/fixtures.yaml", defaultFixtures)
+}
+
+// LoadFixtures reads a json or yaml (.yaml, .yml) file; unknown fields are rejected
+func LoadFixtures(location string) (fixtures Fixtures, err error) {
+	content, err := os.ReadFile(location)
+	if err != nil {
+		return fixtures, err
+	}
+	return decodeFixtures(location, content)
+}
+
+func decodeFixtures(location string, content []byte) (fixtures Fixtures, err error) {
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".yaml", ".yml":
+		var temp interface{}
+		err = yaml.Unmarshal(content, &temp)
+		if err != nil {
+			return fixtures, fmt.Errorf("unable to read %v: %w", location, err)
+		}
+		content, err = json.Marshal(temp)
+		if err != nil {
+			return fixtures, fmt.Errorf("unable to read %v: %w", location, err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&fixtures)
+	if err != nil {
+		return fixtures, fmt.Errorf("unable to read %v: %w", location, err)
+	}
+	return fixtures, nil
+}
+
+// StartHermetic creates a hermetic environment with fixtures and a controller that uses all of its fakes
+func StartHermetic(ctx context.Context, wg *sync.WaitGroup, fixtures Fixtures) (env *Hermetic, ctrl *controller.Controller, err error) {
+	env = NewHermetic(ctx, wg)
+	env.Load(fixtures)
+	ctrl, err = NewController(ctx, &configuration.ConfigStruct{
+		DeviceRepoUrl:   env.DeviceRepoUrl,
+		ImportRepoUrl:   env.ImportRepoUrl,
+		ImportDeployUrl: env.ImportDeploy.Url(),
+	})
+	return env, ctrl, err
+}
+
+// Load stores fixtures in the fakes; metadata is stored first, so that device-group criteria can be computed
+func (this *Hermetic) Load(fixtures Fixtures) {
+	for _, aspect := range fixtures.Aspects {
+		this.DeviceRepository.SetAspect(aspect)
+	}
+	for _, function := range fixtures.Functions {
+		this.DeviceRepository.SetFunction(function)
+	}
+	for _, concept := range fixtures.Concepts {
+		this.DeviceRepository.SetConcept(concept)
+	}
+	for _, characteristic := range fixtures.Characteristics {
+		this.DeviceRepository.SetCharacteristic(characteristic)
+	}
+	for _, protocol := range fixtures.Protocols {
+		this.DeviceRepository.SetProtocol(protocol)
+	}
+	for _, deviceType := range fixtures.DeviceTypes {
+		this.DeviceRepository.SetDeviceType(deviceType)
+	}
+	for _, device := range fixtures.Devices {
+		if device.OwnerId == "" {
+			device.OwnerId = fixtures.Owner
+		}
+		this.DeviceRepository.SetDevice(device)
+	}
+	for _, group := range fixtures.DeviceGroups {
+		if len(group.Criteria) == 0 {
+			group.Criteria = this.DeviceRepository.DeviceGroupCriteria(group.DeviceIds)
+		}
+		this.DeviceRepository.SetDeviceGroup(group)
+	}
+	for _, importType := range fixtures.ImportTypes {
+		this.ImportRepository.SetImportType(importType)
+	}
+	if len(fixtures.Imports) > 0 {
+		this.ImportDeploy.SetInstances(fixtures.Imports)
+	}
+}
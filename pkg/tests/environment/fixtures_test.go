@@ -0,0 +1,84 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+)
+
+func TestHermeticFixtures(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+		FilterCriteria: model.FilterCriteriaAndSet{{
+			FunctionId: devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature",
+			AspectId:   "air",
+		}},
+		IncludeDevices: true,
+		IncludeGroups:  true,
+		IncludeImports: true,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ids := []string{}
+	for _, selectable := range result {
+		switch {
+		case selectable.Device != nil:
+			ids = append(ids, "device:"+selectable.Device.Id)
+			if selectable.Device.OwnerId != fixtures.Owner {
+				t.Error("unexpected owner", selectable.Device.OwnerId)
+			}
+		case selectable.DeviceGroup != nil:
+			ids = append(ids, "group:"+selectable.DeviceGroup.Id)
+		case selectable.Import != nil:
+			ids = append(ids, "import:"+selectable.Import.Id)
+		}
+	}
+	slices.Sort(ids)
+	expected := []string{"device:t1", "group:g1", "import:i1"}
+	if !slices.Equal(ids, expected) {
+		t.Errorf("\na=%v\ne=%v", ids, expected)
+	}
+
+	for name := range ctrl.CheckHealth(ctx).Dependencies {
+		t.Error("injected dependency should not be probed:", name)
+	}
+}
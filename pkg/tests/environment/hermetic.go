@@ -0,0 +1,125 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/docker"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/mock"
+)
+
+// TestEnvVariable selects the test environment: "hermetic" uses in-memory fakes instead of docker containers
+const TestEnvVariable = "DEVICE_SELECTION_TEST_ENV"
+
+func IsHermetic() bool {
+	return os.Getenv(TestEnvVariable) == "hermetic"
+}
+
+// Hermetic is an in-process replacement for the device-manager, device-repository, import-repository and import-deploy containers.
+// the repository urls are only keys to find the fakes in NewController; nothing listens on them.
+// the import-deploy fake also serves its api on ImportDeploy.Url(), but NewController calls it directly.
+type Hermetic struct {
+	DeviceRepository *mock.DeviceRepository
+	ImportRepository *mock.ImportRepository
+	DeviceManager    *mock.DeviceManager
+	ImportDeploy     *mock.ImportDeploy
+	DeviceRepoUrl    string
+	ImportRepoUrl    string
+}
+
+var hermeticMux sync.Mutex
+var hermeticEnvs = map[string]*Hermetic{}
+var hermeticCount = 0
+
+func NewHermetic(ctx context.Context, wg *sync.WaitGroup) *Hermetic {
+	hermeticMux.Lock()
+	defer hermeticMux.Unlock()
+	hermeticCount++
+	key := strconv.Itoa(hermeticCount)
+	repo := mock.NewDeviceRepository()
+	env := &Hermetic{
+		DeviceRepository: repo,
+		ImportRepository: mock.NewImportRepository(),
+		DeviceManager:    mock.NewDeviceManager(repo),
+		ImportDeploy:     mock.NewImportDeploy(),
+		DeviceRepoUrl:    "hermetic://device-repository/" + key,
+		ImportRepoUrl:    "hermetic://import-repository/" + key,
+	}
+	hermeticEnvs[env.DeviceRepoUrl] = env
+	hermeticEnvs[env.ImportRepoUrl] = env
+	hermeticEnvs[env.ImportDeploy.Url()] = env
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		env.DeviceManager.Stop()
+		env.ImportDeploy.Stop()
+		hermeticMux.Lock()
+		defer hermeticMux.Unlock()
+		delete(hermeticEnvs, env.DeviceRepoUrl)
+		delete(hermeticEnvs, env.ImportRepoUrl)
+		delete(hermeticEnvs, env.ImportDeploy.Url())
+	}()
+	return env
+}
+
+// Options replace the device-repository, import-repository and import-deploy clients of controller.NewWithOptions with the fakes
+func (this *Hermetic) Options() []controller.Option {
+	return []controller.Option{
+		controller.WithDeviceRepository(this.DeviceRepository),
+		controller.WithImportRepository(this.ImportRepository),
+		controller.WithImportDeploy(this.ImportDeploy),
+	}
+}
+
+// NewController creates the controller with the fakes of a hermetic environment, if config references its urls; otherwise it is controller.New
+func NewController(ctx context.Context, config configuration.Config) (*controller.Controller, error) {
+	return NewControllerWithOptions(ctx, config)
+}
+
+// NewControllerWithOptions works like NewController; options are applied after the fakes of the hermetic environment
+func NewControllerWithOptions(ctx context.Context, config configuration.Config, options ...controller.Option) (*controller.Controller, error) {
+	hermeticMux.Lock()
+	fakes := []controller.Option{}
+	if env, ok := hermeticEnvs[config.DeviceRepoUrl]; ok {
+		fakes = append(fakes, controller.WithDeviceRepository(env.DeviceRepository))
+	}
+	if env, ok := hermeticEnvs[config.ImportRepoUrl]; ok {
+		fakes = append(fakes, controller.WithImportRepository(env.ImportRepository))
+	}
+	if env, ok := hermeticEnvs[config.ImportDeployUrl]; ok {
+		fakes = append(fakes, controller.WithImportDeploy(env.ImportDeploy))
+	}
+	hermeticMux.Unlock()
+	return controller.NewWithOptions(ctx, config, append(fakes, options...)...)
+}
+
+// DeviceManagerWithDependencies starts the device-manager and its dependencies, as docker containers or, if IsHermetic(), in-process.
+// hermetic environments have no kafka and no permissions-v2; their urls are empty.
+func DeviceManagerWithDependencies(ctx context.Context, wg *sync.WaitGroup) (kafkaUrl string, deviceManagerUrl string, deviceRepoUrl string, permv2Url string, err error) {
+	if IsHermetic() {
+		env := NewHermetic(ctx, wg)
+		return "", env.DeviceManager.Url(), env.DeviceRepoUrl, "", nil
+	}
+	return docker.DeviceManagerWithDependenciesAndKafka(ctx, wg)
+}
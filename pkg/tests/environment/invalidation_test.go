@@ -0,0 +1,116 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller"
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+)
+
+type manualInvalidation struct {
+	invalidate func()
+}
+
+func (this *manualInvalidation) Start(ctx context.Context, invalidate func()) error {
+	this.invalidate = invalidate
+	return nil
+}
+
+func TestInjectedInvalidationSource(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.LoadFixtures("testdata/fixtures.yaml")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	env := environment.NewHermetic(ctx, wg)
+	env.Load(fixtures)
+
+	source := &manualInvalidation{}
+	ctrl, err := environment.NewControllerWithOptions(ctx, &configuration.ConfigStruct{
+		DeviceRepoUrl: env.DeviceRepoUrl,
+		ImportRepoUrl: env.ImportRepoUrl,
+	}, controller.WithInvalidationSource(source))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if source.invalidate == nil {
+		t.Error("invalidation source not started")
+		return
+	}
+
+	count := func() int {
+		result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+			FilterCriteria: model.FilterCriteriaAndSet{{
+				FunctionId: devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature",
+				AspectId:   "air",
+			}},
+			IncludeDevices: true,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		return len(result)
+	}
+
+	if c := count(); c != 1 {
+		t.Error("unexpected result count", c)
+		return
+	}
+	env.DeviceRepository.DeleteDeviceType("thermometer")
+	if c := count(); c != 1 {
+		t.Error("expected cached device-type selectables", c)
+		return
+	}
+	source.invalidate()
+	if c := count(); c != 0 {
+		t.Error("expected invalidated cache", c)
+	}
+
+	for name := range ctrl.CheckHealth(ctx).Dependencies {
+		t.Error("injected dependency should not be probed:", name)
+	}
+}
+
+func TestInvalidationSourceNotStartedOnError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &manualInvalidation{}
+	_, err := controller.NewWithOptions(ctx, &configuration.ConfigStruct{
+		DeviceRepoUrl:     "http://device-repo:8080",
+		ImportRepoTimeout: "5",
+	}, controller.WithInvalidationSource(source))
+	if err == nil {
+		t.Error("expected error for invalid import_repo_timeout")
+	}
+	if source.invalidate != nil {
+		t.Error("invalidation source should not be started if the controller can not be created")
+	}
+}
@@ -20,12 +20,11 @@ import (
 	"context"
 	"github.com/SENERGY-Platform/device-selection/pkg/api"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
-	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
 	"net/http/httptest"
 	"sync"
-	"time"
 )
 
 func Testenv(ctx context.Context, wg *sync.WaitGroup) (managerurl string, repourl string, permv2Url string, selectionurl string, err error) {
@@ -39,7 +38,7 @@ func Testenv(ctx context.Context, wg *sync.WaitGroup) (managerurl string, repour
 		Debug:         true,
 	}
 
-	ctrl, err := controller.New(ctx, c)
+	ctrl, err := environment.NewController(ctx, c)
 	if err != nil {
 		return managerurl, repourl, permv2Url, selectionurl, err
 	}
@@ -54,7 +53,7 @@ func Testenv(ctx context.Context, wg *sync.WaitGroup) (managerurl string, repour
 	}()
 	selectionurl = selectionApi.URL
 
-	time.Sleep(2 * time.Second)
+	helper.WaitForAsyncUpdates()
 
 	return
 }
@@ -197,7 +196,7 @@ func TestenvWithoutApi(ctx context.Context, wg *sync.WaitGroup) (managerurl stri
 		}
 	}
 
-	time.Sleep(2 * time.Second)
+	helper.WaitForAsyncUpdates()
 
 	return
 }
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/julienschmidt/httprouter"
+)
+
+// DeviceManager serves the device-manager endpoints used by the tests and writes directly into a DeviceRepository.
+// changes are visible immediately, no kafka is involved.
+type DeviceManager struct {
+	repo *DeviceRepository
+	ts   *httptest.Server
+}
+
+func NewDeviceManager(repo *DeviceRepository) *DeviceManager {
+	manager := &DeviceManager{repo: repo}
+
+	router := httprouter.New()
+
+	router.PUT("/device-types/:id", handleSet(func(deviceType models.DeviceType, id string, user string) (models.DeviceType, error) {
+		deviceType.Id = id
+		deviceType.GenerateId()
+		repo.SetDeviceType(deviceType)
+		return deviceType, nil
+	}))
+	router.POST("/device-types", handleSet(func(deviceType models.DeviceType, id string, user string) (models.DeviceType, error) {
+		deviceType.Id = id
+		deviceType.GenerateId()
+		repo.SetDeviceType(deviceType)
+		return deviceType, nil
+	}))
+	router.PUT("/devices/:id", handleSet(setDevice(repo)))
+	router.POST("/devices", handleSet(setDevice(repo)))
+	router.PUT("/device-groups/:id", handleSet(setDeviceGroup(repo)))
+	router.POST("/device-groups", handleSet(setDeviceGroup(repo)))
+	router.PUT("/functions/:id", handleSet(func(function models.Function, id string, user string) (models.Function, error) {
+		function.Id = id
+		repo.SetFunction(function)
+		return function, nil
+	}))
+	router.PUT("/aspects/:id", handleSet(func(aspect models.Aspect, id string, user string) (models.Aspect, error) {
+		aspect.Id = id
+		repo.SetAspect(aspect)
+		return aspect, nil
+	}))
+	router.PUT("/concepts/:id", handleSet(func(concept models.Concept, id string, user string) (models.Concept, error) {
+		concept.Id = id
+		repo.SetConcept(concept)
+		return concept, nil
+	}))
+
+	manager.ts = &httptest.Server{
+		Config: &http.Server{Handler: router},
+	}
+	manager.ts.Listener, _ = net.Listen("tcp", ":")
+	manager.ts.Start()
+
+	return manager
+}
+
+func (this *DeviceManager) Stop() {
+	this.ts.Close()
+}
+
+func (this *DeviceManager) Url() string {
+	return this.ts.URL
+}
+
+func setDevice(repo *DeviceRepository) func(device models.Device, id string, user string) (models.Device, error) {
+	return func(device models.Device, id string, user string) (models.Device, error) {
+		device.Id = id
+		if id == "" {
+			device.GenerateId()
+		}
+		if device.OwnerId == "" {
+			device.OwnerId = user
+		}
+		repo.SetDevice(device)
+		return device, nil
+	}
+}
+
+func setDeviceGroup(repo *DeviceRepository) func(group models.DeviceGroup, id string, user string) (models.DeviceGroup, error) {
+	return func(group models.DeviceGroup, id string, user string) (models.DeviceGroup, error) {
+		group.Id = id
+		group.GenerateId()
+		group.Criteria = repo.DeviceGroupCriteria(group.DeviceIds)
+		repo.SetDeviceGroup(group)
+		return group, nil
+	}
+}
+
+// handleSet decodes the request body, calls set with the path id (empty for POST requests) and responds with the stored element
+func handleSet[T any](set func(element T, id string, user string) (T, error)) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		token, err := jwt.Parse(request.Header.Get("Authorization"))
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var element T
+		err = json.NewDecoder(request.Body).Decode(&element)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		element, err = set(element, params.ByName("id"), token.GetUserId())
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		err = json.NewEncoder(writer).Encode(element)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
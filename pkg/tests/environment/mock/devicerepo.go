@@ -0,0 +1,542 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// DeviceRepository is an in-memory device-repository, to run tests without docker.
+// it implements the methods of client.Interface that are used by device-selection; other methods panic.
+//
+// permissions: the owner of a device or device-group, admins and (if the resource has no owner) every user have all rights;
+// SetPermissions overwrites the rights of a user.
+type DeviceRepository struct {
+	client.Interface
+
+	mux             sync.RWMutex
+	deviceTypes     map[string]models.DeviceType
+	devices         map[string]models.Device
+	deviceGroups    map[string]models.DeviceGroup
+	functions       map[string]models.Function
+	aspects         map[string]models.Aspect
+	aspectNodes     map[string]models.AspectNode
+	concepts        map[string]models.Concept
+	characteristics map[string]models.Characteristic
+	protocols       map[string]models.Protocol
+	permissions     map[string]map[string]models.Permissions
+}
+
+func NewDeviceRepository() *DeviceRepository {
+	return &DeviceRepository{
+		deviceTypes:     map[string]models.DeviceType{},
+		devices:         map[string]models.Device{},
+		deviceGroups:    map[string]models.DeviceGroup{},
+		functions:       map[string]models.Function{},
+		aspects:         map[string]models.Aspect{},
+		aspectNodes:     map[string]models.AspectNode{},
+		concepts:        map[string]models.Concept{},
+		characteristics: map[string]models.Characteristic{},
+		protocols:       map[string]models.Protocol{},
+		permissions:     map[string]map[string]models.Permissions{},
+	}
+}
+
+var errNotFound = errors.New("not found")
+var errForbidden = errors.New("access denied")
+
+func (this *DeviceRepository) SetDeviceType(deviceType models.DeviceType) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.deviceTypes[deviceType.Id] = deviceType
+}
+
+func (this *DeviceRepository) SetDevice(device models.Device) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.devices[device.Id] = device
+}
+
+// SetDeviceGroup stores group as is; use DeviceGroupCriteria to compute the criteria of its devices
+func (this *DeviceRepository) SetDeviceGroup(group models.DeviceGroup) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	group.SetShortCriteria()
+	this.deviceGroups[group.Id] = group
+}
+
+// SetFunction sets a missing rdf type by the id prefix
+func (this *DeviceRepository) SetFunction(function models.Function) {
+	if function.RdfType == "" {
+		switch {
+		case strings.HasPrefix(function.Id, models.CONTROLLING_FUNCTION_PREFIX):
+			function.RdfType = models.SES_ONTOLOGY_CONTROLLING_FUNCTION
+		case strings.HasPrefix(function.Id, models.MEASURING_FUNCTION_PREFIX):
+			function.RdfType = models.SES_ONTOLOGY_MEASURING_FUNCTION
+		}
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.functions[function.Id] = function
+}
+
+// SetAspect stores a root aspect and updates the aspect nodes of the aspect and its sub aspects
+func (this *DeviceRepository) SetAspect(aspect models.Aspect) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if old, ok := this.aspects[aspect.Id]; ok {
+		for _, id := range append(this.aspectNodes[old.Id].DescendentIds, old.Id) {
+			delete(this.aspectNodes, id)
+		}
+	}
+	this.aspects[aspect.Id] = aspect
+	this.setAspectNodes(aspect, aspect.Id, "", []string{})
+}
+
+func (this *DeviceRepository) setAspectNodes(aspect models.Aspect, rootId string, parentId string, ancestors []string) (descendents []string) {
+	node := models.AspectNode{
+		Id:            aspect.Id,
+		Name:          aspect.Name,
+		RootId:        rootId,
+		ParentId:      parentId,
+		ChildIds:      []string{},
+		AncestorIds:   ancestors,
+		DescendentIds: []string{},
+	}
+	childAncestors := append([]string{aspect.Id}, ancestors...)
+	for _, sub := range aspect.SubAspects {
+		node.ChildIds = append(node.ChildIds, sub.Id)
+		node.DescendentIds = append(node.DescendentIds, sub.Id)
+		node.DescendentIds = append(node.DescendentIds, this.setAspectNodes(sub, rootId, aspect.Id, slices.Clone(childAncestors))...)
+	}
+	sort.Strings(node.ChildIds)
+	sort.Strings(node.DescendentIds)
+	this.aspectNodes[aspect.Id] = node
+	return node.DescendentIds
+}
+
+func (this *DeviceRepository) SetConcept(concept models.Concept) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.concepts[concept.Id] = concept
+}
+
+func (this *DeviceRepository) SetCharacteristic(characteristic models.Characteristic) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.characteristics[characteristic.Id] = characteristic
+}
+
+func (this *DeviceRepository) SetProtocol(protocol models.Protocol) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.protocols[protocol.Id] = protocol
+}
+
+// SetPermissions overwrites the rights of userId on the device or device-group resourceId
+func (this *DeviceRepository) SetPermissions(resourceId string, userId string, permissions models.Permissions) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.permissions[resourceId] == nil {
+		this.permissions[resourceId] = map[string]models.Permissions{}
+	}
+	this.permissions[resourceId][userId] = permissions
+}
+
+func (this *DeviceRepository) DeleteDevice(id string) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	delete(this.devices, id)
+}
+
+func (this *DeviceRepository) DeleteDeviceType(id string) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	delete(this.deviceTypes, id)
+}
+
+func (this *DeviceRepository) DeleteDeviceGroup(id string) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	delete(this.deviceGroups, id)
+}
+
+func (this *DeviceRepository) ReadDevice(id string, token string, action client.AuthAction) (result models.Device, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	device, ok := this.getDevice(id)
+	if !ok {
+		return result, errNotFound, http.StatusNotFound
+	}
+	permissions, _, err := this.getPermissions(token, device.Id, device.OwnerId)
+	if err != nil {
+		return result, err, http.StatusUnauthorized
+	}
+	if !hasPermission(permissions, action) {
+		return result, errForbidden, http.StatusForbidden
+	}
+	return device.Device, nil, http.StatusOK
+}
+
+func (this *DeviceRepository) ListDevices(token string, options client.DeviceListOptions) (result []models.Device, err error, errCode int) {
+	devices, _, err, code := this.ListExtendedDevices(token, client.ExtendedDeviceListOptions{
+		Ids:           options.Ids,
+		Search:        options.Search,
+		Limit:         options.Limit,
+		Offset:        options.Offset,
+		SortBy:        options.SortBy,
+		Permission:    options.Permission,
+		DeviceTypeIds: options.DeviceTypeIds,
+		LocalIds:      options.LocalIds,
+		Owner:         options.Owner,
+	})
+	result = []models.Device{}
+	for _, device := range devices {
+		result = append(result, device.Device)
+	}
+	return result, err, code
+}
+
+// ListExtendedDevices filters like the device-repository: nil lists are ignored, empty lists match nothing.
+// modified device ids (e.g. with service_group_selection) are resolved if they are listed in options.Ids.
+func (this *DeviceRepository) ListExtendedDevices(token string, options client.ExtendedDeviceListOptions) (result []models.ExtendedDevice, total int64, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	user, err := jwt.Parse(token)
+	if err != nil {
+		return result, 0, err, http.StatusUnauthorized
+	}
+	if options.Permission == "" {
+		options.Permission = client.READ
+	}
+	candidates := []models.ExtendedDevice{}
+	if options.Ids != nil {
+		for _, id := range options.Ids {
+			if device, ok := this.getDevice(id); ok {
+				candidates = append(candidates, device)
+			}
+		}
+	} else {
+		for id := range this.devices {
+			device, _ := this.getDevice(id)
+			candidates = append(candidates, device)
+		}
+	}
+	if options.LocalIds != nil && options.Owner == "" {
+		options.Owner = user.GetUserId()
+	}
+	result = []models.ExtendedDevice{}
+	for _, device := range candidates {
+		if options.DeviceTypeIds != nil && !slices.Contains(options.DeviceTypeIds, device.DeviceTypeId) {
+			continue
+		}
+		if options.LocalIds != nil && !slices.Contains(options.LocalIds, device.LocalId) {
+			continue
+		}
+		if options.Owner != "" && device.OwnerId != options.Owner {
+			continue
+		}
+		if len(options.AttributeKeys) > 0 && !hasAnyAttribute(device.Attributes, options.AttributeKeys) {
+			continue
+		}
+		if options.Search != "" && !strings.Contains(strings.ToLower(device.DisplayName), strings.ToLower(options.Search)) {
+			continue
+		}
+		device.Permissions, device.Shared, _ = this.getPermissions(token, device.Id, device.OwnerId)
+		if !hasPermission(device.Permissions, options.Permission) {
+			continue
+		}
+		result = append(result, device)
+	}
+	sortByName(result, options.SortBy, func(device models.ExtendedDevice) (string, string) { return device.DisplayName, device.Id })
+	total = int64(len(result))
+	return paginate(result, options.Limit, options.Offset), total, nil, http.StatusOK
+}
+
+// getDevice returns the device with id, including devices with id modifiers; the caller must hold the lock
+func (this *DeviceRepository) getDevice(id string) (result models.ExtendedDevice, ok bool) {
+	device, ok := this.devices[id]
+	groupName := ""
+	if !ok {
+		device, groupName, ok = this.getModifiedDevice(id)
+		if !ok {
+			return result, false
+		}
+	}
+	result = models.ExtendedDevice{Device: device, DisplayName: device.Name}
+	for _, attr := range device.Attributes {
+		if attr.Key == displayNameAttributeKey && attr.Value != "" {
+			result.DisplayName = attr.Value
+			if groupName != "" {
+				result.DisplayName = result.DisplayName + " " + groupName
+			}
+		}
+	}
+	if deviceType, ok := this.getDeviceType(device.DeviceTypeId); ok {
+		result.DeviceTypeName = deviceType.Name
+	}
+	return result, true
+}
+
+const displayNameAttributeKey = "shared/nickname"
+
+func (this *DeviceRepository) ReadDeviceType(id string, token string) (result models.DeviceType, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result, ok := this.getDeviceType(id)
+	if !ok {
+		return result, errNotFound, http.StatusNotFound
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *DeviceRepository) ListDeviceTypesV3(token string, options client.DeviceTypeListOptions) (result []models.DeviceType, total int64, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result = []models.DeviceType{}
+	for _, deviceType := range this.listDeviceTypes(options.IncludeModified, options.IgnoreUnmodified) {
+		if options.Ids != nil && !slices.Contains(options.Ids, deviceType.Id) {
+			continue
+		}
+		if options.Search != "" && !strings.Contains(strings.ToLower(deviceType.Name), strings.ToLower(options.Search)) {
+			continue
+		}
+		if len(options.Criteria) > 0 {
+			if _, ok := this.getSelectable(deviceType, options.Criteria, "", nil, false); !ok {
+				continue
+			}
+		}
+		result = append(result, deviceType)
+	}
+	sortByName(result, options.SortBy, func(deviceType models.DeviceType) (string, string) { return deviceType.Name, deviceType.Id })
+	total = int64(len(result))
+	return paginate(result, options.Limit, options.Offset), total, nil, http.StatusOK
+}
+
+func (this *DeviceRepository) ReadDeviceGroup(id string, token string, filterGenericDuplicateCriteria bool) (result models.DeviceGroup, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result, ok := this.deviceGroups[id]
+	if !ok {
+		return result, errNotFound, http.StatusNotFound
+	}
+	permissions, _, err := this.getPermissions(token, result.Id, this.deviceGroupOwner(result))
+	if err != nil {
+		return result, err, http.StatusUnauthorized
+	}
+	if !permissions.Read {
+		return result, errForbidden, http.StatusForbidden
+	}
+	return result, nil, http.StatusOK
+}
+
+// ListDeviceGroups returns groups that have a criterion equal to each of options.Criteria; empty fields of options.Criteria match every value
+func (this *DeviceRepository) ListDeviceGroups(token string, options client.DeviceGroupListOptions) (result []models.DeviceGroup, total int64, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	_, err = jwt.Parse(token)
+	if err != nil {
+		return result, 0, err, http.StatusUnauthorized
+	}
+	if options.Permission == "" {
+		options.Permission = client.READ
+	}
+	result = []models.DeviceGroup{}
+	for _, group := range this.deviceGroups {
+		if options.Ids != nil && !slices.Contains(options.Ids, group.Id) {
+			continue
+		}
+		if options.IgnoreGenerated && group.AutoGeneratedByDevice != "" {
+			continue
+		}
+		if options.Search != "" && !strings.Contains(strings.ToLower(group.Name), strings.ToLower(options.Search)) {
+			continue
+		}
+		if !groupMatchesCriteria(group, options.Criteria) {
+			continue
+		}
+		permissions, _, _ := this.getPermissions(token, group.Id, this.deviceGroupOwner(group))
+		if !hasPermission(permissions, options.Permission) {
+			continue
+		}
+		result = append(result, group)
+	}
+	sortByName(result, options.SortBy, func(group models.DeviceGroup) (string, string) { return group.Name, group.Id })
+	total = int64(len(result))
+	return paginate(result, options.Limit, options.Offset), total, nil, http.StatusOK
+}
+
+func groupMatchesCriteria(group models.DeviceGroup, criteria []client.FilterCriteria) bool {
+	for _, c := range criteria {
+		found := false
+		for _, groupCriterion := range group.Criteria {
+			if groupCriterion.FunctionId == c.FunctionId &&
+				(c.AspectId == "" || groupCriterion.AspectId == c.AspectId) &&
+				(c.DeviceClassId == "" || groupCriterion.DeviceClassId == c.DeviceClassId) &&
+				(c.Interaction == "" || groupCriterion.Interaction == c.Interaction) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// deviceGroupOwner is the owner of the first device; groups without devices have no owner
+func (this *DeviceRepository) deviceGroupOwner(group models.DeviceGroup) string {
+	for _, id := range group.DeviceIds {
+		if device, ok := this.getDevice(id); ok {
+			return device.OwnerId
+		}
+	}
+	return ""
+}
+
+func (this *DeviceRepository) GetFunctionsByType(rdfType string) (result []models.Function, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result = []models.Function{}
+	for _, function := range this.functions {
+		if function.RdfType == rdfType {
+			result = append(result, function)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result, nil, http.StatusOK
+}
+
+func (this *DeviceRepository) GetAspectNode(id string) (result models.AspectNode, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result, ok := this.aspectNodes[id]
+	if !ok {
+		return result, errNotFound, http.StatusNotFound
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *DeviceRepository) GetConceptWithoutCharacteristics(id string) (result models.Concept, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result, ok := this.concepts[id]
+	if !ok {
+		return result, errNotFound, http.StatusNotFound
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *DeviceRepository) GetCharacteristic(id string) (result models.Characteristic, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result, ok := this.characteristics[id]
+	if !ok {
+		return result, errNotFound, http.StatusNotFound
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *DeviceRepository) ListProtocols(token string, limit int64, offset int64, sortBy string) (result []models.Protocol, err error, errCode int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result = []models.Protocol{}
+	for _, protocol := range this.protocols {
+		result = append(result, protocol)
+	}
+	sortByName(result, sortBy, func(protocol models.Protocol) (string, string) { return protocol.Name, protocol.Id })
+	return paginate(result, limit, offset), nil, http.StatusOK
+}
+
+// getPermissions of the user of token on a device or device-group; the caller must hold the lock
+func (this *DeviceRepository) getPermissions(token string, resourceId string, owner string) (permissions models.Permissions, shared bool, err error) {
+	user, err := jwt.Parse(token)
+	if err != nil {
+		return permissions, false, err
+	}
+	shared = owner != "" && owner != user.GetUserId()
+	if explicit, ok := this.permissions[resourceId][user.GetUserId()]; ok {
+		return explicit, shared, nil
+	}
+	if owner == "" || !shared || user.IsAdmin() {
+		return models.Permissions{Read: true, Write: true, Execute: true, Administrate: true}, shared, nil
+	}
+	return permissions, shared, nil
+}
+
+func hasPermission(permissions models.Permissions, action client.AuthAction) bool {
+	switch action {
+	case client.WRITE:
+		return permissions.Write
+	case client.EXECUTE:
+		return permissions.Execute
+	case client.ADMINISTRATE:
+		return permissions.Administrate
+	default:
+		return permissions.Read
+	}
+}
+
+func hasAnyAttribute(attributes []models.Attribute, keys []string) bool {
+	for _, attr := range attributes {
+		if slices.Contains(keys, attr.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortByName sorts by name and id; sortBy "name.desc" reverses the order, other values are ignored
+func sortByName[T any](list []T, sortBy string, nameAndId func(T) (string, string)) {
+	sort.Slice(list, func(i, j int) bool {
+		nameI, idI := nameAndId(list[i])
+		nameJ, idJ := nameAndId(list[j])
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		return idI < idJ
+	})
+	if sortBy == "name.desc" {
+		slices.Reverse(list)
+	}
+}
+
+// paginate ignores limits <= 0
+func paginate[T any](list []T, limit int64, offset int64) []T {
+	if offset >= int64(len(list)) {
+		return []T{}
+	}
+	if offset > 0 {
+		list = list[offset:]
+	}
+	if limit > 0 && limit < int64(len(list)) {
+		list = list[:limit]
+	}
+	return list
+}
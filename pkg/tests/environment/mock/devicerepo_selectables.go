@@ -0,0 +1,368 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	"github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/controller/idmodifier"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+const serviceGroupSelectionModifier = "service_group_selection"
+
+func (this *DeviceRepository) GetDeviceTypeSelectables(query []client.FilterCriteria, pathPrefix string, interactionsFilter []models.Interaction, includeModified bool) (result []model.DeviceTypeSelectable, err error, code int) {
+	return this.getSelectables(query, pathPrefix, interactionsFilter, includeModified, false), nil, http.StatusOK
+}
+
+func (this *DeviceRepository) GetDeviceTypeSelectablesV2(query []client.FilterCriteria, pathPrefix string, includeModified bool, servicesMustMatchAllCriteria bool) (result []model.DeviceTypeSelectable, err error, code int) {
+	return this.getSelectables(query, pathPrefix, nil, includeModified, servicesMustMatchAllCriteria), nil, http.StatusOK
+}
+
+func (this *DeviceRepository) getSelectables(query []client.FilterCriteria, pathPrefix string, interactionsFilter []models.Interaction, includeModified bool, servicesMustMatchAllCriteria bool) (result []model.DeviceTypeSelectable) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result = []model.DeviceTypeSelectable{}
+	deviceTypes := this.listDeviceTypes(includeModified, false)
+	sortByName(deviceTypes, "name.asc", func(deviceType models.DeviceType) (string, string) { return deviceType.Name, deviceType.Id })
+	for _, deviceType := range deviceTypes {
+		if selectable, ok := this.getSelectable(deviceType, query, pathPrefix, interactionsFilter, servicesMustMatchAllCriteria); ok {
+			result = append(result, selectable)
+		}
+	}
+	return result
+}
+
+// getSelectable returns the services and path options of deviceType matching criteria; ok is false if a criterion is not matched.
+// criteria without function match by device class. the caller must hold the lock.
+func (this *DeviceRepository) getSelectable(deviceType models.DeviceType, criteria []client.FilterCriteria, pathPrefix string, interactionsFilter []models.Interaction, servicesMustMatchAllCriteria bool) (result model.DeviceTypeSelectable, ok bool) {
+	result = model.DeviceTypeSelectable{
+		DeviceTypeId:       deviceType.Id,
+		Services:           []models.Service{},
+		ServicePathOptions: map[string][]model.ServicePathOption{},
+	}
+	matched := make([]bool, len(criteria))
+	for i, c := range criteria {
+		if c.FunctionId == "" {
+			matched[i] = c.DeviceClassId == "" || c.DeviceClassId == deviceType.DeviceClassId
+		}
+	}
+	for _, service := range deviceType.Services {
+		if len(interactionsFilter) > 0 && !slices.Contains(interactionsFilter, service.Interaction) {
+			continue
+		}
+		options := []model.ServicePathOption{}
+		serviceMatched := slices.Clone(matched)
+		for i, c := range criteria {
+			if c.FunctionId == "" {
+				continue
+			}
+			found := this.getPathOptions(deviceType, service, c, pathPrefix)
+			if len(found) > 0 {
+				serviceMatched[i] = true
+				options = appendNewPathOptions(options, found)
+			}
+		}
+		if servicesMustMatchAllCriteria && slices.Contains(serviceMatched, false) {
+			continue
+		}
+		for i := range matched {
+			matched[i] = matched[i] || serviceMatched[i]
+		}
+		if len(options) > 0 {
+			sort.SliceStable(options, func(i, j int) bool {
+				return options[i].Path < options[j].Path
+			})
+			result.Services = append(result.Services, service)
+			result.ServicePathOptions[service.Id] = options
+		}
+	}
+	return result, !slices.Contains(matched, false)
+}
+
+func appendNewPathOptions(list []model.ServicePathOption, options []model.ServicePathOption) []model.ServicePathOption {
+	for _, option := range options {
+		if !slices.ContainsFunc(list, func(element model.ServicePathOption) bool {
+			return element.Path == option.Path && element.FunctionId == option.FunctionId && element.AspectNode.Id == option.AspectNode.Id
+		}) {
+			list = append(list, option)
+		}
+	}
+	return list
+}
+
+// getPathOptions returns a path option for every content variable of service that matches criteria;
+// measuring functions are searched in the outputs, controlling functions in the inputs
+func (this *DeviceRepository) getPathOptions(deviceType models.DeviceType, service models.Service, criteria client.FilterCriteria, pathPrefix string) (result []model.ServicePathOption) {
+	if criteria.DeviceClassId != "" && criteria.DeviceClassId != deviceType.DeviceClassId {
+		return nil
+	}
+	if criteria.Interaction != "" && criteria.Interaction != service.Interaction && service.Interaction != models.EVENT_AND_REQUEST {
+		return nil
+	}
+	controlling, known := this.getFunctionType(criteria.FunctionId)
+	contents := service.Outputs
+	if controlling {
+		contents = service.Inputs
+	}
+	if !known {
+		contents = append(slices.Clone(service.Inputs), service.Outputs...)
+	}
+	for _, content := range contents {
+		forEachVariable(content.ContentVariable, pathPrefix, func(variable models.ContentVariable, path string) {
+			if variable.FunctionId != criteria.FunctionId || !this.aspectMatches(criteria.AspectId, variable.AspectId) {
+				return
+			}
+			option := model.ServicePathOption{
+				ServiceId:             service.Id,
+				Path:                  path,
+				CharacteristicId:      variable.CharacteristicId,
+				FunctionId:            variable.FunctionId,
+				IsVoid:                variable.IsVoid,
+				Value:                 variable.Value,
+				Type:                  variable.Type,
+				IsControllingFunction: controlling,
+				Configurables:         getConfigurables(service, path),
+				Interaction:           service.Interaction,
+			}
+			if variable.AspectId != "" {
+				option.AspectNode = this.aspectNodes[variable.AspectId]
+			}
+			result = append(result, option)
+		})
+	}
+	return result
+}
+
+// getFunctionType uses the rdf type of stored functions or the id prefix; functions with other ids are unknown
+func (this *DeviceRepository) getFunctionType(functionId string) (controlling bool, known bool) {
+	if function, ok := this.functions[functionId]; ok && function.RdfType != "" {
+		return function.RdfType == models.SES_ONTOLOGY_CONTROLLING_FUNCTION, true
+	}
+	switch {
+	case strings.HasPrefix(functionId, models.CONTROLLING_FUNCTION_PREFIX):
+		return true, true
+	case strings.HasPrefix(functionId, models.MEASURING_FUNCTION_PREFIX):
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// aspectMatches if expected is empty or actual is expected or one of its descendents
+func (this *DeviceRepository) aspectMatches(expected string, actual string) bool {
+	if expected == "" || expected == actual {
+		return true
+	}
+	return slices.Contains(this.aspectNodes[expected].DescendentIds, actual)
+}
+
+// getConfigurables returns the input variables of service without function, except the variable at excludedPath
+func getConfigurables(service models.Service, excludedPath string) (result []model.Configurable) {
+	for _, content := range service.Inputs {
+		forEachVariable(content.ContentVariable, "", func(variable models.ContentVariable, path string) {
+			if len(variable.SubContentVariables) > 0 || variable.FunctionId != "" || variable.IsVoid || path == excludedPath {
+				return
+			}
+			result = append(result, model.Configurable{
+				Path:             path,
+				CharacteristicId: variable.CharacteristicId,
+				Value:            variable.Value,
+				Type:             variable.Type,
+			})
+		})
+	}
+	return result
+}
+
+// forEachVariable calls f for variable and all sub variables with the path of names joined by "."
+func forEachVariable(variable models.ContentVariable, parentPath string, f func(variable models.ContentVariable, path string)) {
+	path := variable.Name
+	if parentPath != "" {
+		path = parentPath + "." + variable.Name
+	}
+	f(variable, path)
+	for _, sub := range variable.SubContentVariables {
+		forEachVariable(sub, path, f)
+	}
+}
+
+// listDeviceTypes returns device types and, if includeModified, their service group selections; the caller must hold the lock
+func (this *DeviceRepository) listDeviceTypes(includeModified bool, ignoreUnmodified bool) (result []models.DeviceType) {
+	result = []models.DeviceType{}
+	for _, deviceType := range this.deviceTypes {
+		if !ignoreUnmodified {
+			result = append(result, deviceType)
+		}
+		if includeModified {
+			for _, group := range deviceType.ServiceGroups {
+				modified, _ := selectServiceGroup(deviceType, group.Key)
+				result = append(result, modified)
+			}
+		}
+	}
+	return result
+}
+
+// getDeviceType returns the device type with id, including modified device types; the caller must hold the lock
+func (this *DeviceRepository) getDeviceType(id string) (result models.DeviceType, ok bool) {
+	result, ok = this.deviceTypes[id]
+	if ok {
+		return result, true
+	}
+	pureId, modifier := idmodifier.SplitModifier(id)
+	result, ok = this.deviceTypes[pureId]
+	if !ok || pureId == id {
+		return result, false
+	}
+	groupKey, ok := getServiceGroupSelection(modifier)
+	if !ok {
+		return result, false
+	}
+	return selectServiceGroup(result, groupKey)
+}
+
+// getModifiedDevice returns a device with id modifier, e.g. "device$service_group_selection=sg1"; the caller must hold the lock
+func (this *DeviceRepository) getModifiedDevice(id string) (result models.Device, groupName string, ok bool) {
+	pureId, modifier := idmodifier.SplitModifier(id)
+	result, ok = this.devices[pureId]
+	if !ok || pureId == id {
+		return result, "", false
+	}
+	groupKey, ok := getServiceGroupSelection(modifier)
+	if !ok {
+		return result, "", false
+	}
+	deviceType, ok := this.deviceTypes[result.DeviceTypeId]
+	if !ok {
+		return result, "", false
+	}
+	modifiedType, ok := selectServiceGroup(deviceType, groupKey)
+	if !ok {
+		return result, "", false
+	}
+	for _, group := range deviceType.ServiceGroups {
+		if group.Key == groupKey {
+			groupName = group.Name
+		}
+	}
+	result.Id = idmodifier.JoinModifier(pureId, modifier)
+	result.DeviceTypeId = modifiedType.Id
+	result.Name = result.Name + " " + groupName
+	return result, groupName, true
+}
+
+func getServiceGroupSelection(modifier map[string][]string) (groupKey string, ok bool) {
+	keys := modifier[serviceGroupSelectionModifier]
+	if len(modifier) != 1 || len(keys) != 1 {
+		return "", false
+	}
+	return keys[0], true
+}
+
+// selectServiceGroup returns the modified device type with the services of the service group and services without group
+func selectServiceGroup(deviceType models.DeviceType, groupKey string) (result models.DeviceType, ok bool) {
+	for _, group := range deviceType.ServiceGroups {
+		if group.Key == groupKey {
+			result = deviceType
+			result.Id = idmodifier.JoinModifier(deviceType.Id, map[string][]string{serviceGroupSelectionModifier: {groupKey}})
+			result.Name = deviceType.Name + " " + group.Name
+			result.Services = []models.Service{}
+			for _, service := range deviceType.Services {
+				if service.ServiceGroupKey == groupKey || service.ServiceGroupKey == "" {
+					result.Services = append(result.Services, service)
+				}
+			}
+			return result, true
+		}
+	}
+	return deviceType, false
+}
+
+// DeviceGroupCriteria computes the criteria that all devices of a device-group fulfill, like the device-manager does on device-group updates:
+// measuring functions with aspect (and its ancestors), controlling functions with device class, each with the interaction of the service
+func (this *DeviceRepository) DeviceGroupCriteria(deviceIds []string) (result []models.DeviceGroupFilterCriteria) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	var current map[string]models.DeviceGroupFilterCriteria
+	for _, id := range deviceIds {
+		deviceCriteria := this.deviceCriteria(id)
+		if current == nil {
+			current = deviceCriteria
+			continue
+		}
+		for key := range current {
+			if _, ok := deviceCriteria[key]; !ok {
+				delete(current, key)
+			}
+		}
+	}
+	result = []models.DeviceGroupFilterCriteria{}
+	for _, criteria := range current {
+		result = append(result, criteria)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Short() < result[j].Short()
+	})
+	return result
+}
+
+func (this *DeviceRepository) deviceCriteria(deviceId string) (result map[string]models.DeviceGroupFilterCriteria) {
+	result = map[string]models.DeviceGroupFilterCriteria{}
+	device, ok := this.getDevice(deviceId)
+	if !ok {
+		return result
+	}
+	deviceType, ok := this.getDeviceType(device.DeviceTypeId)
+	if !ok {
+		return result
+	}
+	add := func(criteria models.DeviceGroupFilterCriteria) {
+		result[criteria.Short()] = criteria
+	}
+	for _, service := range deviceType.Services {
+		interactions := []models.Interaction{service.Interaction}
+		if service.Interaction == models.EVENT_AND_REQUEST {
+			interactions = []models.Interaction{models.EVENT, models.REQUEST}
+		}
+		for _, content := range append(slices.Clone(service.Inputs), service.Outputs...) {
+			forEachVariable(content.ContentVariable, "", func(variable models.ContentVariable, path string) {
+				if variable.FunctionId == "" {
+					return
+				}
+				for _, interaction := range interactions {
+					if !strings.HasPrefix(variable.FunctionId, models.MEASURING_FUNCTION_PREFIX) {
+						add(models.DeviceGroupFilterCriteria{FunctionId: variable.FunctionId, DeviceClassId: deviceType.DeviceClassId, Interaction: interaction})
+						continue
+					}
+					add(models.DeviceGroupFilterCriteria{FunctionId: variable.FunctionId, AspectId: variable.AspectId, Interaction: interaction})
+					if variable.AspectId != "" {
+						for _, ancestor := range this.aspectNodes[variable.AspectId].AncestorIds {
+							add(models.DeviceGroupFilterCriteria{FunctionId: variable.FunctionId, AspectId: ancestor, Interaction: interaction})
+						}
+					}
+				}
+			})
+		}
+	}
+	return result
+}
@@ -17,15 +17,24 @@
 package mock
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/SENERGY-Platform/device-selection/pkg/model"
-	"github.com/julienschmidt/httprouter"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/julienschmidt/httprouter"
 )
 
+// ImportDeploy fakes the import-deploy api as http server (Url) and, for controller.WithImportDeploy, as upstream.ImportDeploy.
+// instances are not filtered by user.
 type ImportDeploy struct {
+	mux       sync.Mutex
 	instances []model.Import
 	ts        *httptest.Server
 }
@@ -38,7 +47,18 @@ func NewImportDeploy() *ImportDeploy {
 	router := httprouter.New()
 
 	router.GET("/instances", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-		err := json.NewEncoder(writer).Encode(deploy.instances)
+		query := request.URL.Query()
+		result := deploy.list(nil)
+		if query.Has("import_type_ids") {
+			result = deploy.list(strings.Split(query.Get("import_type_ids"), ","))
+		}
+		if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+			result = result[min(offset, len(result)):]
+		}
+		if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 0 {
+			result = result[:min(limit, len(result))]
+		}
+		err := json.NewEncoder(writer).Encode(result)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
@@ -46,11 +66,13 @@ func NewImportDeploy() *ImportDeploy {
 	})
 
 	router.PUT("/instances", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-		err := json.NewDecoder(request.Body).Decode(&deploy.instances)
+		instances := []model.Import{}
+		err := json.NewDecoder(request.Body).Decode(&instances)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		deploy.SetInstances(instances)
 	})
 
 	deploy.ts = &httptest.Server{
@@ -62,6 +84,38 @@ func NewImportDeploy() *ImportDeploy {
 	return deploy
 }
 
+func (this *ImportDeploy) SetInstances(instances []model.Import) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.instances = instances
+}
+
+func (this *ImportDeploy) ListInstances(ctx context.Context, token string, importTypeIds []string) (result []model.Import, err error, code int) {
+	return this.list(importTypeIds), nil, http.StatusOK
+}
+
+// list returns the instances of importTypeIds or, if importTypeIds is nil, all instances
+func (this *ImportDeploy) list(importTypeIds []string) (result []model.Import) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = []model.Import{}
+	for _, instance := range this.instances {
+		for _, id := range importTypeIds {
+			if id == instance.ImportTypeId {
+				result = append(result, instance)
+				break
+			}
+		}
+		if importTypeIds == nil {
+			result = append(result, instance)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b model.Import) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
 func (this *ImportDeploy) Stop() {
 	this.ts.Close()
 }
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+
+	importrepo "github.com/SENERGY-Platform/import-repository/lib/client"
+	importmodel "github.com/SENERGY-Platform/import-repository/lib/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// ImportRepository is an in-memory import-repository, to run tests without docker.
+// ListImportTypes returns import types, where every criterion is matched by an output variable.
+type ImportRepository struct {
+	mux         sync.RWMutex
+	importTypes map[string]importmodel.ImportType
+}
+
+func NewImportRepository() *ImportRepository {
+	return &ImportRepository{importTypes: map[string]importmodel.ImportType{}}
+}
+
+func (this *ImportRepository) SetImportType(importType importmodel.ImportType) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.importTypes[importType.Id] = importType
+}
+
+func (this *ImportRepository) DeleteImportType(id string) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	delete(this.importTypes, id)
+}
+
+func (this *ImportRepository) ListImportTypes(token jwt.Token, options importrepo.ImportTypeListOptions) (result []importmodel.ImportType, total int64, err error, code int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result = []importmodel.ImportType{}
+	for _, importType := range this.importTypes {
+		if importTypeMatchesCriteria(importType, options.Criteria) {
+			result = append(result, importType)
+		}
+	}
+	sortByName(result, options.SortBy, func(importType importmodel.ImportType) (string, string) {
+		return importType.Name, importType.Id
+	})
+	total = int64(len(result))
+	return paginate(result, options.Limit, options.Offset), total, nil, http.StatusOK
+}
+
+func (this *ImportRepository) ReadImportType(id string, token jwt.Token) (result importmodel.ImportType, err error, code int) {
+	this.mux.RLock()
+	defer this.mux.RUnlock()
+	result, ok := this.importTypes[id]
+	if !ok {
+		return result, errNotFound, http.StatusNotFound
+	}
+	return result, nil, http.StatusOK
+}
+
+func importTypeMatchesCriteria(importType importmodel.ImportType, criteria []importrepo.ImportTypeFilterCriteria) bool {
+	for _, c := range criteria {
+		if !importVariableMatches(importType.Output, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func importVariableMatches(variable importmodel.ContentVariable, criteria importrepo.ImportTypeFilterCriteria) bool {
+	if variable.FunctionId == criteria.FunctionId && (len(criteria.AspectIds) == 0 || slices.Contains(criteria.AspectIds, variable.AspectId)) {
+		return true
+	}
+	for _, sub := range variable.SubContentVariables {
+		if importVariableMatches(sub, criteria) {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/legacy"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
 	"reflect"
@@ -261,7 +262,7 @@ func testGroupHelper(repo *controller.Controller, deviceIds []string, expectedRe
 	return func(t *testing.T) {
 		dtCache := &map[string]devicemodel.DeviceType{}
 		dCache := &map[string]devicemodel.Device{}
-		result, err, code := repo.GetDeviceGroupCriteria(helper.AdminJwt, dtCache, dCache, deviceIds)
+		result, err, code := repo.GetDeviceGroupCriteria(context.Background(), helper.AdminJwt, dtCache, dCache, deviceIds, "")
 		if err != nil {
 			t.Error(err, code)
 			return
@@ -326,5 +327,5 @@ func grouphelpertestenv(ctx context.Context, wg *sync.WaitGroup, deviceTypes []d
 		KafkaConsumerGroup:              "device_selection",
 		KafkaTopicsForCacheInvalidation: []string{"device-types", "aspects", "functions"},
 	}
-	return controller.New(ctx, c)
+	return environment.NewController(ctx, c)
 }
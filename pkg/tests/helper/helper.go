@@ -23,10 +23,9 @@ import (
 	"errors"
 	"github.com/SENERGY-Platform/device-selection/pkg/api"
 	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
-	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
-	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/docker"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
 	"io"
 	"log"
 	"net/http"
@@ -117,7 +116,7 @@ func normalizeGroupHelperResult(result model.DeviceGroupHelperResult) model.Devi
 }
 
 func EnvWithDevices(ctx context.Context, wg *sync.WaitGroup, deviceTypes []devicemodel.DeviceType, deviceInstances []devicemodel.Device) (kafkaUrl string, managerurl string, repourl string, permv2Url string, err error) {
-	kafkaUrl, managerurl, repourl, permv2Url, err = docker.DeviceManagerWithDependenciesAndKafka(ctx, wg)
+	kafkaUrl, managerurl, repourl, permv2Url, err = environment.DeviceManagerWithDependencies(ctx, wg)
 	if err != nil {
 		return kafkaUrl, managerurl, repourl, permv2Url, err
 	}
@@ -136,14 +135,14 @@ func EnvWithDevices(ctx context.Context, wg *sync.WaitGroup, deviceTypes []devic
 		}
 	}
 
-	time.Sleep(2 * time.Second)
+	WaitForAsyncUpdates()
 
 	return
 }
 
 func EnvWithMetadata(ctx context.Context, wg *sync.WaitGroup, deviceTypes []devicemodel.DeviceType, deviceInstances []devicemodel.Device, aspects []devicemodel.Aspect, functions []devicemodel.Function) (managerurl string, repourl string, permv2Url, selectionurl string, err error) {
 	var kafkaUrl string
-	kafkaUrl, managerurl, repourl, permv2Url, err = docker.DeviceManagerWithDependenciesAndKafka(ctx, wg)
+	kafkaUrl, managerurl, repourl, permv2Url, err = environment.DeviceManagerWithDependencies(ctx, wg)
 	if err != nil {
 		return managerurl, repourl, permv2Url, selectionurl, err
 	}
@@ -176,7 +175,7 @@ func EnvWithMetadata(ctx context.Context, wg *sync.WaitGroup, deviceTypes []devi
 		}
 	}
 
-	time.Sleep(2 * time.Second)
+	WaitForAsyncUpdates()
 
 	c := &configuration.ConfigStruct{
 		DeviceRepoUrl:                   repourl,
@@ -186,7 +185,7 @@ func EnvWithMetadata(ctx context.Context, wg *sync.WaitGroup, deviceTypes []devi
 		KafkaTopicsForCacheInvalidation: []string{"device-types", "aspects", "functions"},
 	}
 
-	ctrl, err := controller.New(ctx, c)
+	ctrl, err := environment.NewController(ctx, c)
 	if err != nil {
 		return managerurl, repourl, permv2Url, selectionurl, err
 	}
@@ -219,7 +218,7 @@ func EnvWithApi(ctx context.Context, wg *sync.WaitGroup, deviceTypes []devicemod
 		KafkaTopicsForCacheInvalidation: []string{"device-types", "aspects", "functions"},
 	}
 
-	ctrl, err := controller.New(ctx, c)
+	ctrl, err := environment.NewController(ctx, c)
 	if err != nil {
 		return managerurl, repourl, permv2Url, selectionurl, err
 	}
@@ -328,6 +327,19 @@ func SetDevice(devicemanagerUrl string, d devicemodel.Device) error {
 
 var SleepAfterEdit = 2 * time.Second
 
+func init() {
+	if environment.IsHermetic() {
+		SleepAfterEdit = 0
+	}
+}
+
+// WaitForAsyncUpdates gives the device-repository time to consume kafka updates; hermetic environments are updated synchronously
+func WaitForAsyncUpdates() {
+	if !environment.IsHermetic() {
+		time.Sleep(2 * time.Second)
+	}
+}
+
 func Jwtpost(token string, url string, msg interface{}) (resp *http.Response, err error) {
 	body := new(bytes.Buffer)
 	err = json.NewEncoder(body).Encode(msg)
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableAspectMatch(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	getTemperature := devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"
+	fixtures.DeviceTypes = append(fixtures.DeviceTypes, models.DeviceType{
+		Id:            "airsensor",
+		Name:          "airsensor",
+		DeviceClassId: "thermometer",
+		Services: []models.Service{{
+			Id:          "getAirTemperature",
+			Name:        "getAirTemperature",
+			Interaction: models.REQUEST,
+			Outputs: []models.Content{{ContentVariable: models.ContentVariable{
+				Name:             "temperature",
+				FunctionId:       getTemperature,
+				AspectId:         "air",
+				CharacteristicId: "celsius",
+				Type:             models.Float,
+			}}},
+		}},
+	})
+	fixtures.Devices = append(fixtures.Devices, models.Device{Id: "a1", LocalId: "a1", Name: "a1", DeviceTypeId: "airsensor"})
+	fixtures.DeviceGroups = append(fixtures.DeviceGroups, models.DeviceGroup{Id: "g2", Name: "g2", DeviceIds: []string{"a1"}})
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	all := []string{"device:a1", "device:t1", "group:g1", "group:g2", "import:i1"}
+	inside := []string{"device:t1", "group:g1", "import:i1"}
+	outside := []string{"device:a1", "group:g2"}
+	cases := []struct {
+		aspectId    string
+		aspectMatch devicemodel.AspectMatch
+		expected    []string
+	}{
+		{aspectId: "inside_air", aspectMatch: "", expected: inside},
+		{aspectId: "inside_air", aspectMatch: devicemodel.AspectMatchExact, expected: inside},
+		{aspectId: "inside_air", aspectMatch: devicemodel.AspectMatchDescendants, expected: inside},
+		{aspectId: "inside_air", aspectMatch: devicemodel.AspectMatchAncestors, expected: all},
+		{aspectId: "inside_air", aspectMatch: devicemodel.AspectMatchBoth, expected: all},
+		{aspectId: "air", aspectMatch: devicemodel.AspectMatchExact, expected: outside},
+		{aspectId: "air", aspectMatch: devicemodel.AspectMatchDescendants, expected: all},
+		{aspectId: "air", aspectMatch: devicemodel.AspectMatchAncestors, expected: outside},
+		{aspectId: "air", aspectMatch: devicemodel.AspectMatchBoth, expected: all},
+	}
+	for _, c := range cases {
+		t.Run(c.aspectId+"_"+string(c.aspectMatch), func(t *testing.T) {
+			result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+				FilterCriteria: model.FilterCriteriaAndSet{{
+					FunctionId:  getTemperature,
+					AspectId:    c.aspectId,
+					AspectMatch: c.aspectMatch,
+				}},
+				IncludeDevices: true,
+				IncludeGroups:  true,
+				IncludeImports: true,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids := []string{}
+			for _, selectable := range result {
+				switch {
+				case selectable.Device != nil:
+					ids = append(ids, "device:"+selectable.Device.Id)
+				case selectable.DeviceGroup != nil:
+					ids = append(ids, "group:"+selectable.DeviceGroup.Id)
+				case selectable.Import != nil:
+					ids = append(ids, "import:"+selectable.Import.Id)
+				}
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, c.expected) {
+				t.Errorf("\na=%v\ne=%v", ids, c.expected)
+			}
+		})
+	}
+
+	groupAspects := func(aspectMatch devicemodel.AspectMatch) (result []string) {
+		helperResult, err, _ := ctrl.DeviceGroupHelper(ctx, helper.AdminJwt, []string{"t1"}, model.DeviceGroupHelperPagination{Limit: 10}, false, nil, aspectMatch)
+		if err != nil {
+			t.Error(err)
+		}
+		result = []string{}
+		for _, criterion := range helperResult.Criteria {
+			if criterion.FunctionId == getTemperature {
+				result = append(result, criterion.AspectId)
+			}
+		}
+		slices.Sort(result)
+		return result
+	}
+	if aspects := groupAspects(""); !slices.Equal(aspects, []string{"air", "inside_air"}) {
+		t.Error(aspects)
+	}
+	if aspects := groupAspects(devicemodel.AspectMatchExact); !slices.Equal(aspects, []string{"inside_air"}) {
+		t.Error(aspects)
+	}
+
+	//options that maintain usability are found with the same aspect_match; exact runs before ancestors to check that cached device-types are not shared
+	usabilityCases := []struct {
+		member      string
+		aspectMatch devicemodel.AspectMatch
+		expected    []string
+	}{
+		{member: "t1", aspectMatch: "", expected: []string{"a1"}},
+		{member: "t1", aspectMatch: devicemodel.AspectMatchExact, expected: []string{}},
+		{member: "t1", aspectMatch: devicemodel.AspectMatchAncestors, expected: []string{"a1"}},
+		{member: "t1", aspectMatch: devicemodel.AspectMatchDescendants, expected: []string{"a1"}},
+		{member: "t1", aspectMatch: devicemodel.AspectMatchBoth, expected: []string{"a1"}},
+		{member: "a1", aspectMatch: "", expected: []string{"t1"}},
+		{member: "a1", aspectMatch: devicemodel.AspectMatchDescendants, expected: []string{"t1"}},
+		{member: "a1", aspectMatch: devicemodel.AspectMatchExact, expected: []string{}},
+		{member: "a1", aspectMatch: devicemodel.AspectMatchAncestors, expected: []string{"t1"}},
+		{member: "a1", aspectMatch: devicemodel.AspectMatchBoth, expected: []string{"t1"}},
+	}
+	for _, c := range usabilityCases {
+		t.Run("maintain_usability_"+c.member+"_"+string(c.aspectMatch), func(t *testing.T) {
+			helperResult, err, _ := ctrl.DeviceGroupHelper(ctx, helper.AdminJwt, []string{c.member}, model.DeviceGroupHelperPagination{Limit: 10}, true, nil, c.aspectMatch)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids := []string{}
+			for _, option := range helperResult.Options {
+				ids = append(ids, option.Device.Id)
+				if !option.MaintainsGroupUsability {
+					t.Errorf("%v does not maintain usability: %#v", option.Device.Id, option.RemovesCriteria)
+				}
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, c.expected) {
+				t.Errorf("\na=%v\ne=%v", ids, c.expected)
+			}
+		})
+	}
+
+	_, err, code := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+		FilterCriteria: model.FilterCriteriaAndSet{{FunctionId: getTemperature, AspectId: "air", AspectMatch: "siblings"}},
+		IncludeDevices: true,
+	})
+	if err == nil || code != http.StatusBadRequest {
+		t.Error("expected invalid aspect_match", err, code)
+	}
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableCharacteristicMatch(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	matches := func(target string, filter bool) map[string]model.CharacteristicMatch {
+		result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+			FilterCriteria: model.FilterCriteriaAndSet{{
+				FunctionId:             devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature",
+				AspectId:               "air",
+				TargetCharacteristicId: target,
+			}},
+			IncludeDevices:                    true,
+			IncludeImports:                    true,
+			FilterIncompatibleCharacteristics: filter,
+		})
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		byId := map[string]model.CharacteristicMatch{}
+		for _, selectable := range result {
+			for _, options := range selectable.ServicePathOptions {
+				for _, option := range options {
+					if option.CharacteristicMatch == nil {
+						t.Errorf("missing characteristic match %#v", option)
+						continue
+					}
+					switch {
+					case selectable.Device != nil:
+						byId[selectable.Device.Id] = *option.CharacteristicMatch
+					case selectable.Import != nil:
+						byId[selectable.Import.Id] = *option.CharacteristicMatch
+					}
+				}
+			}
+		}
+		return byId
+	}
+
+	result := matches("fahrenheit", false)
+	expectedDevice := model.CharacteristicMatch{TargetCharacteristicId: "fahrenheit", ConceptId: "temperature", BaseCharacteristicId: "celsius", DisplayUnit: "°C", Usability: model.CharacteristicConversion}
+	if result["t1"] != expectedDevice {
+		t.Errorf("\na=%#v\ne=%#v", result["t1"], expectedDevice)
+	}
+	if result["i1"].Usability != model.CharacteristicIncompatible || result["i1"].DisplayUnit != "K" {
+		t.Errorf("%#v", result["i1"])
+	}
+
+	result = matches("celsius", true)
+	if result["t1"].Usability != model.CharacteristicDirect {
+		t.Errorf("%#v", result["t1"])
+	}
+	if _, ok := result["i1"]; ok || len(result) != 1 {
+		t.Errorf("incompatible import should be filtered %#v", result)
+	}
+}
+
+func TestSelectableCharacteristicFilterPerCriterion(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	getTemperature := devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"
+	setTemperature := devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature"
+	//the temperature concept has no kelvin characteristic, so setTemperature is incompatible with celsius
+	fixtures.DeviceTypes = append(fixtures.DeviceTypes, models.DeviceType{
+		Id:            "kelvinheater",
+		Name:          "kelvinheater",
+		DeviceClassId: "heater",
+		Services: []models.Service{
+			{
+				Id:          "getTemperature",
+				Name:        "getTemperature",
+				Interaction: models.REQUEST,
+				Outputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       getTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "celsius",
+					Type:             models.Float,
+				}}},
+			},
+			{
+				Id:          "setTemperature",
+				Name:        "setTemperature",
+				Interaction: models.REQUEST,
+				Inputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       setTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "kelvin",
+					Type:             models.Float,
+				}}},
+			},
+		},
+	})
+	fixtures.Devices = append(fixtures.Devices, models.Device{Id: "kh1", LocalId: "kh1", Name: "kh1", DeviceTypeId: "kelvinheater"})
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	query := func(filter bool, criteria ...devicemodel.FilterCriteria) (ids []string) {
+		result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+			FilterCriteria:                    criteria,
+			IncludeDevices:                    true,
+			FilterIncompatibleCharacteristics: filter,
+		})
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		ids = []string{}
+		for _, selectable := range result {
+			ids = append(ids, selectable.Device.Id)
+		}
+		return ids
+	}
+	compatible := devicemodel.FilterCriteria{FunctionId: getTemperature, TargetCharacteristicId: "celsius"}
+	incompatible := devicemodel.FilterCriteria{FunctionId: setTemperature, TargetCharacteristicId: "celsius"}
+
+	if ids := query(true, compatible); !slices.Contains(ids, "kh1") {
+		t.Error(ids)
+	}
+	if ids := query(false, compatible, incompatible); !slices.Equal(ids, []string{"kh1"}) {
+		t.Error(ids)
+	}
+	//the compatible criterion keeps an option, but the incompatible criterion has none left
+	if ids := query(true, compatible, incompatible); len(ids) != 0 {
+		t.Error(ids)
+	}
+
+	deviceTypes, err, _ := ctrl.QueryDeviceTypes(ctx, helper.AdminJwt, model.QueryDeviceTypesOptions{
+		FilterCriteria:                    model.FilterCriteriaAndSet{compatible, incompatible},
+		FilterIncompatibleCharacteristics: true,
+	})
+	if err != nil || len(deviceTypes) != 0 {
+		t.Error(err, deviceTypes)
+	}
+}
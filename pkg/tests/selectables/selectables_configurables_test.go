@@ -0,0 +1,183 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableConfigurables(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	query := func(configurables *devicemodel.ConfigurableCriteria) (result []model.Selectable, ids []string) {
+		result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+			FilterCriteria: model.FilterCriteriaAndSet{{
+				FunctionId:    devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature",
+				AspectId:      "air",
+				Configurables: configurables,
+			}},
+			IncludeDevices: true,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		ids = []string{}
+		for _, selectable := range result {
+			ids = append(ids, selectable.Device.Id)
+		}
+		slices.Sort(ids)
+		return result, ids
+	}
+
+	cases := []struct {
+		name          string
+		configurables *devicemodel.ConfigurableCriteria
+		expected      []string
+	}{
+		{name: "unconstrained", configurables: nil, expected: []string{"h1", "th1"}},
+		{name: "none", configurables: &devicemodel.ConfigurableCriteria{None: true}, expected: []string{"h1"}},
+		{name: "require path", configurables: &devicemodel.ConfigurableCriteria{Require: []devicemodel.ConfigurableFilter{{Path: "value.duration"}}}, expected: []string{"th1"}},
+		{name: "exclude characteristic", configurables: &devicemodel.ConfigurableCriteria{Exclude: []devicemodel.ConfigurableFilter{{CharacteristicId: "seconds"}}}, expected: []string{"h1"}},
+		{name: "accepts", configurables: &devicemodel.ConfigurableCriteria{Require: []devicemodel.ConfigurableFilter{{Path: "value.duration", Accepts: 120}}}, expected: []string{"th1"}},
+		{name: "accepts out of range", configurables: &devicemodel.ConfigurableCriteria{Require: []devicemodel.ConfigurableFilter{{Path: "value.duration", Accepts: 7200}}}, expected: []string{}},
+		{name: "accepts wrong type", configurables: &devicemodel.ConfigurableCriteria{Require: []devicemodel.ConfigurableFilter{{Path: "value.duration", Accepts: 1.5}}}, expected: []string{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, ids := query(c.configurables)
+			if !slices.Equal(ids, c.expected) {
+				t.Errorf("\na=%v\ne=%v", ids, c.expected)
+			}
+		})
+	}
+
+	result, _ := query(&devicemodel.ConfigurableCriteria{})
+	found := false
+	for _, selectable := range result {
+		if selectable.Device.Id != "th1" {
+			continue
+		}
+		found = true
+		resolved := selectable.ServicePathOptions["setTemperature"][0].ResolvedConfigurables
+		if len(resolved) != 1 || resolved[0].Path != "value.duration" || model.NormalizeJson(resolved[0].Value) != 60.0 || resolved[0].Characteristic == nil || resolved[0].Characteristic.DisplayUnit != "s" {
+			t.Errorf("%#v", resolved)
+		}
+	}
+	if !found {
+		t.Error("missing th1")
+	}
+}
+
+func TestSelectableConfigurablesPerCriterion(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	getTemperature := devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"
+	setTemperature := devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature"
+	fixtures.DeviceTypes = append(fixtures.DeviceTypes, models.DeviceType{
+		Id:            "timedthermostat",
+		Name:          "timedthermostat",
+		DeviceClassId: "thermostat",
+		Services: []models.Service{
+			{
+				Id:          "getTemperature",
+				Name:        "getTemperature",
+				Interaction: models.REQUEST,
+				Outputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       getTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "celsius",
+					Type:             models.Float,
+				}}},
+			},
+			{
+				Id:          "setTemperature",
+				Name:        "setTemperature",
+				Interaction: models.REQUEST,
+				Inputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name: "value",
+					Type: models.Structure,
+					SubContentVariables: []models.ContentVariable{
+						{Name: "temperature", FunctionId: setTemperature, AspectId: "inside_air", CharacteristicId: "celsius", Type: models.Float},
+						{Name: "duration", CharacteristicId: "seconds", Type: models.Integer, Value: 60},
+					},
+				}}},
+			},
+		},
+	})
+	fixtures.Devices = append(fixtures.Devices, models.Device{Id: "tt1", LocalId: "tt1", Name: "tt1", DeviceTypeId: "timedthermostat"})
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	criteria := model.FilterCriteriaAndSet{
+		{FunctionId: getTemperature},
+		{FunctionId: setTemperature, Configurables: &devicemodel.ConfigurableCriteria{None: true}},
+	}
+	result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{FilterCriteria: criteria, IncludeDevices: true})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//getTemperature keeps its option, but the only setTemperature option has a configurable
+	if len(result) != 0 {
+		t.Errorf("%#v", result)
+	}
+	deviceTypes, err, _ := ctrl.QueryDeviceTypes(ctx, helper.AdminJwt, model.QueryDeviceTypesOptions{FilterCriteria: criteria})
+	if err != nil || len(deviceTypes) != 0 {
+		t.Error(err, deviceTypes)
+	}
+
+	criteria[1].Configurables = &devicemodel.ConfigurableCriteria{Require: []devicemodel.ConfigurableFilter{{Path: "value.duration"}}}
+	result, err, _ = ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{FilterCriteria: criteria, IncludeDevices: true})
+	if err != nil || len(result) != 1 || result[0].Device.Id != "tt1" {
+		t.Errorf("%v %#v", err, result)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableDeviceTypeQuery(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//more devices than one page of the device-repository (limit 1000)
+	for i := 2; i <= 1200; i++ {
+		id := "h" + strconv.Itoa(i)
+		fixtures.Devices = append(fixtures.Devices, models.Device{Id: id, LocalId: id, Name: id, DeviceTypeId: "heater"})
+	}
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	setTemperature := devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature"
+	cases := []struct {
+		name     string
+		criteria model.FilterCriteriaAndSet
+		expected map[string]int
+	}{
+		{name: "function", criteria: model.FilterCriteriaAndSet{{FunctionId: setTemperature, AspectId: "air"}}, expected: map[string]int{"heater": 1200, "thermostat": 1}},
+		{name: "device class", criteria: model.FilterCriteriaAndSet{{FunctionId: setTemperature, DeviceClassId: "thermostat"}}, expected: map[string]int{"thermostat": 1}},
+		{name: "exclusion", criteria: model.FilterCriteriaAndSet{{FunctionId: setTemperature, AspectId: "air"}, {DeviceClassId: "heater", Exclude: true}}, expected: map[string]int{"thermostat": 1}},
+		{name: "exact aspect", criteria: model.FilterCriteriaAndSet{{FunctionId: setTemperature, AspectId: "air", AspectMatch: devicemodel.AspectMatchExact}}, expected: map[string]int{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err, _ := ctrl.QueryDeviceTypes(ctx, helper.AdminJwt, model.QueryDeviceTypesOptions{FilterCriteria: c.criteria})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			counts := map[string]int{}
+			for _, dt := range result {
+				counts[dt.DeviceTypeId] = dt.DeviceCount
+				if dt.Name != dt.DeviceTypeId || dt.DeviceClassId != dt.DeviceTypeId {
+					t.Errorf("unexpected name or device class of %v: %v %v", dt.DeviceTypeId, dt.Name, dt.DeviceClassId)
+				}
+				if len(dt.Services) != 1 || dt.Services[0].Id != "setTemperature" {
+					t.Errorf("unexpected services of %v: %#v", dt.DeviceTypeId, dt.Services)
+				}
+				options := dt.ServicePathOptions["setTemperature"]
+				if len(options) != 1 || options[0].FunctionId != setTemperature || options[0].AspectNode.Id != "inside_air" {
+					t.Errorf("unexpected path options of %v: %#v", dt.DeviceTypeId, dt.ServicePathOptions)
+				}
+			}
+			if !maps.Equal(counts, c.expected) {
+				t.Errorf("\na=%v\ne=%v", counts, c.expected)
+			}
+		})
+	}
+
+	invalid := []model.FilterCriteriaAndSet{
+		{},
+		{{FunctionId: setTemperature, Exclude: true}},
+		{{FunctionId: setTemperature, Optional: true}},
+	}
+	for _, criteria := range invalid {
+		_, err, code := ctrl.QueryDeviceTypes(ctx, helper.AdminJwt, model.QueryDeviceTypesOptions{FilterCriteria: criteria})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error("expected invalid device-type query", criteria, err, code)
+		}
+	}
+}
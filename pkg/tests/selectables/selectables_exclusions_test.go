@@ -0,0 +1,180 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableExclusions(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	getTemperature := devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"
+	setTemperature := devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature"
+	fixtures.DeviceTypes = append(fixtures.DeviceTypes, models.DeviceType{
+		Id:            "smartheater",
+		Name:          "smartheater",
+		DeviceClassId: "heater",
+		Services: []models.Service{
+			{
+				Id:          "setTemperature",
+				Name:        "setTemperature",
+				Interaction: models.REQUEST,
+				Inputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       setTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "celsius",
+					Type:             models.Float,
+				}}},
+			},
+			{
+				Id:          "temperatureEvent",
+				Name:        "temperatureEvent",
+				Interaction: models.EVENT,
+				Outputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       getTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "celsius",
+					Type:             models.Float,
+				}}},
+			},
+		},
+	})
+	fixtures.DeviceTypes = append(fixtures.DeviceTypes, models.DeviceType{
+		Id:            "multisensor",
+		Name:          "multisensor",
+		DeviceClassId: "sensor",
+		Services: []models.Service{
+			{
+				Id:          "getTemperature",
+				Name:        "getTemperature",
+				Interaction: models.EVENT_AND_REQUEST,
+				Outputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       getTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "celsius",
+					Type:             models.Float,
+				}}},
+			},
+		},
+	})
+	fixtures.Devices = append(fixtures.Devices,
+		models.Device{Id: "sh1", LocalId: "sh1", Name: "sh1", DeviceTypeId: "smartheater"},
+		models.Device{Id: "ms1", LocalId: "ms1", Name: "ms1", DeviceTypeId: "multisensor"},
+	)
+	fixtures.DeviceGroups = append(fixtures.DeviceGroups, models.DeviceGroup{Id: "g2", Name: "g2", DeviceIds: []string{"ms1"}})
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cases := []struct {
+		name      string
+		criterion devicemodel.FilterCriteria
+		exclusion devicemodel.FilterCriteria
+		expected  []string
+	}{
+		{name: "function", criterion: devicemodel.FilterCriteria{FunctionId: setTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{FunctionId: getTemperature}, expected: []string{"device:h1", "device:th1"}},
+		{name: "function and aspect", criterion: devicemodel.FilterCriteria{FunctionId: setTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, expected: []string{"device:h1", "device:th1"}},
+		{name: "exact aspect", criterion: devicemodel.FilterCriteria{FunctionId: setTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air", AspectMatch: devicemodel.AspectMatchExact}, expected: []string{"device:h1", "device:sh1", "device:th1"}},
+		{name: "device class", criterion: devicemodel.FilterCriteria{FunctionId: setTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{DeviceClassId: "heater"}, expected: []string{"device:th1"}},
+		{name: "interaction", criterion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{Interaction: string(devicemodel.EVENT)}, expected: []string{"device:ms1", "device:t1", "group:g1"}},
+		{name: "group and import aspect", criterion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "inside_air", AspectMatch: devicemodel.AspectMatchExact}, expected: []string{}},
+		{name: "group and import exact parent aspect", criterion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air", AspectMatch: devicemodel.AspectMatchExact}, expected: []string{"device:ms1", "device:sh1", "device:t1", "group:g1", "group:g2", "import:i1"}},
+		//interactions match exactly: request-only exclusions keep event_and_request services; group criteria list event and request separately, so g2 (ms1) has to match both
+		{name: "event and request service", criterion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{FunctionId: getTemperature, Interaction: string(devicemodel.REQUEST)}, expected: []string{"device:ms1", "device:sh1", "import:i1"}},
+		{name: "event and request exclusion", criterion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{Interaction: string(devicemodel.EVENT_AND_REQUEST)}, expected: []string{"device:sh1", "device:t1", "group:g1", "import:i1"}},
+		{name: "event and request exclusion with aspect", criterion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, exclusion: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "inside_air", Interaction: string(devicemodel.EVENT_AND_REQUEST)}, expected: []string{"device:sh1", "device:t1", "group:g1", "import:i1"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exclusion := c.exclusion
+			exclusion.Exclude = true
+			criteria := model.FilterCriteriaAndSet{c.criterion, exclusion}
+			collect := func(result []model.Selectable) (ids []string) {
+				ids = []string{}
+				for _, selectable := range result {
+					switch {
+					case selectable.Device != nil:
+						ids = append(ids, "device:"+selectable.Device.Id)
+					case selectable.DeviceGroup != nil:
+						ids = append(ids, "group:"+selectable.DeviceGroup.Id)
+					case selectable.Import != nil:
+						ids = append(ids, "import:"+selectable.Import.Id)
+					}
+				}
+				slices.Sort(ids)
+				return ids
+			}
+			v2, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+				FilterCriteria: criteria,
+				IncludeDevices: true,
+				IncludeGroups:  true,
+				IncludeImports: true,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ids := collect(v2); !slices.Equal(ids, c.expected) {
+				t.Errorf("v2\na=%v\ne=%v", ids, c.expected)
+			}
+			v1, err, _ := ctrl.GetFilteredDevices(ctx, helper.AdminJwt, criteria, nil, "", true, true, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ids := collect(v1); !slices.Equal(ids, c.expected) {
+				t.Errorf("v1\na=%v\ne=%v", ids, c.expected)
+			}
+		})
+	}
+
+	invalid := []model.FilterCriteriaAndSet{
+		{{FunctionId: getTemperature, Exclude: true}},
+		{{FunctionId: getTemperature}, {Exclude: true}},
+		{{FunctionId: getTemperature}, {FunctionId: setTemperature, Exclude: true, Optional: true}},
+	}
+	for _, criteria := range invalid {
+		_, err, code := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{FilterCriteria: criteria, IncludeDevices: true})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error("expected invalid exclusion", criteria, err, code)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableGroupedFormat(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fixtures.Devices = append(fixtures.Devices,
+		models.Device{Id: "h2", LocalId: "h2", Name: "h2", DeviceTypeId: "heater"},
+		models.Device{Id: "h3", LocalId: "h3", Name: "h3", DeviceTypeId: "heater"},
+	)
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+		FilterCriteria: model.FilterCriteriaAndSet{{FunctionId: devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature", AspectId: "air"}},
+		IncludeDevices: true,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(result) != 4 {
+		t.Errorf("%#v", result)
+		return
+	}
+
+	grouped := model.GroupSelectables(result)
+	if len(grouped.DeviceTypes) != 2 || len(grouped.DeviceTypes["heater"].Services) != 1 || len(grouped.DeviceTypes["thermostat"].ServicePathOptions) != 1 {
+		t.Errorf("%#v", grouped.DeviceTypes)
+	}
+	for i, selectable := range grouped.Selectables {
+		if selectable.Device == nil || selectable.Device.Id != result[i].Device.Id || selectable.Services != nil || selectable.ServicePathOptions != nil || !selectable.DeviceTypeRef {
+			t.Errorf("%#v", selectable)
+		}
+	}
+
+	//compare the json of the expanded form, like a client would receive it
+	groupedJson, err := json.Marshal(grouped)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	decoded := model.GroupedSelectables{}
+	err = json.Unmarshal(groupedJson, &decoded)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected, _ := json.Marshal(result)
+	actual, _ := json.Marshal(decoded.Expand())
+	if string(actual) != string(expected) {
+		t.Errorf("\na=%s\ne=%s", actual, expected)
+	}
+	if len(groupedJson) >= len(expected) {
+		t.Error("grouped form is not smaller", len(groupedJson), len(expected))
+	}
+
+	//devices without services do not reference their device-type and stay without services
+	withoutServices := append(slices.Clone(result), model.Selectable{Device: result[0].Device})
+	groupedJson, err = json.Marshal(model.GroupSelectables(withoutServices))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	decoded = model.GroupedSelectables{}
+	err = json.Unmarshal(groupedJson, &decoded)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected, _ = json.Marshal(withoutServices)
+	actual, _ = json.Marshal(decoded.Expand())
+	if string(actual) != string(expected) {
+		t.Errorf("\na=%s\ne=%s", actual, expected)
+	}
+}
@@ -24,7 +24,7 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
-	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/docker"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/legacy"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
 	"io"
@@ -43,7 +43,7 @@ func TestSelectableGroups(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	kafkaUrl, deviceManagerUrl, deviceRepoUrl, _, err := docker.DeviceManagerWithDependenciesAndKafka(ctx, wg)
+	kafkaUrl, deviceManagerUrl, deviceRepoUrl, _, err := environment.DeviceManagerWithDependencies(ctx, wg)
 	if err != nil {
 		t.Error(err)
 		return
@@ -57,7 +57,7 @@ func TestSelectableGroups(t *testing.T) {
 		KafkaTopicsForCacheInvalidation: []string{"device-types", "aspects", "functions"},
 	}
 
-	ctrl, err := controller.New(ctx, c)
+	ctrl, err := environment.NewController(ctx, c)
 	if err != nil {
 		t.Error(err)
 		return
@@ -792,7 +792,7 @@ const token = helper.AdminJwt
 
 func testCheckSelectionWithoutOptions(ctrl *controller.Controller, criteria model.FilterCriteriaAndSet, interaction devicemodel.Interaction, includeGroups bool, expectedResult []model.Selectable) func(t *testing.T) {
 	return func(t *testing.T) {
-		result, err, _ := ctrl.GetFilteredDevices(token, criteria, nil, interaction, includeGroups, false, nil)
+		result, err, _ := ctrl.GetFilteredDevices(context.Background(), token, criteria, nil, interaction, includeGroups, false, nil)
 		if err != nil {
 			t.Error(err)
 			return
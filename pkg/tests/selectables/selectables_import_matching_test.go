@@ -0,0 +1,193 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+)
+
+func TestSelectableImportMatching(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	getTemperature := devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"
+	cases := []struct {
+		name     string
+		criteria devicemodel.FilterCriteria
+		and      []devicemodel.FilterCriteria
+		expected []string
+		devices  []string
+	}{
+		{name: "function", criteria: devicemodel.FilterCriteria{FunctionId: getTemperature}, expected: []string{"i1"}},
+		{name: "function and aspect", criteria: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "inside_air"}, expected: []string{"i1"}},
+		{name: "function and parent aspect", criteria: devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air"}, expected: []string{"i1"}},
+		{name: "device class skips imports", criteria: devicemodel.FilterCriteria{FunctionId: getTemperature, DeviceClassId: "thermometer"}, expected: []string{}, devices: []string{"t1"}},
+		{name: "value type", criteria: devicemodel.FilterCriteria{FunctionId: getTemperature, ValueTypes: []devicemodel.Type{devicemodel.String}}, expected: []string{}},
+		{name: "every criterion", criteria: devicemodel.FilterCriteria{FunctionId: getTemperature}, and: []devicemodel.FilterCriteria{{FunctionId: getTemperature, ValueTypes: []devicemodel.Type{devicemodel.String}}}, expected: []string{}},
+		{name: "other function", criteria: devicemodel.FilterCriteria{FunctionId: devicemodel.MEASURING_FUNCTION_PREFIX + "getHumidity"}, expected: []string{}},
+	}
+	importIds := func(selectables []model.Selectable) []string {
+		result := []string{}
+		for _, selectable := range selectables {
+			if selectable.Import != nil {
+				result = append(result, selectable.Import.Id)
+			}
+		}
+		return result
+	}
+	deviceIds := func(selectables []model.Selectable) []string {
+		result := []string{}
+		for _, selectable := range selectables {
+			if selectable.Device != nil {
+				result = append(result, selectable.Device.Id)
+			}
+		}
+		return result
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			criteria := append(model.FilterCriteriaAndSet{c.criteria}, c.and...)
+			v1, err, _ := ctrl.GetFilteredDevices(ctx, helper.AdminJwt, criteria, nil, devicemodel.REQUEST, false, true, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !slices.Equal(importIds(v1), c.expected) {
+				t.Errorf("v1\na=%v\ne=%v", importIds(v1), c.expected)
+			}
+			for _, selectable := range v1 {
+				if selectable.ServicePathOptions != nil {
+					t.Errorf("unexpected v1 path options %#v", selectable.ServicePathOptions)
+				}
+			}
+			v2, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+				FilterCriteria: criteria,
+				IncludeDevices: c.devices != nil,
+				IncludeImports: true,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !slices.Equal(importIds(v2), c.expected) {
+				t.Errorf("v2\na=%v\ne=%v", importIds(v2), c.expected)
+			}
+			if c.devices != nil && !slices.Equal(deviceIds(v2), c.devices) {
+				t.Errorf("v2 devices\na=%v\ne=%v", deviceIds(v2), c.devices)
+			}
+			for _, selectable := range v2 {
+				if selectable.Import == nil {
+					continue
+				}
+				if options := selectable.ServicePathOptions["weather"]; len(options) != 1 || options[0].Path != "value.temperature" {
+					t.Errorf("unexpected path options %#v", selectable.ServicePathOptions)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectableImportFilter(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	restart := true
+	fixtures.Imports = []model.Import{
+		{Id: "leipzig", Name: "leipzig", ImportTypeId: "weather", KafkaTopic: "weather_leipzig", Restart: &restart, Configs: []model.ImportConfig{{Name: "city", Value: "Leipzig"}, {Name: "interval", Value: 60.0}}},
+		{Id: "berlin", Name: "berlin", ImportTypeId: "weather", KafkaTopic: "weather_berlin", Configs: []model.ImportConfig{{Name: "city", Value: "Berlin"}, {Name: "interval", Value: "600"}}},
+	}
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ptr := func(value float64) *float64 { return &value }
+	cases := []struct {
+		name     string
+		filter   model.ImportFilter
+		expected []string
+	}{
+		{name: "equals", filter: model.ImportFilter{Configs: []model.ImportConfigFilter{{Name: "city", Equals: "Leipzig"}}}, expected: []string{"leipzig"}},
+		{name: "in", filter: model.ImportFilter{Configs: []model.ImportConfigFilter{{Name: "city", In: []interface{}{"Leipzig", "Berlin"}}}}, expected: []string{"berlin", "leipzig"}},
+		{name: "range", filter: model.ImportFilter{Configs: []model.ImportConfigFilter{{Name: "interval", Min: ptr(100)}}}, expected: []string{"berlin"}},
+		{name: "equals number", filter: model.ImportFilter{Configs: []model.ImportConfigFilter{{Name: "interval", Equals: 60}}}, expected: []string{"leipzig"}},
+		{name: "unknown config", filter: model.ImportFilter{Configs: []model.ImportConfigFilter{{Name: "foo", Equals: "bar"}}}, expected: []string{}},
+		{name: "restart", filter: model.ImportFilter{Restart: &restart}, expected: []string{"leipzig"}},
+		{name: "kafka topic", filter: model.ImportFilter{KafkaTopic: "weather_berlin"}, expected: []string{"berlin"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+				FilterCriteria: model.FilterCriteriaAndSet{{FunctionId: devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"}},
+				IncludeImports: true,
+				ImportFilter:   &c.filter,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids := []string{}
+			for _, selectable := range result {
+				ids = append(ids, selectable.Import.Id)
+				if len(selectable.MatchedImportConfigs) != len(c.filter.Configs) {
+					t.Errorf("unexpected matched configs %#v", selectable.MatchedImportConfigs)
+				}
+			}
+			if !slices.Equal(ids, c.expected) {
+				t.Errorf("\na=%v\ne=%v", ids, c.expected)
+			}
+		})
+	}
+
+	_, err, code := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+		IncludeImports: true,
+		ImportFilter:   &model.ImportFilter{Configs: []model.ImportConfigFilter{{Name: "city", Equals: "Leipzig", In: []interface{}{"Berlin"}}}},
+	})
+	if err == nil || code != http.StatusBadRequest {
+		t.Error("expected invalid filter", err, code)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/SENERGY-Platform/device-selection/pkg/controller"
 	"github.com/SENERGY-Platform/device-selection/pkg/model"
 	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/docker"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment/legacy"
 	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
@@ -42,7 +43,7 @@ func TestSelectableLocalId(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	kafkaUrl, deviceManagerUrl, deviceRepoUrl, _, err := docker.DeviceManagerWithDependenciesAndKafka(ctx, wg)
+	kafkaUrl, deviceManagerUrl, deviceRepoUrl, _, err := environment.DeviceManagerWithDependencies(ctx, wg)
 	if err != nil {
 		t.Error(err)
 		return
@@ -63,7 +64,7 @@ func TestSelectableLocalId(t *testing.T) {
 		KafkaTopicsForCacheInvalidation: []string{"device-types", "aspects", "functions"},
 	}
 
-	ctrl, err := controller.New(ctx, c)
+	ctrl, err := environment.NewController(ctx, c)
 	if err != nil {
 		t.Error(err)
 		return
@@ -329,7 +330,7 @@ func TestSelectableLocalId(t *testing.T) {
 
 func testCheckSelectionWithLocalIdsWithoutOptions(ctrl *controller.Controller, criteria model.FilterCriteriaAndSet, interaction devicemodel.Interaction, includeGroups bool, localIds []string, expectedResult []model.Selectable) func(t *testing.T) {
 	return func(t *testing.T) {
-		result, err, _ := ctrl.GetFilteredDevices(token, criteria, nil, interaction, includeGroups, false, localIds)
+		result, err, _ := ctrl.GetFilteredDevices(context.Background(), token, criteria, nil, interaction, includeGroups, false, localIds)
 		if err != nil {
 			t.Error(err)
 			return
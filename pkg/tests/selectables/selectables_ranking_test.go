@@ -0,0 +1,200 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableRanking(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	getTemperature := devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"
+	setTemperature := devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature"
+	fixtures.DeviceTypes = append(fixtures.DeviceTypes, models.DeviceType{
+		Id:            "smartheater",
+		Name:          "smartheater",
+		DeviceClassId: "heater",
+		Services: []models.Service{
+			{
+				Id:          "setTemperature",
+				Name:        "setTemperature",
+				Interaction: models.REQUEST,
+				Inputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       setTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "celsius",
+					Type:             models.Float,
+				}}},
+			},
+			{
+				Id:          "temperatureEvent",
+				Name:        "temperatureEvent",
+				Interaction: models.EVENT,
+				Outputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:             "temperature",
+					FunctionId:       getTemperature,
+					AspectId:         "inside_air",
+					CharacteristicId: "celsius",
+					Type:             models.Float,
+				}}},
+			},
+		},
+	})
+	fixtures.Devices = append(fixtures.Devices, models.Device{Id: "sh1", LocalId: "sh1", Name: "sh1", DeviceTypeId: "smartheater"})
+	fixtures.Devices = append(fixtures.Devices, models.Device{Id: "sh2", LocalId: "sh2", Name: "sh2", DeviceTypeId: "smartheater", OwnerId: "other-user"})
+	_, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	query := func(preferredInteraction devicemodel.Interaction, criteria ...devicemodel.FilterCriteria) (result []model.Selectable, ids []string) {
+		result, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+			FilterCriteria:       criteria,
+			IncludeDevices:       true,
+			PreferredInteraction: preferredInteraction,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		ids = []string{}
+		for _, selectable := range result {
+			ids = append(ids, selectable.Device.Id)
+		}
+		return result, ids
+	}
+	mandatory := devicemodel.FilterCriteria{FunctionId: setTemperature, AspectId: "air"}
+	optional := devicemodel.FilterCriteria{FunctionId: getTemperature, AspectId: "air", Optional: true, Weight: 2}
+
+	result, ids := query("", mandatory)
+	if !slices.Equal(ids, []string{"h1", "sh1", "sh2", "th1"}) {
+		t.Error(ids)
+	}
+	for _, selectable := range result {
+		if selectable.Ranking != nil {
+			t.Error("unexpected ranking without optional criteria", selectable.Ranking)
+		}
+	}
+
+	//sh2 belongs to another user, so the token subject only owns sh1, h1 and th1
+	result, ids = query("", mandatory, optional)
+	if !slices.Equal(ids, []string{"sh1", "sh2", "h1", "th1"}) {
+		t.Error(ids)
+	}
+	if len(result) == 4 {
+		ranking := result[0].Ranking
+		if ranking == nil || ranking.Score != 2+model.OwnedDeviceScore || !ranking.Owned || len(ranking.Criteria) != 2 || !ranking.Criteria[0].Matched || !ranking.Criteria[1].Matched || ranking.Criteria[1].Score != 2 || ranking.Criteria[1].Index != 1 {
+			t.Errorf("%#v", ranking)
+		}
+		if _, ok := result[0].ServicePathOptions["temperatureEvent"]; !ok || len(result[0].Services) != 2 {
+			t.Errorf("missing path options of optional criterion %#v", result[0].ServicePathOptions)
+		}
+		ranking = result[1].Ranking
+		if ranking == nil || ranking.Score != 2 || ranking.Owned {
+			t.Errorf("%#v", ranking)
+		}
+		ranking = result[2].Ranking
+		if ranking == nil || ranking.Score != model.OwnedDeviceScore || !ranking.Owned || ranking.Criteria[1].Matched {
+			t.Errorf("%#v", ranking)
+		}
+	}
+
+	result, _ = query(devicemodel.EVENT, mandatory, optional)
+	if len(result) != 4 || !result[0].Ranking.PreferredInteraction || result[0].Ranking.Score != 2+model.PreferredInteractionScore+model.OwnedDeviceScore || result[2].Ranking.PreferredInteraction {
+		t.Errorf("%#v", result)
+	}
+
+	//optional criteria of devices, groups and imports are matched without another query of the selectables
+	result, err, _ = ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+		FilterCriteria: model.FilterCriteriaAndSet{
+			{FunctionId: getTemperature, AspectId: "air"},
+			{FunctionId: getTemperature, AspectId: "inside_air", AspectMatch: devicemodel.AspectMatchExact, Interaction: string(devicemodel.EVENT), Optional: true, Weight: 3},
+			{DeviceClassId: "thermometer", Optional: true},
+		},
+		IncludeDevices: true,
+		IncludeGroups:  true,
+		IncludeImports: true,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ranked := []string{}
+	for _, selectable := range result {
+		switch {
+		case selectable.Device != nil:
+			ranked = append(ranked, selectable.Device.Id+":"+strconv.FormatFloat(selectable.Ranking.Score, 'f', -1, 64))
+		case selectable.DeviceGroup != nil:
+			ranked = append(ranked, selectable.DeviceGroup.Id+":"+strconv.FormatFloat(selectable.Ranking.Score, 'f', -1, 64))
+		case selectable.Import != nil:
+			ranked = append(ranked, selectable.Import.Id+":"+strconv.FormatFloat(selectable.Ranking.Score, 'f', -1, 64))
+		}
+	}
+	//groups have no device class in the criteria of measuring functions
+	if !slices.Equal(ranked, []string{"sh1:3.25", "sh2:3", "i1:3", "t1:1.25", "g1:0"}) {
+		t.Error(ranked)
+	}
+
+	//exclusions only remove selectables and are not listed in the ranking
+	result, _ = query("", mandatory, optional, devicemodel.FilterCriteria{DeviceClassId: "thermostat", Exclude: true})
+	for _, selectable := range result {
+		if len(selectable.Ranking.Criteria) != 2 || slices.ContainsFunc(selectable.Ranking.Criteria, func(match model.CriterionMatch) bool { return match.Criterion.Exclude }) {
+			t.Errorf("%#v", selectable.Ranking)
+		}
+	}
+
+	_, err, code := ctrl.GetFilteredDevicesV2(ctx, "Bearer invalid", model.GetFilteredDevicesV2Options{
+		FilterCriteria: model.FilterCriteriaAndSet{mandatory, optional},
+		IncludeDevices: true,
+	})
+	if err == nil || code != http.StatusUnauthorized {
+		t.Error("expected error for invalid token", err, code)
+	}
+
+	_, err, code = ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+		FilterCriteria: model.FilterCriteriaAndSet{optional},
+		IncludeDevices: true,
+	})
+	if err == nil || code != http.StatusBadRequest {
+		t.Error("expected error for only optional criteria", err, code)
+	}
+	_, err, code = ctrl.GetFilteredDevices(ctx, helper.AdminJwt, model.FilterCriteriaAndSet{mandatory, optional}, nil, "", false, false, nil)
+	if err == nil || code != http.StatusBadRequest {
+		t.Error("expected error for optional criteria in v1", err, code)
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selectables
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/model"
+	"github.com/SENERGY-Platform/device-selection/pkg/model/devicemodel"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/environment"
+	"github.com/SENERGY-Platform/device-selection/pkg/tests/helper"
+	"github.com/SENERGY-Platform/models/go/models"
+)
+
+func TestSelectableValueConstraints(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fixtures, err := environment.DefaultFixtures()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	env, ctrl, err := environment.StartHermetic(ctx, wg, fixtures)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	yes, no := true, false
+	cases := []struct {
+		name       string
+		valueTypes []devicemodel.Type
+		void       *bool
+		expected   []string
+	}{
+		{name: "short name", valueTypes: []devicemodel.Type{"Float"}, expected: []string{"device:t1", "group:g1", "import:i1"}},
+		{name: "url", valueTypes: []devicemodel.Type{devicemodel.Float}, expected: []string{"device:t1", "group:g1", "import:i1"}},
+		{name: "other type", valueTypes: []devicemodel.Type{"String", "Structure"}, expected: []string{}},
+		{name: "not void", void: &no, expected: []string{"device:t1", "group:g1", "import:i1"}},
+		{name: "void", void: &yes, expected: []string{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			criteria := model.FilterCriteriaAndSet{{
+				FunctionId: devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature",
+				AspectId:   "air",
+				ValueTypes: c.valueTypes,
+				Void:       c.void,
+			}}
+			collect := func(result []model.Selectable) []string {
+				ids := []string{}
+				for _, selectable := range result {
+					switch {
+					case selectable.Device != nil:
+						ids = append(ids, "device:"+selectable.Device.Id)
+					case selectable.DeviceGroup != nil:
+						ids = append(ids, "group:"+selectable.DeviceGroup.Id)
+					case selectable.Import != nil:
+						ids = append(ids, "import:"+selectable.Import.Id)
+					}
+				}
+				slices.Sort(ids)
+				return ids
+			}
+			v2, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+				FilterCriteria: criteria,
+				IncludeDevices: true,
+				IncludeGroups:  true,
+				IncludeImports: true,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ids := collect(v2); !slices.Equal(ids, c.expected) {
+				t.Errorf("v2\na=%v\ne=%v", ids, c.expected)
+			}
+			//group criteria have no value types, so groups are checked with the device-types of their devices
+			v1, err, _ := ctrl.GetFilteredDevices(ctx, helper.AdminJwt, criteria, nil, "", true, true, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ids := collect(v1); !slices.Equal(ids, c.expected) {
+				t.Errorf("v1\na=%v\ne=%v", ids, c.expected)
+			}
+		})
+	}
+
+	//every criterion must keep a path option: the options of the first criterion are removed, the second criterion still matches
+	getTemperature := devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature"
+	setTemperature := devicemodel.CONTROLLING_FUNCTION_PREFIX + "setTemperature"
+	textThermostat := models.DeviceType{
+		Id:            "textthermostat",
+		Name:          "textthermostat",
+		DeviceClassId: "thermostat",
+		Services: []models.Service{
+			{
+				Id:          "getTemperature",
+				Name:        "getTemperature",
+				Interaction: models.REQUEST,
+				Outputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:       "temperature",
+					FunctionId: getTemperature,
+					AspectId:   "inside_air",
+					Type:       models.String,
+				}}},
+			},
+			{
+				Id:          "setTemperature",
+				Name:        "setTemperature",
+				Interaction: models.REQUEST,
+				Inputs: []models.Content{{ContentVariable: models.ContentVariable{
+					Name:       "temperature",
+					FunctionId: setTemperature,
+					AspectId:   "inside_air",
+					Type:       models.Float,
+				}}},
+			},
+		},
+	}
+	env.DeviceRepository.SetDeviceType(textThermostat)
+	env.DeviceRepository.SetDevice(models.Device{Id: "tth1", LocalId: "tth1", Name: "tth1", DeviceTypeId: textThermostat.Id, OwnerId: fixtures.Owner})
+	twoCriteria := model.FilterCriteriaAndSet{
+		{FunctionId: getTemperature, AspectId: "air", ValueTypes: []devicemodel.Type{devicemodel.Float}},
+		{FunctionId: setTemperature, AspectId: "air"},
+	}
+	v2, err, _ := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{FilterCriteria: twoCriteria, IncludeDevices: true})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(v2) != 0 {
+		t.Errorf("v2: %#v", v2)
+	}
+	v1, err, _ := ctrl.GetFilteredDevices(ctx, helper.AdminJwt, twoCriteria, nil, "", false, false, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(v1) != 0 {
+		t.Errorf("v1: %#v", v1)
+	}
+	deviceTypes, err, _ := ctrl.QueryDeviceTypes(ctx, helper.AdminJwt, model.QueryDeviceTypesOptions{FilterCriteria: twoCriteria})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(deviceTypes) != 0 {
+		t.Errorf("device-types: %#v", deviceTypes)
+	}
+	//completed import path options have the type of their variable
+	importCriteria := model.FilterCriteriaAndSet{{FunctionId: getTemperature, AspectId: "air"}}
+	imports, err, _ := ctrl.GetFilteredDevices(ctx, helper.AdminJwt, importCriteria, nil, "", false, true, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	imports, err = ctrl.CompleteServices(ctx, helper.AdminJwt, imports, importCriteria)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	importOptions := 0
+	for _, selectable := range imports {
+		if selectable.Import == nil {
+			continue
+		}
+		for _, options := range selectable.ServicePathOptions {
+			for _, option := range options {
+				importOptions++
+				if option.Type != devicemodel.Float {
+					t.Errorf("unexpected import path option type: %#v", option)
+				}
+			}
+		}
+	}
+	if importOptions == 0 {
+		t.Errorf("missing import path options: %#v", imports)
+	}
+
+	twoCriteria[0].ValueTypes = []devicemodel.Type{devicemodel.String}
+	v2, err, _ = ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{FilterCriteria: twoCriteria, IncludeDevices: true})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(v2) != 1 || v2[0].Device.Id != "tth1" {
+		t.Errorf("v2: %#v", v2)
+	}
+
+	_, err, code := ctrl.GetFilteredDevicesV2(ctx, helper.AdminJwt, model.GetFilteredDevicesV2Options{
+		FilterCriteria: model.FilterCriteriaAndSet{{FunctionId: devicemodel.MEASURING_FUNCTION_PREFIX + "getTemperature", ValueTypes: []devicemodel.Type{"Number"}}},
+		IncludeDevices: true,
+	})
+	if err == nil || code != http.StatusBadRequest {
+		t.Error("expected invalid value type", err, code)
+	}
+}
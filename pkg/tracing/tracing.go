@@ -0,0 +1,112 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/SENERGY-Platform/device-selection/pkg/configuration"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const InstrumentationName = "github.com/SENERGY-Platform/device-selection"
+
+const DefaultServiceName = "device-selection"
+
+// Init sets the global tracer provider and propagator.
+// the returned shutdown function flushes pending spans and should be called before the process exits.
+// without a configured exporter a no-op provider is used, incoming trace headers are still forwarded to import-deploy.
+func Init(ctx context.Context, config configuration.Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	switch config.TracingExporter {
+	case "", "none":
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		if config.TracingOtlpEndpoint == "" {
+			return nil, fmt.Errorf("tracing_exporter=otlp requires tracing_otlp_endpoint")
+		}
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(config.TracingOtlpEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("unable to create otlp trace exporter: %w", err)
+		}
+		serviceName := config.TracingServiceName
+		if serviceName == "" {
+			serviceName = DefaultServiceName
+		}
+		ratio := config.TracingSampleRatio
+		if ratio <= 0 || ratio > 1 {
+			ratio = 1
+		}
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+			sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		)
+		otel.SetTracerProvider(provider)
+		return provider.Shutdown, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing_exporter %q (expected none or otlp)", config.TracingExporter)
+	}
+}
+
+// Start creates an internal span as child of the span in ctx
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// StartUpstream creates a client span for a call to another service
+func StartUpstream(ctx context.Context, service string, operation string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	attributes = append(attributes, attribute.String("peer.service", service))
+	return otel.Tracer(InstrumentationName).Start(ctx, service+" "+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
+// Finish records err (if not nil) and ends the span
+func Finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// NewHandler creates a server span for every request and extracts the trace context of the caller
+func NewHandler(handler http.Handler) http.Handler {
+	return otelhttp.NewHandler(handler, DefaultServiceName, otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+		return r.Method + " " + r.URL.Path
+	}))
+}
+
+// NewTransport creates client spans for outgoing requests and injects the trace context of the request context into the request headers.
+// only clients that send requests with a context (import-deploy) profit from it; the repository clients are out of scope.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}